@@ -18,22 +18,79 @@ import (
 // @Param        login body models.CustomerLoginRequest true "login"
 // @Success      201  {object}  models.CustomerLoginResponse
 // @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      403  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h *Handler) CustomerLogin(c *gin.Context)  {
 	loginReq := models.CustomerLoginRequest{}
 
 	if err := c.ShouldBindJSON(&loginReq);err != nil {
-		handlerResponseLog(c, h.Log, "error while binding body", http.StatusBadRequest, err)
+		handleError(c, h.Log, "error while binding body", invalidBody(err))
 		return
 	}
 	fmt.Println("loginReq:",loginReq)
 
 	loginResp,err := h.Services.Auth().CustomerLogin(c.Request.Context(),loginReq)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"unauthorized",http.StatusUnauthorized,err)
+		handleError(c, h.Log, "unauthorized", err)
 		return
 	}
 	handlerResponseLog(c,h.Log,"succes",http.StatusOK,loginResp)
 }
 
+
+// RefreshToken godoc
+// @Router       /auth/refresh [POST]
+// @Summary      Refresh tokens
+// @Description  Exchanges a refresh token for a new access and refresh token pair
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token body models.RefreshTokenRequest true "refresh token"
+// @Success      200  {object}  models.TokenResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h *Handler) RefreshToken(c *gin.Context) {
+	req := models.RefreshTokenRequest{}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, h.Log, "error while binding body", invalidBody(err))
+		return
+	}
+
+	tokens, err := h.Services.Auth().Refresh(c.Request.Context(), req)
+	if err != nil {
+		handleError(c, h.Log, "unauthorized", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "succes", http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Router       /auth/logout [POST]
+// @Summary      Logout
+// @Description  Revokes the refresh token and every token issued from the same login
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token body models.RefreshTokenRequest true "refresh token"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h *Handler) Logout(c *gin.Context) {
+	req := models.RefreshTokenRequest{}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, h.Log, "error while binding body", invalidBody(err))
+		return
+	}
+
+	if err := h.Services.Auth().Logout(c.Request.Context(), req); err != nil {
+		handleError(c, h.Log, "unauthorized", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "succes", http.StatusOK, "logged out")
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /order/{id}/cancel [POST]
+// @Summary      Cancel an order
+// @Description  cancels the order and refunds the share of the paid amount the cancellation policy allows, by the notice given before the rental starts. In-process orders get no refund
+// @Tags         order
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Param        cancellation body models.CancelOrder false "cancellation"
+// @Success      200 {object} models.OrderCancellation
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) CancelOrder(c *gin.Context) {
+	request := models.CancelOrder{}
+
+	// the body is optional, the reason may be left out
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			handleError(c, h.Log, "error while reading request body", invalidBody(err))
+			return
+		}
+	}
+
+	request.OrderId = c.Param("id")
+	if err := uuid.Validate(request.OrderId); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+request.OrderId, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.PaymentTimeout)
+	defer cancel()
+
+	cancellation, err := h.Services.Order().Cancel(ctx, request, authInfo(c))
+	if err != nil {
+		handleError(c, h.Log, "error while canceling order", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, cancellation)
+}
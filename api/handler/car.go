@@ -7,9 +7,8 @@ import (
 	// _ "rent-car/api/docs"
 	"rent-car/api/models"
 	"rent-car/config"
-	"rent-car/pkg/check"
+	"rent-car/pkg/errs"
 	"strconv"
-
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -29,13 +28,7 @@ func (h Handler) CreateCar(c *gin.Context) {
 	car := models.CreateCar{}
 
 	if err := c.ShouldBindJSON(&car); err != nil {
-		handlerResponseLog(c,h.Log,"error while reading request body", http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := check.ValidateCarYear(car.Year); err != nil {
-		handlerResponseLog(c,h.Log,"error while validating car year, year: "+strconv.Itoa(car.Year), http.StatusBadRequest, err.Error())
-
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
@@ -44,7 +37,7 @@ func (h Handler) CreateCar(c *gin.Context) {
 
 	id, err := h.Services.Car().Create(ctx,car)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while creating car", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while creating car", err)
 		return
 	}
 
@@ -67,12 +60,7 @@ func (h Handler) UpdateCar(c *gin.Context) {
 	car := models.Car{}
 
 	if err := c.ShouldBindJSON(&car); err != nil {
-		handlerResponseLog(c,h.Log,"error while reading request body", http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := check.ValidateCarYear(car.Year); err != nil {
-		handlerResponseLog(c,h.Log,"error while validating car year,year:"+strconv.Itoa(car.Year), http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
@@ -80,7 +68,7 @@ func (h Handler) UpdateCar(c *gin.Context) {
 
 	err := uuid.Validate(car.Id)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while validating car id,id: "+car.Id, http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating car id,id: "+car.Id, errs.InvalidField("id", err))
 		return
 	}
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
@@ -88,7 +76,7 @@ func (h Handler) UpdateCar(c *gin.Context) {
 
 	id, err := h.Services.Car().Update(ctx,car)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while updating car", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while updating car", err)
 		return
 	}
 
@@ -98,13 +86,15 @@ func (h Handler) UpdateCar(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router       /cars [GET]
 // @Summary      Get car list
-// @Description  get car list
+// @Description  get car list, filter with filter[field]=value or filter[field][op]=value (ops: eq, ne, gt, gte, lt, lte, like, in), e.g. filter[brand]=bmw&filter[year][gte]=2018
 // @Tags         car
 // @Accept       json
 // @Produce      json
 // @Param        page query string false "page"
 // @Param        limit query string false "limit"
+// @Param        cursor query string false "next_cursor or prev_cursor of a previous page, an empty value starts cursor pagination instead of page"
 // @Param        search query string false "search"
+// @Param        sort query string false "comma separated fields, prefix with - for descending, e.g. -created_at,name"
 // @Success      201 {object} models.GetAllCarsResponse
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
@@ -117,12 +107,12 @@ func (h Handler) GetAllCars(c *gin.Context) {
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while parsing page", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
 		return
 	}
 	limit, err := ParseLimitQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"Error while parsing limit", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "Error while parsing limit", errs.InvalidField("limit", err))
 		return
 	}
 	fmt.Println("page: ", page)
@@ -130,12 +120,19 @@ func (h Handler) GetAllCars(c *gin.Context) {
 
 	request.Page = page
 	request.Limit = limit
+	request.Cursor, request.UseCursor = c.GetQuery("cursor")
+
+	request.Filters, request.Sort, err = parseListQuery(c, carListFields)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing filters", invalidQuery(err))
+		return
+	}
 
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
 	defer cancel()
 	cars, err := h.Services.Car().GetCarAll(ctx,request)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while gettign cars", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while gettign cars", err)
 		return
 	}
 
@@ -145,45 +142,97 @@ func (h Handler) GetAllCars(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router       /availablecars [GET]
 // @Summary      Get availablecars
-// @Description  get availablecars
+// @Description  get cars without a booking in the [from, to) window
 // @Tags         car
 // @Accept       json
 // @Produce      json
+// @Param        from query string true "from date, YYYY-MM-DD"
+// @Param        to query string true "to date, YYYY-MM-DD"
 // @Param        page query string false "page"
 // @Param        limit query string false "limit"
+// @Param        cursor query string false "next_cursor or prev_cursor of a previous page, an empty value starts cursor pagination instead of page"
 // @Param        search query string false "search"
+// @Param        brand query string false "brand"
+// @Param        colour query string false "colour"
+// @Param        year_from query int false "year from"
+// @Param        year_to query int false "year to"
+// @Param        hoursepower_from query int false "hoursepower from"
+// @Param        hoursepower_to query int false "hoursepower to"
+// @Param        engine_cap_from query number false "engine capacity from"
+// @Param        engine_cap_to query number false "engine capacity to"
 // @Success      201 {object} models.GetAllCarsResponse
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
 // @Failure      500 {object} models.Response
 func (h Handler) GetAvaibleCars(c *gin.Context) {
 	var (
-		request = models.GetAllCarsRequest{}
+		request = models.GetAvailableCarsRequest{}
+		err     error
 	)
+	dates := models.DateRange{}
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		handleError(c, h.Log, "error while validating from and to dates", invalidQuery(err))
+		return
+	}
+	request.From = dates.From
+	request.To = dates.To
 	request.Search = c.Query("search")
+	request.Brand = c.Query("brand")
+	request.Colour = c.Query("colour")
+
+	intParams := map[string]*int{
+		"year_from":        &request.YearFrom,
+		"year_to":          &request.YearTo,
+		"hoursepower_from": &request.HoursePowerFrom,
+		"hoursepower_to":   &request.HoursePowerTo,
+	}
+	for name, value := range intParams {
+		if c.Query(name) == "" {
+			continue
+		}
+		if *value, err = strconv.Atoi(c.Query(name)); err != nil {
+			handleError(c, h.Log, "error while parsing "+name, errs.InvalidField(name, err))
+			return
+		}
+	}
+
+	floatParams := map[string]*float32{
+		"engine_cap_from": &request.EngineCapFrom,
+		"engine_cap_to":   &request.EngineCapTo,
+	}
+	for name, value := range floatParams {
+		if c.Query(name) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(c.Query(name), 32)
+		if err != nil {
+			handleError(c, h.Log, "error while parsing "+name, errs.InvalidField(name, err))
+			return
+		}
+		*value = float32(parsed)
+	}
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while parsing page", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
 		return
 	}
 	limit, err := ParseLimitQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"Error while parsing limit", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "Error while parsing limit", errs.InvalidField("limit", err))
 		return
 	}
-	fmt.Println("page: ", page)
-	fmt.Println("limit: ", limit)
 
 	request.Page = page
 	request.Limit = limit
+	request.Cursor, request.UseCursor = c.GetQuery("cursor")
 	
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
 	defer cancel()
 
 	cars, err := h.Services.Car().GetAvaibleCars(ctx,request)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while gettign cars", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while gettign cars", err)
 		return
 	}
 
@@ -210,7 +259,7 @@ func (h Handler) GetByIDCar(c *gin.Context) {
 	defer cancel()
 	car, err := h.Services.Car().GetByIDCar(ctx,id)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while getting car by id", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while getting car by id", err)
 		return
 	}
 	handlerResponseLog(c,h.Log,"", http.StatusOK, car)
@@ -219,7 +268,7 @@ func (h Handler) GetByIDCar(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router       /car/{id} [DELETE]
 // @Summary      Delete car
-// @Description  Delete car
+// @Description  Soft delete a car that has no new or in process orders
 // @Tags         car
 // @Accept       json
 // @Produce      json
@@ -227,6 +276,7 @@ func (h Handler) GetByIDCar(c *gin.Context) {
 // @Success      201 {object} models.Response
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
 // @Failure      500 {object} models.Response
 func (h Handler) DeleteCar(c *gin.Context) {
 
@@ -235,7 +285,7 @@ func (h Handler) DeleteCar(c *gin.Context) {
 
 	err := uuid.Validate(id)
 	if err != nil {
-		handleResponse(c, "error while validating id, err", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating id, err", errs.InvalidField("id", err))
 		return
 	}
 
@@ -244,9 +294,213 @@ func (h Handler) DeleteCar(c *gin.Context) {
 
 	err = h.Services.Car().Delete(ctx,id)
 	if err != nil {
-		handleResponse(c, "error while deleting car", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while deleting car", err)
 		return
 	}
 
 	handleResponse(c, "ok", http.StatusOK, id)
 }
+
+// @Security ApiKeyAuth
+// @Router       /car/{id}/quote [GET]
+// @Summary      Price quote
+// @Description  prices renting the car in the [from, to) window
+// @Tags         car
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "car_id"
+// @Param        from query string true "from date, YYYY-MM-DD"
+// @Param        to query string true "to date, YYYY-MM-DD"
+// @Success      200 {object} models.PriceQuote
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) QuoteCar(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating car id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	dates := models.DateRange{}
+	if err := c.ShouldBindQuery(&dates); err != nil {
+		handleError(c, h.Log, "error while validating from and to dates", invalidQuery(err))
+		return
+	}
+
+	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
+	defer cancel()
+
+	quote, err := h.Services.Pricing().Quote(ctx, id, dates.From, dates.To)
+	if err != nil {
+		handleError(c, h.Log, "error while pricing car", err)
+		return
+	}
+	handlerResponseLog(c,h.Log,"ok", http.StatusOK, quote)
+}
+
+// @Security ApiKeyAuth
+// @Router       /car/{id}/rates [POST]
+// @Summary      Creates a seasonal rate
+// @Description  overrides the daily rate of the car in the [from_date, to_date) window
+// @Tags         car
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "car_id"
+// @Param        rate body models.SeasonalRate true "seasonal rate"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) CreateSeasonalRate(c *gin.Context) {
+	rate := models.SeasonalRate{}
+
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	rate.CarId = c.Param("id")
+	if err := uuid.Validate(rate.CarId); err != nil {
+		handleError(c, h.Log, "error while validating car id,id: "+rate.CarId, errs.InvalidField("car_id", err))
+		return
+	}
+
+	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
+	defer cancel()
+
+	id, err := h.Services.Car().CreateSeasonalRate(ctx, rate)
+	if err != nil {
+		handleError(c, h.Log, "error while creating seasonal rate", err)
+		return
+	}
+	handlerResponseLog(c,h.Log,"Created successfully", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /car/{id}/rates/{rate_id} [DELETE]
+// @Summary      Delete seasonal rate
+// @Description  Delete seasonal rate
+// @Tags         car
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "car_id"
+// @Param        rate_id path string true "rate_id"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) DeleteSeasonalRate(c *gin.Context) {
+	carID, id := c.Param("id"), c.Param("rate_id")
+
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating rate id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Car().DeleteSeasonalRate(ctx, carID, id); err != nil {
+		handleError(c, h.Log, "error while deleting seasonal rate", err)
+		return
+	}
+	handlerResponseLog(c,h.Log,"ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /cars/deleted [GET]
+// @Summary      Get deleted cars
+// @Description  list soft deleted cars, the most recently deleted first, admin only
+// @Tags         car
+// @Accept       json
+// @Produce      json
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200 {object} models.GetAllCarsResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetDeletedCars(c *gin.Context) {
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	cars, err := h.Services.Car().GetDeleted(ctx, models.GetDeletedRequest{Page: page, Limit: limit})
+	if err != nil {
+		handleError(c, h.Log, "error while getting deleted cars", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, cars)
+}
+
+// @Security ApiKeyAuth
+// @Router       /car/{id}/restore [POST]
+// @Summary      Restore car
+// @Description  undo the soft delete of a car, admin only
+// @Tags         car
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "car_id"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) RestoreCar(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating car id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	err := h.Services.Car().Restore(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while restoring car", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /cars/deleted [DELETE]
+// @Summary      Purge deleted cars
+// @Description  permanently remove cars deleted longer than the retention period ago, cars with orders are kept, admin only
+// @Tags         car
+// @Accept       json
+// @Produce      json
+// @Param        retention_days query int false "retention period in days, 30 by default and at least 30"
+// @Success      200 {object} models.PurgeResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) PurgeDeletedCars(c *gin.Context) {
+	retention, err := ParseRetentionQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing retention_days", errs.InvalidField("retention_days", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	purged, err := h.Services.Car().Purge(ctx, retention)
+	if err != nil {
+		handleError(c, h.Log, "error while purging deleted cars", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, models.PurgeResponse{
+		Purged:        purged,
+		RetentionDays: int(retention.Hours() / 24),
+	})
+}
@@ -2,13 +2,12 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	_ "rent-car/api/docs"
 	"rent-car/api/models"
 	"rent-car/config"
-	"rent-car/pkg/check"
+	"rent-car/pkg/errs"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -29,21 +28,7 @@ func (h Handler) CreateCustomer(c *gin.Context) {
 	customer := models.Customer{}
 
 	if err := c.ShouldBindJSON(&customer); err != nil {
-		handlerResponseLog(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := check.ValidatePassword(customer.Password); err != nil {
-		handlerResponseLog(c, h.Log, "error while validating password", http.StatusBadRequest, err.Error())
-	}
-
-	if err := check.ValidateGmailCustomer(customer.Gmail); !true {
-		handlerResponseLog(c, h.Log, "error while validating Email"+customer.Gmail, http.StatusBadRequest, err)
-		return
-	}
-
-	if err := check.ValidatePhoneNumberOfCustomer(customer.Phone); !true {
-		handlerResponseLog(c, h.Log, "error while validating PhoneNumber"+customer.Phone, http.StatusBadRequest, err)
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
@@ -53,7 +38,7 @@ func (h Handler) CreateCustomer(c *gin.Context) {
 
 	id, err := h.Services.Customer().Create(ctx, customer)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while creating customer", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while creating customer", err)
 		return
 	}
 	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
@@ -67,47 +52,35 @@ func (h Handler) CreateCustomer(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "customer_id"
-// @Param        car body models.Customer true "customer"
+// @Param        car body models.UpdateCustomer true "customer"
 // @Success      201 {object} models.Customer
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
 // @Failure      500 {object} models.Response
 func (h Handler) UpdateCustomer(c *gin.Context) {
 
-	customer := models.Customer{}
+	customer := models.UpdateCustomer{}
 
 	if err := c.ShouldBindJSON(&customer); err != nil {
-		handlerResponseLog(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if err := check.ValidatePassword(customer.Password); err != nil {
-		handlerResponseLog(c, h.Log, "error while validating password", http.StatusBadRequest, err.Error())
-	}
-
-	if err := check.ValidateGmailCustomer(customer.Gmail); !true {
-		handlerResponseLog(c, h.Log, "error while validating Email"+customer.Gmail, http.StatusBadRequest, err)
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
-	if err := check.ValidatePhoneNumberOfCustomer(customer.Phone); !true {
-		handlerResponseLog(c, h.Log, "error while validating PhoneNumber"+customer.Phone, http.StatusBadRequest, err)
-		return
-	}
-	customer.Id = c.Query("id")
+	customer.Id = c.Param("id")
 
 	err := uuid.Validate(customer.Id)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while validating", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating", errs.InvalidField("id", err))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
 	defer cancel()
+
 	id, err := h.Services.Customer().Update(ctx, customer)
 
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while updating customer", http.StatusInternalServerError, err)
+		handleError(c, h.Log, "error while updating customer", err)
 		return
 	}
 	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
@@ -116,13 +89,15 @@ func (h Handler) UpdateCustomer(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router       /customers [GET]
 // @Summary      Get customer list
-// @Description  get customer list
+// @Description  get customer list, filter with filter[field]=value or filter[field][op]=value (ops: eq, ne, gt, gte, lt, lte, like, in), e.g. filter[first_name][like]=ali
 // @Tags         customer
 // @Accept       json
 // @Produce      json
 // @Param        page query string false "page"
 // @Param        limit query string false "limit"
+// @Param        cursor query string false "next_cursor or prev_cursor of a previous page, an empty value starts cursor pagination instead of page"
 // @Param        search query string false "search"
+// @Param        sort query string false "comma separated fields, prefix with - for descending, e.g. -created_at,name"
 // @Success      201 {object} models.GetAllCustomersResponse
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
@@ -135,12 +110,12 @@ func (h Handler) GetAllCustomer(c *gin.Context) {
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while parsing page", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
 		return
 	}
 	limit, err := ParseLimitQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while parsing limit", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
 		return
 	}
 	fmt.Println("page: ", page)
@@ -148,12 +123,19 @@ func (h Handler) GetAllCustomer(c *gin.Context) {
 
 	request.Page = page
 	request.Limit = limit
+	request.Cursor, request.UseCursor = c.GetQuery("cursor")
+
+	request.Filters, request.Sort, err = parseListQuery(c, customerListFields)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing filters", invalidQuery(err))
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
 	defer cancel()
 	customers, err := h.Services.Customer().GetCustomerAll(ctx, request)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while getting customers", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while getting customers", err)
 		return
 	}
 
@@ -182,7 +164,7 @@ func (h Handler) GetByIDCustomer(c *gin.Context) {
 
 	customer, err := h.Services.Customer().GetByIDCustomer(ctx, id)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while getting customer by id", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while getting customer by id", err)
 		return
 	}
 	handlerResponseLog(c, h.Log, "ok", http.StatusOK, customer)
@@ -207,7 +189,7 @@ func (h Handler) DeleteCustomer(c *gin.Context) {
 
 	err := uuid.Validate(id)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while validating id", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating id", errs.InvalidField("id", err))
 		return
 	}
 
@@ -216,7 +198,7 @@ func (h Handler) DeleteCustomer(c *gin.Context) {
 
 	err = h.Services.Customer().Delete(ctx, id)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while deleting customer", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while deleting customer", err)
 		return
 	}
 	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
@@ -241,26 +223,235 @@ func (h Handler) UpdateCustomerPassword(c *gin.Context) {
 	customer := models.PasswordOfCustomer{}
 
 	if err := c.ShouldBindJSON(&customer); err != nil {
-		handlerResponseLog(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
-	if customer.NewPassword == customer.Password {
-		handlerResponseLog(c,h.Log,"Change your old password to new one"+customer.NewPassword,http.StatusBadRequest,errors.New("error is here in customer"))
-	}
-
-	if err := check.ValidatePassword(customer.Password); err != nil {
-		handlerResponseLog(c, h.Log, "error while validating password", http.StatusBadRequest, err.Error())
-	}
 
 	
 	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
 	defer cancel()
+
+	data := authInfo(c)
+	if data.UserRole == config.CUSTOMER_ROLE {
+		owner, err := h.Services.Customer().GetByIDCustomer(ctx, data.UserID)
+		if err != nil {
+			handleError(c, h.Log, "error while getting customer by id", err)
+			return
+		}
+		if owner.Phone != customer.Phone {
+			handleError(c, h.Log, "customer can change only own password", errNotOwner)
+			return
+		}
+	}
+
 	_, err := h.Services.Customer().UpdatePassword(ctx, customer)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while updating customer", http.StatusInternalServerError, err)
+		handleError(c, h.Log, "error while updating customer", err)
 		return
 	}
 	handlerResponseLog(c, h.Log, "ok", http.StatusOK,customer)
 }
 
+
+// @Security ApiKeyAuth
+// @Router       /customers/deleted [GET]
+// @Summary      Get deleted customers
+// @Description  list soft deleted customers, the most recently deleted first, admin only
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200 {object} models.GetDeletedCustomersResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetDeletedCustomers(c *gin.Context) {
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	customers, err := h.Services.Customer().GetDeleted(ctx, models.GetDeletedRequest{Page: page, Limit: limit})
+	if err != nil {
+		handleError(c, h.Log, "error while getting deleted customers", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, customers)
+}
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/restore [POST]
+// @Summary      Restore customer
+// @Description  undo the soft delete of a customer, fails when the phone number was registered again, admin only
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) RestoreCustomer(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating customer id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	err := h.Services.Customer().Restore(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while restoring customer", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /customers/deleted [DELETE]
+// @Summary      Purge deleted customers
+// @Description  permanently remove customers deleted longer than the retention period ago, customers with orders are kept, admin only
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        retention_days query int false "retention period in days, 30 by default and at least 30"
+// @Success      200 {object} models.PurgeResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) PurgeDeletedCustomers(c *gin.Context) {
+	retention, err := ParseRetentionQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing retention_days", errs.InvalidField("retention_days", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	purged, err := h.Services.Customer().Purge(ctx, retention)
+	if err != nil {
+		handleError(c, h.Log, "error while purging deleted customers", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, models.PurgeResponse{
+		Purged:        purged,
+		RetentionDays: int(retention.Hours() / 24),
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/block [POST]
+// @Summary      Block customer
+// @Description  blocks the customer from logging in and booking until the block is lifted or until passes, the customer's tokens are revoked, admin only
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Param        block body models.BlockCustomerRequest true "block"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) BlockCustomer(c *gin.Context) {
+	request := models.BlockCustomerRequest{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Customer().Block(ctx, id, request, authInfo(c)); err != nil {
+		handleError(c, h.Log, "error while blocking customer", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/unblock [POST]
+// @Summary      Unblock customer
+// @Description  lifts the block of the customer, admin only
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Param        unblock body models.UnblockCustomerRequest true "unblock"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) UnblockCustomer(c *gin.Context) {
+	request := models.UnblockCustomerRequest{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Customer().Unblock(ctx, id, request, authInfo(c)); err != nil {
+		handleError(c, h.Log, "error while unblocking customer", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/blocks [GET]
+// @Summary      Get customer block history
+// @Description  who blocked or unblocked the customer and why, the latest first, staff only
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Success      200 {object} []models.CustomerBlockEvent
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetCustomerBlocks(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	events, err := h.Services.Customer().GetBlockEvents(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while getting customer block events", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, events)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /order/{id}/extend [POST]
+// @Summary      Extend an order
+// @Description  asks to move the to_date of a new or in-process order later. The extra days are priced with the pricing rules and kept as a separate extension of the booking. Extensions asked for by customers wait for staff approval when the service requires it, the others are applied right away
+// @Tags         order
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Param        extension body models.ExtendOrder true "extension"
+// @Success      200 {object} models.OrderExtension
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) ExtendOrder(c *gin.Context) {
+	request := models.ExtendOrder{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	request.OrderId = c.Param("id")
+	if err := uuid.Validate(request.OrderId); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+request.OrderId, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	extension, err := h.Services.Order().Extend(ctx, request, authInfo(c))
+	if err != nil {
+		handleError(c, h.Log, "error while extending order", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, extension)
+}
+
+// @Security ApiKeyAuth
+// @Router       /order/{id}/extensions/{extension_id}/review [POST]
+// @Summary      Review an order extension
+// @Description  approves or rejects a pending extension, a rejection needs a reason. An approved extension is applied when the car is still free, staff only
+// @Tags         order
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Param        extension_id path string true "extension_id"
+// @Param        review body models.ReviewExtension true "review"
+// @Success      200 {object} models.OrderExtension
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) ReviewExtension(c *gin.Context) {
+	request := models.ReviewExtension{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	request.OrderId = c.Param("id")
+	if err := uuid.Validate(request.OrderId); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+request.OrderId, errs.InvalidField("id", err))
+		return
+	}
+	request.ExtensionId = c.Param("extension_id")
+	if err := uuid.Validate(request.ExtensionId); err != nil {
+		handleError(c, h.Log, "error while validating extension id,id: "+request.ExtensionId, errs.InvalidField("extension_id", err))
+		return
+	}
+	request.ReviewerId = authInfo(c).UserID
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	extension, err := h.Services.Order().ReviewExtension(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while reviewing extension", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, extension)
+}
@@ -3,12 +3,17 @@ package handler
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"rent-car/api/models"
 	"rent-car/config"
+	"rent-car/pkg/check"
+	"rent-car/pkg/errs"
 	"rent-car/pkg/jwt"
 	"rent-car/pkg/logger"
 	"rent-car/service"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -74,6 +79,64 @@ func handlerResponseLog(c *gin.Context,log logger.ILogger,msg string, statusCode
 	c.JSON(resp.StatusCode, resp)
 }
 
+// errorStatus is the status code of every error kind, the one place errors are mapped to HTTP.
+var errorStatus = map[errs.Kind]int{
+	errs.KindNotFound:     http.StatusNotFound,
+	errs.KindConflict:     http.StatusConflict,
+	errs.KindValidation:   http.StatusBadRequest,
+	errs.KindForbidden:    http.StatusForbidden,
+	errs.KindUnauthorized: http.StatusUnauthorized,
+	errs.KindInternal:     http.StatusInternalServerError,
+}
+
+// handleError responds with the status code and the models.ErrorResponse of err.
+// Errors that are not domain errors are internal, their text is logged but not sent to the client.
+func handleError(c *gin.Context, log logger.ILogger, msg string, err error) {
+	e := errs.From(err)
+
+	status, ok := errorStatus[e.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	body := models.ErrorResponse{
+		Code:    e.Code,
+		Type:    string(e.Kind),
+		Message: e.Message,
+		Fields:  e.Fields,
+	}
+	if e.Kind != errs.KindInternal {
+		// the full text keeps the details added while the error was returned up the stack
+		body.Message = err.Error()
+	}
+
+	if cause := errors.Unwrap(e); cause != nil {
+		msg += ": " + cause.Error()
+	} else {
+		msg += ": " + err.Error()
+	}
+	handlerResponseLog(c, log, msg, status, body)
+}
+
+// errRequestInvalid lists every field of a bound request that failed its binding rules.
+var errRequestInvalid = errs.Validation("request_invalid", "request validation failed")
+
+// invalidBody is the error of a request body that can not be decoded or fails validation.
+func invalidBody(err error) error {
+	if fields, ok := check.FieldErrors(err); ok {
+		return errRequestInvalid.WithFields(fields...).Wrap(err)
+	}
+	return errs.Validation("invalid_body", err.Error()).Wrap(err)
+}
+
+// invalidQuery is the error of malformed or invalid query parameters.
+func invalidQuery(err error) error {
+	if fields, ok := check.FieldErrors(err); ok {
+		return errRequestInvalid.WithFields(fields...).Wrap(err)
+	}
+	return errs.Validation("invalid_query", err.Error()).Wrap(err)
+}
+
 func ParsePageQueryParam(c *gin.Context) (uint64, error) {
 	pageStr := c.Query("page")
 	if pageStr == "" {
@@ -107,24 +170,64 @@ func ParseLimitQueryParam(c *gin.Context) (uint64, error) {
 
 
 
-func getAuthInfo(c *gin.Context) (models.AuthInfo, error) {
-	accessToken := c.GetHeader("Authorization")
+// ParseRetentionQueryParam reads retention_days, it defaults to and may not be shorter than config.SoftDeleteRetention.
+func ParseRetentionQueryParam(c *gin.Context) (time.Duration, error) {
+	retention := config.SoftDeleteRetention
+
+	daysStr := c.Query("retention_days")
+	if daysStr == "" {
+		return retention, nil
+	}
+	days, err := strconv.ParseUint(daysStr, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	if requested := time.Duration(days) * 24 * time.Hour; requested >= retention {
+		return requested, nil
+	}
+	return 0, fmt.Errorf("retention_days must be at least %d", int(retention.Hours()/24))
+}
+
+func (h Handler) getAuthInfo(c *gin.Context) (models.AuthInfo, error) {
+	accessToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
 	if accessToken == "" {
-		return models.AuthInfo{}, errors.New("unauthorized")
+		return models.AuthInfo{}, errUnauthorized
 	}
 
 	m, err := jwt.ExtractClaims(accessToken)
 	if err != nil {
-		return models.AuthInfo{}, err
+		return models.AuthInfo{}, service.ErrInvalidToken.Wrap(err)
+	}
+
+	if tokenType, _ := m["token_type"].(string); tokenType != config.ACCESS_TOKEN_TYPE {
+		return models.AuthInfo{}, errUnauthorized
 	}
 
-	role := m["user_role"].(string)
-	if !(role == config.ADMIN_ROLE || role == config.CUSTOMER_ROLE) {
-		return models.AuthInfo{}, errors.New("unauthorized")
+	role, _ := m["user_role"].(string)
+	if !(role == config.CUSTOMER_ROLE || check.IsStaffRole(role)) {
+		return models.AuthInfo{}, errUnauthorized
 	}
 
-	return models.AuthInfo{
-		UserID:   m["user_id"].(string),
+	info := models.AuthInfo{
 		UserRole: role,
-	}, nil
+	}
+	info.UserID, _ = m["user_id"].(string)
+	info.TokenID, _ = m["jti"].(string)
+	info.FamilyID, _ = m["family_id"].(string)
+	if iat, ok := m["iat"].(float64); ok {
+		info.IssuedAt = int64(iat)
+	}
+	if info.UserID == "" || info.TokenID == "" || info.FamilyID == "" {
+		return models.AuthInfo{}, errUnauthorized
+	}
+
+	revoked, err := h.Services.Auth().IsTokenRevoked(c.Request.Context(), info)
+	if err != nil {
+		return models.AuthInfo{}, err
+	}
+	if revoked {
+		return models.AuthInfo{}, service.ErrTokenRevoked
+	}
+
+	return info, nil
 }
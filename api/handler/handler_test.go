@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"rent-car/api/models"
+	"rent-car/pkg/check"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("test")
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:    "not found",
+			err:     errs.NotFound("car_not_found", "car not found").Wrap(errors.New("no rows in result set")),
+			status:  http.StatusNotFound,
+			code:    "car_not_found",
+			message: "car not found",
+		},
+		{
+			name:    "booking conflict keeps the details",
+			err:     &storage.BookingConflictError{CarID: "c1", FromDate: "2030-01-01", ToDate: "2030-01-05"},
+			status:  http.StatusConflict,
+			code:    "car_already_booked",
+			message: "car c1 is already booked between 2030-01-01 and 2030-01-05",
+		},
+		{
+			name:    "forbidden",
+			err:     fmt.Errorf("%w: customer can not finish", errs.Forbidden("status_transition_forbidden", "not allowed")),
+			status:  http.StatusForbidden,
+			code:    "status_transition_forbidden",
+			message: "not allowed: customer can not finish",
+		},
+		{
+			name:    "internal error text is hidden",
+			err:     errors.New(`pq: relation "cars" does not exist`),
+			status:  http.StatusInternalServerError,
+			code:    "internal_error",
+			message: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			handleError(c, log, "error while testing", tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+
+			resp := struct {
+				StatusCode int
+				Data       models.ErrorResponse
+			}{}
+			if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp)) {
+				assert.Equal(t, tt.status, resp.StatusCode)
+				assert.Equal(t, tt.code, resp.Data.Code)
+				assert.Equal(t, tt.message, resp.Data.Message)
+			}
+		})
+	}
+}
+
+func TestHandleErrorValidationFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handleError(c, logger.New("test"), "error while validating", storage.ErrInvalidCursor)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"StatusCode": 400,
+		"Description": "Bad request",
+		"Data": {
+			"code": "invalid_cursor",
+			"type": "validation_failed",
+			"message": "invalid cursor",
+			"fields": [{"field": "cursor", "message": "is malformed or belongs to another sort order"}]
+		}
+	}`, w.Body.String())
+}
+
+func TestCreateOrderReportsEveryInvalidField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := check.RegisterRules(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/order",
+		strings.NewReader(`{"car_id": "1", "from_date": "2001-01-10", "to_date": "2001-01-05"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	Handler{Log: logger.New("test")}.CreateOrder(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	resp := struct {
+		Data models.ErrorResponse
+	}{}
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp)) {
+		assert.Equal(t, "request_invalid", resp.Data.Code)
+		assert.ElementsMatch(t, []errs.FieldError{
+			{Field: "car_id", Message: "must be a valid uuid"},
+			{Field: "from_date", Message: "can not be in the past"},
+			{Field: "to_date", Message: "must be after from_date"},
+		}, resp.Data.Fields)
+	}
+}
+
+func TestUpdateCustomerDoesNotAskForPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := check.RegisterRules(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/customer/1",
+		strings.NewReader(`{"first_name": "Ali", "gmail": "ali@gmail.com", "phone": "+998901234567"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	Handler{Log: logger.New("test")}.UpdateCustomer(c)
+
+	// the body is accepted, only the id is refused
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	resp := struct {
+		Data models.ErrorResponse
+	}{}
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp)) {
+		if assert.Len(t, resp.Data.Fields, 1) {
+			assert.Equal(t, "id", resp.Data.Fields[0].Field)
+		}
+	}
+}
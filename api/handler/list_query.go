@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"fmt"
+	"rent-car/api/models"
+	"rent-car/config"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// kinds of list fields, the kind decides the allowed operators and how the value is parsed
+const (
+	fieldString = "string"
+	fieldUUID   = "uuid"
+	fieldInt    = "int"
+	fieldFloat  = "float"
+	fieldBool   = "bool"
+	fieldDate   = "date"
+	fieldTime   = "time"
+)
+
+var fieldOperators = map[string][]string{
+	fieldString: {config.FILTER_EQ, config.FILTER_NE, config.FILTER_LIKE, config.FILTER_IN},
+	fieldUUID:   {config.FILTER_EQ, config.FILTER_NE, config.FILTER_IN},
+	fieldInt:    {config.FILTER_EQ, config.FILTER_NE, config.FILTER_GT, config.FILTER_GTE, config.FILTER_LT, config.FILTER_LTE, config.FILTER_IN},
+	fieldFloat:  {config.FILTER_EQ, config.FILTER_NE, config.FILTER_GT, config.FILTER_GTE, config.FILTER_LT, config.FILTER_LTE, config.FILTER_IN},
+	fieldBool:   {config.FILTER_EQ, config.FILTER_NE},
+	fieldDate:   {config.FILTER_EQ, config.FILTER_NE, config.FILTER_GT, config.FILTER_GTE, config.FILTER_LT, config.FILTER_LTE},
+	fieldTime:   {config.FILTER_GT, config.FILTER_GTE, config.FILTER_LT, config.FILTER_LTE},
+}
+
+// listField is a field of a list resource clients may filter and, when sortable, sort on.
+type listField struct {
+	kind     string
+	sortable bool
+}
+
+var carListFields = map[string]listField{
+	"name":         {kind: fieldString, sortable: true},
+	"brand":        {kind: fieldString, sortable: true},
+	"model":        {kind: fieldString, sortable: true},
+	"colour":       {kind: fieldString},
+	"category":     {kind: fieldString},
+	"year":         {kind: fieldInt, sortable: true},
+	"hourse_power": {kind: fieldInt, sortable: true},
+	"engine_cap":   {kind: fieldFloat, sortable: true},
+	"daily_rate":   {kind: fieldFloat, sortable: true},
+	"created_at":   {kind: fieldTime, sortable: true},
+}
+
+var customerListFields = map[string]listField{
+	"first_name": {kind: fieldString, sortable: true},
+	"last_name":  {kind: fieldString, sortable: true},
+	"gmail":      {kind: fieldString},
+	"phone":      {kind: fieldString},
+	"is_blocked": {kind: fieldBool},
+	"created_at": {kind: fieldTime, sortable: true},
+}
+
+var orderListFields = map[string]listField{
+	"status":      {kind: fieldString, sortable: true},
+	"paid":        {kind: fieldBool},
+	"amount":      {kind: fieldFloat, sortable: true},
+	"from_date":   {kind: fieldDate, sortable: true},
+	"to_date":     {kind: fieldDate, sortable: true},
+	"created_at":  {kind: fieldTime, sortable: true},
+	"car_id":      {kind: fieldUUID},
+	"customer_id": {kind: fieldUUID},
+	"car_brand":   {kind: fieldString, sortable: true},
+	"overdue":     {kind: fieldBool},
+}
+
+var filterParam = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// parseListQuery reads filter[field]=value, filter[field][op]=value and sort=-field,field
+// from the query string, every field must be listed in fields.
+func parseListQuery(c *gin.Context, fields map[string]listField) ([]models.Filter, []models.SortField, error) {
+	filters, err := parseFilters(c.Request.URL.Query(), fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sorts, err := parseSort(c.Query("sort"), fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filters, sorts, nil
+}
+
+func parseFilters(query map[string][]string, fields map[string]listField) ([]models.Filter, error) {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, "filter") {
+			keys = append(keys, key)
+		}
+	}
+	// stable order, so the same url always builds the same query
+	sort.Strings(keys)
+
+	filters := []models.Filter{}
+	for _, key := range keys {
+		match := filterParam.FindStringSubmatch(key)
+		if match == nil {
+			return nil, fmt.Errorf("malformed filter parameter %q, use filter[field] or filter[field][op]", key)
+		}
+
+		name, op := match[1], match[2]
+		if op == "" {
+			op = config.FILTER_EQ
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter field %q, allowed: %s", name, strings.Join(fieldNames(fields, false), ", "))
+		}
+		if !contains(fieldOperators[field.kind], op) {
+			return nil, fmt.Errorf("operator %q is not allowed for field %q, allowed: %s", op, name, strings.Join(fieldOperators[field.kind], ", "))
+		}
+
+		for _, raw := range query[key] {
+			value, err := parseFilterValue(field.kind, op, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for filter field %q: %w", raw, name, err)
+			}
+			filters = append(filters, models.Filter{Field: name, Op: op, Value: value})
+		}
+	}
+	return filters, nil
+}
+
+func parseFilterValue(kind, op, raw string) (interface{}, error) {
+	if op != config.FILTER_IN {
+		return parseFieldValue(kind, raw)
+	}
+
+	parts := strings.Split(raw, ",")
+	switch kind {
+	case fieldInt:
+		values := make([]int64, 0, len(parts))
+		for _, part := range parts {
+			value, err := parseFieldValue(kind, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value.(int64))
+		}
+		return values, nil
+	case fieldFloat:
+		values := make([]float64, 0, len(parts))
+		for _, part := range parts {
+			value, err := parseFieldValue(kind, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value.(float64))
+		}
+		return values, nil
+	default:
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			value, err := parseFieldValue(kind, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value.(string))
+		}
+		return values, nil
+	}
+}
+
+func parseFieldValue(kind, raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("value is empty")
+	}
+
+	switch kind {
+	case fieldInt:
+		return strconv.ParseInt(raw, 10, 64)
+	case fieldFloat:
+		return strconv.ParseFloat(raw, 64)
+	case fieldBool:
+		return strconv.ParseBool(raw)
+	case fieldDate:
+		return time.Parse(time.DateOnly, raw)
+	case fieldTime:
+		if value, err := time.Parse(time.RFC3339, raw); err == nil {
+			return value, nil
+		}
+		return time.Parse(time.DateOnly, raw)
+	case fieldUUID:
+		if err := uuid.Validate(raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+	return raw, nil
+}
+
+func parseSort(raw string, fields map[string]listField) ([]models.SortField, error) {
+	sorts := []models.SortField{}
+	if strings.TrimSpace(raw) == "" {
+		return sorts, nil
+	}
+
+	seen := map[string]bool{}
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+
+		sortField := models.SortField{Field: strings.TrimLeft(item, "+-")}
+		sortField.Desc = strings.HasPrefix(item, "-")
+
+		field, ok := fields[sortField.Field]
+		if !ok || !field.sortable {
+			return nil, fmt.Errorf("unknown sort field %q, allowed: %s", sortField.Field, strings.Join(fieldNames(fields, true), ", "))
+		}
+		if seen[sortField.Field] {
+			return nil, fmt.Errorf("sort field %q is repeated", sortField.Field)
+		}
+		seen[sortField.Field] = true
+
+		sorts = append(sorts, sortField)
+	}
+	return sorts, nil
+}
+
+func fieldNames(fields map[string]listField, sortable bool) []string {
+	names := []string{}
+	for name, field := range fields {
+		if !sortable || field.sortable {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
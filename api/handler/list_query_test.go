@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/url"
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilters(t *testing.T) {
+	query, _ := url.ParseQuery("filter[brand]=bmw&filter[year][gte]=2018&filter[colour][in]=red,black&page=2")
+
+	filters, err := parseFilters(query, carListFields)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []models.Filter{
+			{Field: "brand", Op: config.FILTER_EQ, Value: "bmw"},
+			{Field: "colour", Op: config.FILTER_IN, Value: []string{"red", "black"}},
+			{Field: "year", Op: config.FILTER_GTE, Value: int64(2018)},
+		}, filters)
+	}
+}
+
+func TestParseFiltersTypedValues(t *testing.T) {
+	query, _ := url.ParseQuery("filter[from_date][gte]=2024-01-01&filter[paid]=true&filter[amount][lt]=99.5")
+
+	filters, err := parseFilters(query, orderListFields)
+	if assert.NoError(t, err) && assert.Len(t, filters, 3) {
+		assert.Equal(t, float64(99.5), filters[0].Value)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), filters[1].Value)
+		assert.Equal(t, true, filters[2].Value)
+	}
+}
+
+func TestParseFiltersRejects(t *testing.T) {
+	for _, raw := range []string{
+		"filter[password]=x",
+		"filter[brand][gte]=bmw",
+		"filter[year]=new",
+		"filter[year][between]=1",
+		"filter[brand=bmw",
+		"filter[brand]=",
+	} {
+		query, _ := url.ParseQuery(raw)
+		_, err := parseFilters(query, carListFields)
+		assert.Error(t, err, raw)
+	}
+
+	query, _ := url.ParseQuery("filter[car_id]=1 or 1=1")
+	_, err := parseFilters(query, orderListFields)
+	assert.Error(t, err)
+}
+
+func TestParseSort(t *testing.T) {
+	sorts, err := parseSort("-created_at, name", carListFields)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []models.SortField{
+			{Field: "created_at", Desc: true},
+			{Field: "name"},
+		}, sorts)
+	}
+
+	_, err = parseSort("colour", carListFields)
+	assert.Error(t, err)
+	_, err = parseSort("name,-name", carListFields)
+	assert.Error(t, err)
+	_, err = parseSort("name;drop table cars", carListFields)
+	assert.Error(t, err)
+}
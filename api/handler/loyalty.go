@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/loyalty [GET]
+// @Summary      Get customer loyalty points
+// @Description  the spendable points, the tier with its discount and a page of the points ledger, the newest first. Points are earned when an order is finished, spent with loyalty_points on POST /order and expire
+// @Tags         customer
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200 {object} models.CustomerLoyalty
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetCustomerLoyalty(c *gin.Context) {
+	request := models.GetLoyaltyRequest{CustomerId: c.Param("id")}
+	if err := uuid.Validate(request.CustomerId); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+request.CustomerId, errs.InvalidField("id", err))
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
+		return
+	}
+	request.Page = page
+	request.Limit = limit
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	loyalty, err := h.Services.Loyalty().Get(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while getting customer loyalty", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, loyalty)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/check"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	errUnauthorized  = errs.Unauthorized("unauthorized", "unauthorized")
+	errRoleForbidden = errs.Forbidden("role_forbidden", "role is not allowed to access the resource")
+	errNotOwner      = errs.Forbidden("not_owner", "customers can access only their own resources")
+)
+
+// Authenticate validates the access token and stores models.AuthInfo in the gin context.
+func (h Handler) Authenticate(c *gin.Context) {
+	info, err := h.getAuthInfo(c)
+	if err != nil {
+		handleError(c, h.Log, "error while authenticating request", err)
+		c.Abort()
+		return
+	}
+
+	c.Set(config.AUTH_INFO_KEY, info)
+	c.Next()
+}
+
+// RequireRoles lets the request through only when the caller has one of the given roles.
+func (h Handler) RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info := authInfo(c)
+		for _, role := range roles {
+			if info.UserRole == role {
+				c.Next()
+				return
+			}
+		}
+
+		handleError(c, h.Log, "permission denied for role: "+info.UserRole, errRoleForbidden)
+		c.Abort()
+	}
+}
+
+// CustomerOwnerOrStaff restricts /customer/:id routes to the customer itself or staff.
+func (h Handler) CustomerOwnerOrStaff(c *gin.Context) {
+	info := authInfo(c)
+	if check.IsStaffRole(info.UserRole) {
+		c.Next()
+		return
+	}
+
+	if info.UserID != c.Param("id") {
+		handleError(c, h.Log, "customer can access only own account", errNotOwner)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// OrderOwnerOrStaff restricts /order/:id routes to the customer who placed the order or staff.
+func (h Handler) OrderOwnerOrStaff(c *gin.Context) {
+	info := authInfo(c)
+	if check.IsStaffRole(info.UserRole) {
+		c.Next()
+		return
+	}
+
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+id, errs.InvalidField("id", err))
+		c.Abort()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	order, err := h.Services.Order().GetByIDOrder(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while getting order by id", err)
+		c.Abort()
+		return
+	}
+
+	if order.CustomerId != info.UserID {
+		handleError(c, h.Log, "customer can access only own orders", errNotOwner)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func authInfo(c *gin.Context) models.AuthInfo {
+	value, _ := c.Get(config.AUTH_INFO_KEY)
+	info, _ := value.(models.AuthInfo)
+	return info
+}
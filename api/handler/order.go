@@ -7,7 +7,7 @@ import (
 	_ "rent-car/api/docs"
 	"rent-car/api/models"
 	"rent-car/config"
-	"rent-car/pkg/check"
+	"rent-car/pkg/errs"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -17,67 +17,47 @@ import (
 // CreateOrder godoc
 // @Router       /order [POST]
 // @Summary      Creates a new orders
-// @Description  create a new order
+// @Description  create a new order, the customer needs an approved driver licence that is valid until to_date. The loyalty tier discount, an optional promo_code and loyalty_points spent are taken off the price in this order
 // @Tags         order
 // @Accept       json
 // @Produce      json
-// @Param        car body models.Order false "order"
+// @Param        order body models.CreateOrder false "order"
 // @Success      201 {object} models.CreateOrder
 // @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
 // @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
 // @Failure      500 {object} models.Response
 func (h Handler) CreateOrder(c *gin.Context) {
 	order := models.CreateOrder{}
 
-	data, err := getAuthInfo(c)
-	if err != nil {
-		handlerResponseLog(c, h.Log, "error while getting auth", http.StatusUnauthorized, err.Error())
-		return
-	}
+	data := authInfo(c)
 
 	if err := c.ShouldBindJSON(&order); err != nil {
-		handlerResponseLog(c,h.Log,"error while reding request", http.StatusBadRequest, err.Error())
-		return
-	}
-
-    if err := check.ValidateDateOfFormatForOrder(order.FromDate);err != nil{
-		handlerResponseLog(c,h.Log,"error in FromDate",http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while reding request", invalidBody(err))
 		return
 	}
 
-	if err := check.ValidateDateOfFormatForOrder(order.ToDate);err != nil{
-		handlerResponseLog(c,h.Log,"error in ToDate",http.StatusBadRequest, err.Error())
-		return
-	}
-	
 	order.Status = config.STATUS_NEW
-	order.CustomerId = data.UserID
-   
-	if err := check.ValidatingOrderStatusForAuth(order.Status);err != nil {
-		handlerResponseLog(c,h.Log,"error in order releted with status",http.StatusUnauthorized, err.Error())
-		return
+	order.ActorId = data.UserID
+	order.ActorRole = data.UserRole
+	if data.UserRole == config.CUSTOMER_ROLE {
+		order.CustomerId = data.UserID
 	}
 
-	order.CustomerId = c.Param("id")
-	err = uuid.Validate(order.CustomerId)
+	err := uuid.Validate(order.CustomerId)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while validating customer id,id: "+order.CustomerId, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	order.CarId = c.Param("id")
-	err = uuid.Validate(order.CarId)
-	if err != nil {
-		handlerResponseLog(c,h.Log,"error while validating car id,id: "+order.CarId, http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating customer id,id: "+order.CustomerId, errs.InvalidField("customer_id", err))
 		return
 	}
 
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
 	defer cancel()
 
+
 	id, err := h.Services.Order().Create(ctx,order)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while creating order", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while creating order", err)
 		return
 	}
 	handlerResponseLog(c,h.Log,"ok", http.StatusOK, id)
@@ -96,28 +76,30 @@ func (h Handler) CreateOrder(c *gin.Context) {
 // @Success      201 {object} models.Order
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
 // @Failure      500 {object} models.Response
 func (h Handler) UpdateOrder(c *gin.Context) {
 	order := models.UpdateOrder{}
 
 	if err := c.ShouldBindJSON(&order); err != nil {
-		handlerResponseLog(c,h.Log,"error while reading request body", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
 	order.Id = c.Param("id")
 	err := uuid.Validate(order.Id)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while validating", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating", errs.InvalidField("id", err))
 		return
 	}
 
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
 	defer cancel()
 
+
 	id, err := h.Services.Order().Update(ctx,order)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while updating customer,err", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while updating customer,err", err)
 		return
 	}
 	handlerResponseLog(c,h.Log,"ok", http.StatusOK, id)
@@ -127,13 +109,15 @@ func (h Handler) UpdateOrder(c *gin.Context) {
 // GetOrderList godoc
 // @Router       /orders [GET]
 // @Summary      Get order list
-// @Description  get order list
+// @Description  get order list, filter with filter[field]=value or filter[field][op]=value (ops: eq, ne, gt, gte, lt, lte, like, in), e.g. filter[status]=new&filter[from_date][gte]=2024-01-01
 // @Tags         order
 // @Accept       json
 // @Produce      json
 // @Param        page query string false "page"
 // @Param        limit query string false "limit"
+// @Param        cursor query string false "next_cursor or prev_cursor of a previous page, an empty value starts cursor pagination instead of page"
 // @Param        search query string false "search"
+// @Param        sort query string false "comma separated fields, prefix with - for descending, e.g. -created_at,name"
 // @Success      201 {object} models.GetAllOrdersResponse
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
@@ -146,12 +130,12 @@ func (h Handler) GetAllOrder(c *gin.Context) {
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while parsing page", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
 		return
 	}
 	limit, err := ParseLimitQueryParam(c)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while parsing limit", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
 		return
 	}
 	fmt.Println("page: ", page)
@@ -159,13 +143,20 @@ func (h Handler) GetAllOrder(c *gin.Context) {
 
 	request.Page = page
 	request.Limit = limit
+	request.Cursor, request.UseCursor = c.GetQuery("cursor")
+
+	request.Filters, request.Sort, err = parseListQuery(c, orderListFields)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing filters", invalidQuery(err))
+		return
+	}
 
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
 	defer cancel()
 
 	orders, err := h.Services.Order().GetOrderAll(ctx,request)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while getting orders", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while getting orders", err)
 		return
 	}
 
@@ -181,7 +172,7 @@ func (h Handler) GetAllOrder(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "order"
-// @Success      201 {object} models.Order
+// @Success      201 {object} models.OrderAll
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
 // @Failure      500 {object} models.Response
@@ -193,7 +184,7 @@ func (h Handler) GetByIDOrder(c *gin.Context) {
 
 	order, err := h.Services.Order().GetByIDOrder(ctx,id)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while getting order by id", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while getting order by id", err)
 		return
 	}
 	handlerResponseLog(c, h.Log,"ok", http.StatusOK, order)
@@ -203,7 +194,7 @@ func (h Handler) GetByIDOrder(c *gin.Context) {
 // DeleteOrder godoc
 // @Router       /order/{id} [DELETE]
 // @Summary      Delete order
-// @Description  Delete order
+// @Description  Delete a new order that has no payments, promo code or loyalty points, any other order is canceled instead
 // @Tags         order
 // @Accept       json
 // @Produce      json
@@ -211,20 +202,21 @@ func (h Handler) GetByIDOrder(c *gin.Context) {
 // @Success      201 {object} models.Response
 // @Failure      400 {object} models.Response
 // @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
 // @Failure      500 {object} models.Response
 func (h Handler) DeleteOrder(c *gin.Context) {
 	id := c.Param("id")
 	err := uuid.Validate(id)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while validating id", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating id", errs.InvalidField("id", err))
 		return
 	}
 	ctx,cancel:= context.WithTimeout(c,config.TimewithContex)
 	defer cancel()
 
-	err = h.Services.Customer().Delete(ctx,id)
+	err = h.Services.Order().Delete(ctx,id)
 	if err != nil {
-		handlerResponseLog(c,h.Log,"error while deleting customer", http.StatusInternalServerError, err.Error())
+		handleError(c, h.Log, "error while deleting order", err)
 		return
 	}
 	handlerResponseLog(c,h.Log,"ok", http.StatusOK, id)
@@ -235,45 +227,47 @@ func (h Handler) DeleteOrder(c *gin.Context) {
 // UpdateOrderStatus godoc
 // @Router 		/order/status/{id} [PATCH]
 // @Summary 	update a order
-// @Description This api is update a  order status and returns it's id
+// @Description Moves the order along its lifecycle: new -> in-process -> finished, new -> canceled, in-process -> canceled. When finishing, actual_return_at (default now) charges the late days after to_date and the grace period. Canceling refunds by the cancellation policy, see POST /order/{id}/cancel
 // @Tags 		order
 // @Accept		json
 // @Produce		json
 // @Param		id path string true "id"
-// @Param		status path string true "status"
-// @Success		200  {object}  models.GetOrder
+// @Param		status body models.UpdateOrderStatus true "status"
+// @Success		200  {object}  models.Response
 // @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
 // @Failure		404  {object}  models.Response
+// @Failure		409  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) UpdateOrderStatus(c *gin.Context) {
-	Order := models.GetOrder{}
+	Order := models.UpdateOrderStatus{}
 
 	if err := c.ShouldBindJSON(&Order); err != nil {
-		handlerResponseLog(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
 		return
 	}
 
 	Order.Id = c.Param("id")
-	Order.Status = c.Param("status")
-
-
-
-	if err := check.ValidatingOrderStatusForAuth(Order.Status); err != nil {
-		handlerResponseLog(c,  h.Log,"error check order status: "+Order.Status, http.StatusBadRequest,err.Error())
-		return
-	}
 
 	err := uuid.Validate(Order.Id)
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while validating Order id,id: "+Order.Id, http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while validating Order id,id: "+Order.Id, errs.InvalidField("id", err))
 		return
 	}
 
-	id, err := h.Services.Order().UpdateStatus(context.Background(),Order)
+	// canceling refunds through the payment provider, like POST /order/{id}/cancel
+	timeout := config.TimewithContex
+	if Order.Status == config.STATUS_CANCELED {
+		timeout = config.PaymentTimeout
+	}
+	ctx, cancel := context.WithTimeout(c, timeout)
+	defer cancel()
+
+	id, err := h.Services.Order().UpdateStatus(ctx, Order, authInfo(c))
 	if err != nil {
-		handlerResponseLog(c, h.Log, "error while updating Order status", http.StatusBadRequest, err.Error())
+		handleError(c, h.Log, "error while updating Order status", err)
 		return
 	}
 
 	handlerResponseLog(c, h.Log, "Updated successfully", http.StatusOK, id)
-}
\ No newline at end of file
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/check"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /outbox [GET]
+// @Summary      Get outbox events
+// @Description  list order events queued for the notifiers, admin only
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        status query string false "pending, sent or dead"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200 {object} models.GetOutboxEventsResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetOutboxEvents(c *gin.Context) {
+	request := models.GetOutboxEventsRequest{
+		Status: c.Query("status"),
+	}
+
+	if request.Status != "" {
+		if err := check.ValidateOutboxStatus(request.Status); err != nil {
+			handleError(c, h.Log, "error while validating status: "+request.Status, err)
+			return
+		}
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
+		return
+	}
+	request.Page = page
+	request.Limit = limit
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	events, err := h.Services.Outbox().GetList(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while getting outbox events", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, events)
+}
+
+// @Security ApiKeyAuth
+// @Router       /outbox/{id}/replay [POST]
+// @Summary      Replay a dead outbox event
+// @Description  queue a dead-lettered event for delivery again, admin only
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "outbox event id"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) ReplayOutboxEvent(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating outbox event id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	err := h.Services.Outbox().Replay(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while replaying outbox event", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
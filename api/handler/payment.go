@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /order/{id}/payments [POST]
+// @Summary      Record a payment
+// @Description  records a payment, deposit or refund of the order, card money is moved through the payment provider. Customers can only pay by card, the paid state of the order is derived from its payments
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Param        payment body models.RecordPayment true "payment"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) RecordPayment(c *gin.Context) {
+	request := models.RecordPayment{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	request.OrderId = c.Param("id")
+	if err := uuid.Validate(request.OrderId); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+request.OrderId, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.PaymentTimeout)
+	defer cancel()
+
+	id, err := h.Services.Payment().Record(ctx, request, authInfo(c))
+	if err != nil {
+		handleError(c, h.Log, "error while recording payment", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /order/{id}/payments [GET]
+// @Summary      Get payments of an order
+// @Description  get the payments of the order, oldest first, and the balance derived from them
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Success      200 {object} models.GetPaymentsResponse
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetPayments(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	payments, err := h.Services.Payment().GetByOrderID(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while getting payments", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, payments)
+}
+
+// @Security ApiKeyAuth
+// @Router       /order/{id}/deposit/settle [POST]
+// @Summary      Settle the deposit of a returned order
+// @Description  adds the damage or late fee charges to the finished or canceled order, captures the held deposit against what the customer owes and releases the rest. Without charges only the outstanding amount is captured
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Param        settlement body models.SettleDeposit false "settlement"
+// @Success      200 {object} models.OrderDeposit
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) SettleDeposit(c *gin.Context) {
+	request := models.SettleDeposit{}
+
+	// the body is optional, a release without charges needs none
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			handleError(c, h.Log, "error while reading request body", invalidBody(err))
+			return
+		}
+	}
+
+	request.OrderId = c.Param("id")
+	if err := uuid.Validate(request.OrderId); err != nil {
+		handleError(c, h.Log, "error while validating order id,id: "+request.OrderId, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.PaymentTimeout)
+	defer cancel()
+
+	deposit, err := h.Services.Payment().SettleDeposit(ctx, request, authInfo(c))
+	if err != nil {
+		handleError(c, h.Log, "error while settling deposit", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, deposit)
+}
+
+// @Security ApiKeyAuth
+// @Router       /reports/finance [GET]
+// @Summary      Finance report
+// @Description  sums the payments, refunds and deposit movements recorded in [from, to) and the money held in open deposits now
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Param        from query string true "first day, YYYY-MM-DD"
+// @Param        to query string true "day after the last day, YYYY-MM-DD"
+// @Success      200 {object} models.FinanceReport
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetFinanceReport(c *gin.Context) {
+	request := models.FinanceReportRequest{}
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handleError(c, h.Log, "error while validating from and to dates", invalidQuery(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	report, err := h.Services.Payment().Report(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while building finance report", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, report)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /promo-code [POST]
+// @Summary      Create a promo code
+// @Description  adds a percent or fixed discount valid in [valid_from, valid_to), a zero limit is unlimited and no brands make every car eligible, admin only
+// @Tags         promo
+// @Accept       json
+// @Produce      json
+// @Param        promo body models.CreatePromoCode true "promo code"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) CreatePromoCode(c *gin.Context) {
+	request := models.CreatePromoCode{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	id, err := h.Services.Promo().Create(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while creating promo code", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /promo-codes [GET]
+// @Summary      Get promo codes
+// @Description  lists the promo codes with their uses, the newest first, admin only
+// @Tags         promo
+// @Accept       json
+// @Produce      json
+// @Param        search query string false "search in code and description"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200 {object} models.GetPromoCodesResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetPromoCodes(c *gin.Context) {
+	request := models.GetPromoCodesRequest{}
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handleError(c, h.Log, "error while reading query", invalidQuery(err))
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
+		return
+	}
+	request.Page = page
+	request.Limit = limit
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	promos, err := h.Services.Promo().GetList(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while getting promo codes", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, promos)
+}
+
+// @Security ApiKeyAuth
+// @Router       /promo-code/{id} [GET]
+// @Summary      Get a promo code
+// @Description  get a promo code with its uses, admin only
+// @Tags         promo
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "promo_code_id"
+// @Success      200 {object} models.PromoCode
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetPromoCode(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating promo code id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	promo, err := h.Services.Promo().GetByID(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while getting promo code", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, promo)
+}
+
+// @Security ApiKeyAuth
+// @Router       /promo-code/{id} [PUT]
+// @Summary      Update a promo code
+// @Description  changes the terms of a promo code or deactivates it, orders that redeemed it keep their discount, admin only
+// @Tags         promo
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "promo_code_id"
+// @Param        promo body models.UpdatePromoCode true "promo code"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) UpdatePromoCode(c *gin.Context) {
+	request := models.UpdatePromoCode{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	request.Id = c.Param("id")
+	if err := uuid.Validate(request.Id); err != nil {
+		handleError(c, h.Log, "error while validating promo code id,id: "+request.Id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Promo().Update(ctx, request); err != nil {
+		handleError(c, h.Log, "error while updating promo code", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "Updated successfully", http.StatusOK, request.Id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /promo-code/{id} [DELETE]
+// @Summary      Delete a promo code
+// @Description  deletes a promo code that was never redeemed, redeemed codes can only be deactivated, admin only
+// @Tags         promo
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "promo_code_id"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) DeletePromoCode(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating promo code id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Promo().Delete(ctx, id); err != nil {
+		handleError(c, h.Log, "error while deleting promo code", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StaffLogin godoc
+// @Router       /staff/login [POST]
+// @Summary      Staff login
+// @Description  Staff login
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        login body models.StaffLoginRequest true "login"
+// @Success      200  {object}  models.StaffLoginResponse
+// @Failure      400  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) StaffLogin(c *gin.Context) {
+	loginReq := models.StaffLoginRequest{}
+
+	if err := c.ShouldBindJSON(&loginReq); err != nil {
+		handleError(c, h.Log, "error while binding body", invalidBody(err))
+		return
+	}
+
+	loginResp, err := h.Services.Auth().StaffLogin(c.Request.Context(), loginReq)
+	if err != nil {
+		handleError(c, h.Log, "unauthorized", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "succes", http.StatusOK, loginResp)
+}
+
+// @Security ApiKeyAuth
+// @Router       /staff [POST]
+// @Summary      Creates a new staff account
+// @Description  create a new staff account, admin only
+// @Tags         staff
+// @Accept       json
+// @Produce      json
+// @Param        staff body models.CreateStaff true "staff"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) CreateStaff(c *gin.Context) {
+	staff := models.CreateStaff{}
+
+	if err := c.ShouldBindJSON(&staff); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	id, err := h.Services.Staff().Create(ctx, staff)
+	if err != nil {
+		handleError(c, h.Log, "error while creating staff", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, id)
+}
+
+// @Security ApiKeyAuth
+// @Router       /staff/{id} [GET]
+// @Summary      Gets staff
+// @Description  get staff account by ID
+// @Tags         staff
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "staff_id"
+// @Success      200 {object} models.Staff
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetByIDStaff(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating staff id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	staff, err := h.Services.Staff().GetByID(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while getting staff by id", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, staff)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/verification [PUT]
+// @Summary      Submit driver licence
+// @Description  submits the driver licence, date of birth and document scans of the customer for review, a new submission replaces the previous one
+// @Tags         verification
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Param        verification body models.SubmitVerification true "verification"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) SubmitVerification(c *gin.Context) {
+	request := models.SubmitVerification{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	request.CustomerId = c.Param("id")
+	if err := uuid.Validate(request.CustomerId); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+request.CustomerId, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Verification().Submit(ctx, request); err != nil {
+		handleError(c, h.Log, "error while submitting verification", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, request.CustomerId)
+}
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/verification [GET]
+// @Summary      Get driver licence verification
+// @Description  get the submitted driver licence of the customer and its review status
+// @Tags         verification
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Success      200 {object} models.CustomerVerification
+// @Failure      400 {object} models.Response
+// @Failure      403 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetVerification(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+id, errs.InvalidField("id", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	verification, err := h.Services.Verification().GetByCustomerID(ctx, id)
+	if err != nil {
+		handleError(c, h.Log, "error while getting verification", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, verification)
+}
+
+// @Security ApiKeyAuth
+// @Router       /verifications [GET]
+// @Summary      Get verifications
+// @Description  list submitted driver licences, oldest first, staff only
+// @Tags         verification
+// @Accept       json
+// @Produce      json
+// @Param        status query string false "pending, approved or rejected"
+// @Param        page query string false "page"
+// @Param        limit query string false "limit"
+// @Success      200 {object} models.GetVerificationsResponse
+// @Failure      400 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) GetVerifications(c *gin.Context) {
+	request := models.GetVerificationsRequest{}
+
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handleError(c, h.Log, "error while reading query", invalidQuery(err))
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing page", errs.InvalidField("page", err))
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleError(c, h.Log, "error while parsing limit", errs.InvalidField("limit", err))
+		return
+	}
+	request.Page = page
+	request.Limit = limit
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	verifications, err := h.Services.Verification().GetList(ctx, request)
+	if err != nil {
+		handleError(c, h.Log, "error while getting verifications", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, verifications)
+}
+
+// @Security ApiKeyAuth
+// @Router       /customer/{id}/verification/review [POST]
+// @Summary      Review driver licence
+// @Description  approves or rejects a pending verification, a rejection needs a reason, staff only
+// @Tags         verification
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "customer_id"
+// @Param        review body models.ReviewVerification true "review"
+// @Success      200 {object} models.Response
+// @Failure      400 {object} models.Response
+// @Failure      404 {object} models.Response
+// @Failure      409 {object} models.Response
+// @Failure      500 {object} models.Response
+func (h Handler) ReviewVerification(c *gin.Context) {
+	request := models.ReviewVerification{}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleError(c, h.Log, "error while reading request body", invalidBody(err))
+		return
+	}
+
+	request.CustomerId = c.Param("id")
+	if err := uuid.Validate(request.CustomerId); err != nil {
+		handleError(c, h.Log, "error while validating customer id,id: "+request.CustomerId, errs.InvalidField("id", err))
+		return
+	}
+	request.ReviewerId = authInfo(c).UserID
+
+	ctx, cancel := context.WithTimeout(c, config.TimewithContex)
+	defer cancel()
+
+	if err := h.Services.Verification().Review(ctx, request); err != nil {
+		handleError(c, h.Log, "error while reviewing verification", err)
+		return
+	}
+	handlerResponseLog(c, h.Log, "ok", http.StatusOK, request.CustomerId)
+}
@@ -1,8 +1,8 @@
 package models
 
 type CustomerLoginRequest struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
 
 type CustomerLoginResponse struct {
@@ -13,4 +13,24 @@ type CustomerLoginResponse struct {
 type AuthInfo struct {
 	UserID   string `json:"user_id"`
 	UserRole string `json:"user_role"`
+	TokenID  string `json:"token_id"`
+	FamilyID string `json:"family_id"`
+	IssuedAt int64  `json:"issued_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RevokedToken struct {
+	Jti       string `json:"jti"`
+	FamilyID  string `json:"family_id"`
+	UserID    string `json:"user_id"`
+	Reason    string `json:"reason"`
+	ExpiresAt int64  `json:"expires_at"`
 }
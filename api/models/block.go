@@ -0,0 +1,23 @@
+package models
+
+// BlockCustomerRequest blocks a customer, without Until the block lasts until it is lifted.
+type BlockCustomerRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+	Until  string `json:"until" binding:"omitempty,future_time"`
+}
+
+type UnblockCustomerRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// CustomerBlockEvent is one entry of the audit trail of blocks, Action is blocked or unblocked.
+type CustomerBlockEvent struct {
+	Id           string `json:"id"`
+	CustomerId   string `json:"customer_id"`
+	Action       string `json:"action"`
+	Reason       string `json:"reason"`
+	BlockedUntil string `json:"blocked_until,omitempty"`
+	ActorId      string `json:"actor_id"`
+	ActorRole    string `json:"actor_role"`
+	CreatedAt    string `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+type CancelOrder struct {
+	OrderId string `json:"-"`
+	Reason  string `json:"reason" binding:"max=500"`
+}
+
+// OrderCancellation is the cancellation policy applied to a canceled order and the refund it gave,
+// Refunds are the ledger entries of the refund. RefundFailed is the part of the refund the payment
+// provider declined, it is left in the paid amount for staff to refund. Loyalty points spent on the
+// order are given back and expire at PointsExpireAt.
+type OrderCancellation struct {
+	OrderId        string          `json:"order_id"`
+	Policy         string          `json:"policy"`
+	RefundPercent  float32         `json:"refund_percent"`
+	RefundAmount   float32         `json:"refund_amount"`
+	RefundFailed   float32         `json:"refund_failed,omitempty"`
+	Reason         string          `json:"reason,omitempty"`
+	ActorId        string          `json:"actor_id,omitempty"`
+	ActorRole      string          `json:"actor_role,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	FromStatus     string          `json:"-"`
+	Refunds        []RecordPayment `json:"-"`
+	PointsExpireAt string          `json:"-"`
+}
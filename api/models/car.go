@@ -3,38 +3,85 @@ package models
 type Car struct {
 	Id          string  `json:"id"`
 	Name        string  `json:"name"`
-	Year        int     `json:"year"`
+	Year        int     `json:"year" binding:"car_year"`
 	Brand       string  `json:"brand"`
 	Model       string  `json:"model"`
 	HoursePower int     `json:"hoursepower"`
 	Colour      string  `json:"colour"`
 	EngineCap   float32 `json:"engineCap"`
+	DailyRate   float32 `json:"daily_rate" binding:"gt=0"`
+	WeekendRate float32 `json:"weekend_rate" binding:"gte=0"`
+	Category    string  `json:"category" binding:"omitempty,car_category"`
 	CreatedAt   string  `json:"createdAt"`
 	UpdatedAt   string  `json:"updatedAt"`
+	DeletedAt   string  `json:"deletedAt,omitempty"`
+	SeasonalRates []SeasonalRate `json:"seasonal_rates,omitempty"`
 	GetOrder    []GetOrder `json:"order"`
 }
 
 type CreateCar struct {
 	Id          string  `json:"id"`
 	Name        string  `json:"name"`
-	Year        int     `json:"year"`
+	Year        int     `json:"year" binding:"car_year"`
 	Brand       string  `json:"brand"`
 	Model       string  `json:"model"`
 	HoursePower int     `json:"hoursepower"`
 	Colour      string  `json:"colour"`
 	EngineCap   float32 `json:"engineCap"`
+	DailyRate   float32 `json:"daily_rate" binding:"gt=0"`
+	WeekendRate float32 `json:"weekend_rate" binding:"gte=0"`
+	Category    string  `json:"category" binding:"omitempty,car_category"`
 	CreatedAt   string  `json:"createdAt"`
 	UpdatedAt   string  `json:"updatedAt"`
 }
 
+// SeasonalRate overrides the daily rate of a car for every day in [FromDate, ToDate).
+type SeasonalRate struct {
+	Id        string  `json:"id"`
+	CarId     string  `json:"car_id"`
+	Name      string  `json:"name"`
+	FromDate  string  `json:"from_date" binding:"required,date"`
+	ToDate    string  `json:"to_date" binding:"required,date,after=from_date"`
+	DailyRate float32 `json:"daily_rate" binding:"gt=0"`
+}
+
+// DateRange is the [from, to) rental window of the availability and quote queries.
+type DateRange struct {
+	From string `form:"from" binding:"required,date,not_past"`
+	To   string `form:"to" binding:"required,date,after=from"`
+}
+
 type GetAllCarsResponse struct {
 	Cars  []Car `json:"cars"`
 	Count int64 `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type GetAllCarsRequest struct {
     Search string `json:"search"`
 	Page uint64 `json:"page"`
 	Limit uint64 `json:"limit"`
+	Filters []Filter `json:"filters"`
+	Sort []SortField `json:"sort"`
+	Cursor string `json:"cursor"`
+	UseCursor bool `json:"-"`
 }
 
+type GetAvailableCarsRequest struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Search          string  `json:"search"`
+	Brand           string  `json:"brand"`
+	Colour          string  `json:"colour"`
+	YearFrom        int     `json:"year_from"`
+	YearTo          int     `json:"year_to"`
+	HoursePowerFrom int     `json:"hoursepower_from"`
+	HoursePowerTo   int     `json:"hoursepower_to"`
+	EngineCapFrom   float32 `json:"engine_cap_from"`
+	EngineCapTo     float32 `json:"engine_cap_to"`
+	Page            uint64  `json:"page"`
+	Limit           uint64  `json:"limit"`
+	Cursor string `json:"cursor"`
+	UseCursor bool `json:"-"`
+}
@@ -4,12 +4,24 @@ type Customer struct{
 	Id          string  `json:"id"`
 	FirstName   string  `json:"first_name"`
 	LastName    string  `json:"last_name"`
-	Gmail       string  `json:"gmail"`
-	Phone       string  `json:"phone"`
-	Password    string  `json:"password"`
+	Gmail       string  `json:"gmail" binding:"required,gmail"`
+	Phone       string  `json:"phone" binding:"required,phone"`
+	Password    string  `json:"password" binding:"required,password"`
  	Is_Blocked    bool  `json:"isblocked"`
+	BlockedReason string `json:"blocked_reason,omitempty"`
+	BlockedUntil  string `json:"blocked_until,omitempty"`
 	CreatedAt   string  `json:"createdAt"`
 	UpdatedAt   string  `json:"updatedAt"`
+	DeletedAt   string  `json:"deletedAt,omitempty"`
+}
+
+// UpdateCustomer is the profile a customer can change, the password is changed with PasswordOfCustomer.
+type UpdateCustomer struct {
+	Id        string `json:"-"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Gmail     string `json:"gmail" binding:"required,gmail"`
+	Phone     string `json:"phone" binding:"required,phone"`
 }
 
 type GetAllCustomer struct{
@@ -20,26 +32,34 @@ type GetAllCustomer struct{
 	Phone       string  `json:"phone"`
 	Password    string  `json:"password"`
 	Is_Blocked  bool    `json:"isblocked"`
+	BlockedReason string `json:"blocked_reason,omitempty"`
+	BlockedUntil  string `json:"blocked_until,omitempty"`
 	CreatedAt   string  `json:"createdAt"`
 	UpdatedAt   string  `json:"updatedAt"`
 	Order       Order   `json:"order"`
 }
 
 type PasswordOfCustomer struct {
-	Phone       string  `json:"phone"`
-	NewPassword string `json:"new_password"`
-	Password string `json:"password"`
+	Phone       string  `json:"phone" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,password,differs=password"`
+	Password string `json:"password" binding:"required"`
 }
 
 type GetAllCustomersResponse struct {
 	Customers []GetAllCustomer `json:"customers"`
 	Count int16 `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type GetAllCustomersRequest struct {
     Search string `json:"search"`
 	Page uint64 `json:"page"`
 	Limit uint64 `json:"limit"`
+	Filters []Filter `json:"filters"`
+	Sort []SortField `json:"sort"`
+	Cursor string `json:"cursor"`
+	UseCursor bool `json:"-"`
 }
      
 // type GetAllCustomerCars struct{
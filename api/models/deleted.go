@@ -0,0 +1,19 @@
+package models
+
+// GetDeletedRequest lists soft deleted records, most recently deleted first.
+type GetDeletedRequest struct {
+	Page  uint64 `json:"page"`
+	Limit uint64 `json:"limit"`
+}
+
+type GetDeletedCustomersResponse struct {
+	Customers []Customer `json:"customers"`
+	Count     int        `json:"count"`
+}
+
+// PurgeResponse reports how many soft deleted records older than the retention period were removed.
+// Records still referenced by orders are kept, so the order history stays complete.
+type PurgeResponse struct {
+	Purged        int64 `json:"purged"`
+	RetentionDays int   `json:"retention_days"`
+}
@@ -0,0 +1,54 @@
+package models
+
+// OrderDeposit is the refundable deposit of an order. Amount is set by the category of the car, Held is what the
+// customer paid into it through the payments ledger. When the order is returned the deposit is settled: it is
+// captured against what is outstanding and the rest is released.
+type OrderDeposit struct {
+	OrderId   string  `json:"order_id"`
+	Amount    float32 `json:"amount"`
+	Held      float32 `json:"held"`
+	Captured  float32 `json:"captured"`
+	Released  float32 `json:"released"`
+	Status    string  `json:"status"`
+	Method    string  `json:"method,omitempty"`
+	SettledBy string  `json:"settled_by,omitempty"`
+	SettledAt string  `json:"settled_at,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// OrderCharge is a fee added to a returned order, e.g. for damage.
+type OrderCharge struct {
+	Kind        string  `json:"kind" binding:"required,charge_kind"`
+	Description string  `json:"description" binding:"required,max=100"`
+	Amount      float32 `json:"amount" binding:"required,gt=0"`
+}
+
+// SettleDeposit adds the charges to the order, captures Capture of the held deposit and releases Release to the customer.
+type SettleDeposit struct {
+	OrderId   string        `json:"-"`
+	Charges   []OrderCharge `json:"charges" binding:"omitempty,dive"`
+	Capture   float32       `json:"-"`
+	Release   float32       `json:"-"`
+	Method    string        `json:"-"`
+	ActorId   string        `json:"-"`
+	ActorRole string        `json:"-"`
+}
+
+type FinanceReportRequest struct {
+	From string `form:"from" binding:"required,date"`
+	To   string `form:"to" binding:"required,date,after=from"`
+}
+
+// FinanceReport sums the ledger entries recorded in [From, To), DepositsHeld is the money held in open deposits now.
+type FinanceReport struct {
+	From              string  `json:"from"`
+	To                string  `json:"to"`
+	Revenue           float32 `json:"revenue"`
+	Payments          float32 `json:"payments"`
+	Refunds           float32 `json:"refunds"`
+	DepositsCollected float32 `json:"deposits_collected"`
+	DepositsReleased  float32 `json:"deposits_released"`
+	DepositsCaptured  float32 `json:"deposits_captured"`
+	DepositsHeld      float32 `json:"deposits_held"`
+	OpenDeposits      int     `json:"open_deposits"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// ExtendOrder asks to move the to_date of an order later.
+type ExtendOrder struct {
+	OrderId string `json:"-"`
+	ToDate  string `json:"to_date" binding:"required,date"`
+	Note    string `json:"note" binding:"max=500"`
+}
+
+// OrderExtension is an amendment of an order, the extra days from PreviousToDate to ToDate priced separately
+// from the original booking.
+type OrderExtension struct {
+	Id             string          `json:"id"`
+	OrderId        string          `json:"order_id"`
+	PreviousToDate string          `json:"previous_to_date"`
+	ToDate         string          `json:"to_date"`
+	Days           int             `json:"days"`
+	Amount         float32         `json:"amount"`
+	LineItems      []OrderLineItem `json:"line_items"`
+	Status         string          `json:"status"`
+	Note           string          `json:"note,omitempty"`
+	RequestedBy    string          `json:"requested_by,omitempty"`
+	RequestedRole  string          `json:"requested_role,omitempty"`
+	ReviewedBy     string          `json:"reviewed_by,omitempty"`
+	ReviewReason   string          `json:"review_reason,omitempty"`
+	ReviewedAt     string          `json:"reviewed_at,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+// ReviewExtension approves or rejects a pending extension, a rejection needs a reason.
+type ReviewExtension struct {
+	OrderId     string `json:"-"`
+	ExtensionId string `json:"-"`
+	ReviewerId  string `json:"-"`
+	Status      string `json:"status" binding:"required,oneof=approved rejected"`
+	Reason      string `json:"reason" binding:"required_if=Status rejected,max=500"`
+}
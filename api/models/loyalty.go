@@ -0,0 +1,47 @@
+package models
+
+// LoyaltyEntry is one row of the points ledger, credits are positive and debits negative.
+type LoyaltyEntry struct {
+	Id         string `json:"id"`
+	CustomerId string `json:"customer_id"`
+	OrderId    string `json:"order_id,omitempty"`
+	Kind       string `json:"kind"`
+	Points     int    `json:"points"`
+	ExpiresAt  string `json:"expires_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// LoyaltyBalance is what the ledger of a customer adds up to.
+type LoyaltyBalance struct {
+	// Points can be spent, the points that expired are left out even before the expiry is recorded
+	Points int
+	// Expired were not spent before they expired and are not recorded as expired yet
+	Expired int
+	// TierPoints were earned since the start of the tier window
+	TierPoints int
+}
+
+type GetLoyaltyRequest struct {
+	CustomerId string `json:"-"`
+	Page       uint64 `json:"page"`
+	Limit      uint64 `json:"limit"`
+}
+
+type LoyaltyHistory struct {
+	Entries []LoyaltyEntry `json:"entries"`
+	Count   int            `json:"count"`
+}
+
+// CustomerLoyalty is the points balance and tier of a customer with a page of the ledger, the newest first.
+type CustomerLoyalty struct {
+	CustomerId          string         `json:"customer_id"`
+	Points              int            `json:"points"`
+	PointValue          float32        `json:"point_value"`
+	Tier                string         `json:"tier"`
+	TierDiscountPercent float32        `json:"tier_discount_percent"`
+	TierPoints          int            `json:"tier_points"`
+	NextTier            string         `json:"next_tier,omitempty"`
+	PointsToNextTier    int            `json:"points_to_next_tier,omitempty"`
+	History             []LoyaltyEntry `json:"history"`
+	Count               int            `json:"count"`
+}
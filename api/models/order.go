@@ -15,13 +15,21 @@ type GetOrder struct {
 
 type CreateOrder struct {
 	Id         string `json:"id"`
-	CarId      string `json:"car_id"`
-	CustomerId string `json:"customer_id"`
-	FromDate   string `json:"from_date"`
-	ToDate     string `json:"to_date"`
+	CarId      string `json:"car_id" binding:"required,uuid"`
+	CustomerId string `json:"customer_id" binding:"omitempty,uuid"`
+	FromDate   string `json:"from_date" binding:"required,date,not_past"`
+	ToDate     string `json:"to_date" binding:"required,date,after=from_date"`
 	Status     string `json:"status"`
-	Paid       bool   `json:"paid"`
 	Amount     float32  `json:"amount"`
+	Deposit    float32  `json:"-"`
+	PromoCode  string `json:"promo_code" binding:"omitempty,max=30"`
+	Redemption *PromoRedemption `json:"-"`
+	// LoyaltyPoints are spent on the order, the discount is capped at its price
+	LoyaltyPoints int `json:"loyalty_points" binding:"gte=0"`
+	LoyaltyRedemption *LoyaltyEntry `json:"-"`
+	LineItems  []OrderLineItem `json:"line_items"`
+	ActorId    string `json:"-"`
+	ActorRole  string `json:"-"`
 	CreatedAt string   `json:"created_at"`
 }
 type OrderAll struct {
@@ -33,6 +41,17 @@ type OrderAll struct {
 	Status     string `json:"status"`
 	Paid       bool   `json:"paid"`
 	Amount     float32  `json:"amount"`
+	Discount   float32  `json:"discount"`
+	PromoCode  string   `json:"promo_code,omitempty"`
+	LineItems  []OrderLineItem `json:"line_items"`
+	StatusHistory []OrderStatusHistory `json:"status_history"`
+	Balance    OrderBalance `json:"balance"`
+	Deposit    OrderDeposit `json:"deposit"`
+	OverdueSince   string `json:"overdue_since,omitempty"`
+	LateDays       int    `json:"late_days"`
+	ActualReturnAt string `json:"actual_return_at,omitempty"`
+	Cancellation   *OrderCancellation `json:"cancellation,omitempty"`
+	Extensions     []OrderExtension `json:"extensions"`
 	CreatedAt string   `json:"created_at"`
 	UpdatedAt string   `json:"updated_at"`
 }
@@ -41,23 +60,95 @@ type UpdateOrder struct {
 	Id         string `json:"id"`
 	CarId      string `json:"car_id"`
 	CustomerId string `json:"customer_id"`
-	FromDate   string `json:"from_date"`
-	ToDate     string `json:"to_date"`
+	FromDate   string `json:"from_date" binding:"required,date,not_past"`
+	ToDate     string `json:"to_date" binding:"required,date,after=from_date"`
 	Status     string `json:"status"`
-	Paid       bool   `json:"paid"`
 	Amount     float32 `json:"amount"`
+	Discount   float32 `json:"-"`
+	LineItems  []OrderLineItem `json:"line_items"`
 	UpdatedAt string  `json:"updated_at"`
 }
 
+type UpdateOrderStatus struct {
+	Id     string `json:"-"`
+	Status string `json:"status" binding:"required,order_status"`
+	// ActualReturnAt is when a finished order was returned, now when empty
+	ActualReturnAt string `json:"actual_return_at" binding:"omitempty,past_time"`
+}
+
+// OrderStatusChange moves an order from FromStatus to ToStatus on behalf of the actor.
+type OrderStatusChange struct {
+	OrderId    string `json:"order_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ActorId    string `json:"actor_id"`
+	ActorRole  string `json:"actor_role"`
+	ActualReturnAt string `json:"-"`
+	LateFee    LateFee `json:"-"`
+	// Loyalty are the points a finished order earns
+	Loyalty    LoyaltyEntry `json:"-"`
+}
+
+// LateFee charges the late days of an order after the ChargedDays charged already.
+type LateFee struct {
+	OrderId     string
+	ChargedDays int
+	LateDays    int
+	Items       []OrderLineItem
+}
+
+// OverdueOrder is an in-process order past its to_date and grace period.
+type OverdueOrder struct {
+	Id       string
+	CarId    string
+	ToDate   string
+	LateDays int
+}
+
+type OrderStatusHistory struct {
+	Id         string `json:"id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ActorId    string `json:"actor_id"`
+	ActorRole  string `json:"actor_role"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// OrderLineItem is one row of the price breakdown of an order, discounts have a negative amount.
+type OrderLineItem struct {
+	Id          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float32 `json:"unit_price"`
+	Amount      float32 `json:"amount"`
+}
+
+type PriceQuote struct {
+	CarId     string          `json:"car_id"`
+	FromDate  string          `json:"from_date"`
+	ToDate    string          `json:"to_date"`
+	Days      int             `json:"days"`
+	LineItems []OrderLineItem `json:"line_items"`
+	Amount    float32         `json:"amount"`
+	Deposit   float32         `json:"deposit"`
+}
+
 type GetAllOrdersResponse struct {
 	Orders []GetOrder `json:"orders"`
 	Count  int        `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type GetAllOrdersRequest struct {
     Search string `json:"search"`
 	Page uint64 `json:"page"`
 	Limit uint64 `json:"limit"`
+	Filters []Filter `json:"filters"`
+	Sort []SortField `json:"sort"`
+	Cursor string `json:"cursor"`
+	UseCursor bool `json:"-"`
 }
 
 
@@ -92,4 +183,12 @@ type SendMessage struct {
 	Status    string   `json:"status"`
 	Paid      bool     `json:"payment_status"`
 	Phone     string  `json:"phone"`
+	LateDays  int     `json:"late_days,omitempty"`
+}
+
+// OrderEvent is delivered to the notifiers when an order is created, updated or changes status.
+type OrderEvent struct {
+	Type    string      `json:"type"`
+	OrderId string      `json:"order_id"`
+	Order   SendMessage `json:"order"`
 }
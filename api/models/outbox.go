@@ -0,0 +1,38 @@
+package models
+
+// OutboxEvent is an order event waiting for, or done with, delivery to the notifiers.
+// DeliveredChannels are the notifier channels that took the event already.
+type OutboxEvent struct {
+	Id                string     `json:"id"`
+	EventType         string     `json:"event_type"`
+	OrderId           string     `json:"order_id"`
+	Payload           OrderEvent `json:"payload"`
+	Status            string     `json:"status"`
+	Attempts          int        `json:"attempts"`
+	LastError         string     `json:"last_error"`
+	DeliveredChannels []string   `json:"delivered_channels"`
+	NextAttemptAt     string     `json:"next_attempt_at"`
+	CreatedAt         string     `json:"created_at"`
+	SentAt            string     `json:"sent_at"`
+}
+
+// OutboxFailure records a failed delivery, Dead stops any further retries.
+// Delivered are the channels that took the event so far, they are skipped by the retry.
+type OutboxFailure struct {
+	Id        string
+	Error     string
+	Delivered []string
+	RetryIn   float64
+	Dead      bool
+}
+
+type GetOutboxEventsRequest struct {
+	Status string `json:"status"`
+	Page   uint64 `json:"page"`
+	Limit  uint64 `json:"limit"`
+}
+
+type GetOutboxEventsResponse struct {
+	Events []OutboxEvent `json:"events"`
+	Count  int           `json:"count"`
+}
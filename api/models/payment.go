@@ -0,0 +1,45 @@
+package models
+
+// RecordPayment is one money movement of an order, the amount is always positive and the kind tells its direction.
+type RecordPayment struct {
+	OrderId     string  `json:"-"`
+	Kind        string  `json:"kind" binding:"required,payment_kind"`
+	Method      string  `json:"method" binding:"required,payment_method"`
+	Amount      float32 `json:"amount" binding:"required,gt=0"`
+	Note        string  `json:"note" binding:"max=500"`
+	ProviderRef string  `json:"-"`
+	Status      string  `json:"-"`
+	ActorId     string  `json:"-"`
+	ActorRole   string  `json:"-"`
+}
+
+type Payment struct {
+	Id          string  `json:"id"`
+	OrderId     string  `json:"order_id"`
+	Kind        string  `json:"kind"`
+	Method      string  `json:"method"`
+	Amount      float32 `json:"amount"`
+	Status      string  `json:"status"`
+	ProviderRef string  `json:"provider_ref,omitempty"`
+	Note        string  `json:"note,omitempty"`
+	ActorId     string  `json:"actor_id,omitempty"`
+	ActorRole   string  `json:"actor_role,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// OrderBalance is derived from the payments of an order. Refunds are taken from the paid amount,
+// deposits are held apart and only settle the order when they are captured. A pending card payment
+// is not paid yet but is no longer outstanding, pending refunds and releases are taken out right away.
+type OrderBalance struct {
+	Amount      float32 `json:"amount"`
+	PaidAmount  float32 `json:"paid_amount"`
+	Refunded    float32 `json:"refunded"`
+	DepositHeld float32 `json:"deposit_held"`
+	Outstanding float32 `json:"outstanding"`
+	Paid        bool    `json:"paid"`
+}
+
+type GetPaymentsResponse struct {
+	Payments []Payment    `json:"payments"`
+	Balance  OrderBalance `json:"balance"`
+}
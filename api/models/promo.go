@@ -0,0 +1,72 @@
+package models
+
+// PromoCode is a discount of a campaign, a zero limit is unlimited and empty Brands makes every car eligible.
+type PromoCode struct {
+	Id                 string   `json:"id"`
+	Code               string   `json:"code"`
+	Description        string   `json:"description"`
+	Kind               string   `json:"kind"`
+	Value              float32  `json:"value"`
+	ValidFrom          string   `json:"valid_from"`
+	ValidTo            string   `json:"valid_to"`
+	MaxUses            int      `json:"max_uses"`
+	MaxUsesPerCustomer int      `json:"max_uses_per_customer"`
+	MinDays            int      `json:"min_days"`
+	Brands             []string `json:"brands"`
+	Active             bool     `json:"active"`
+	// Uses counts the redemptions by orders that were not canceled
+	Uses      int    `json:"uses"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type CreatePromoCode struct {
+	Code               string   `json:"code" binding:"required,promo_code"`
+	Description        string   `json:"description" binding:"max=200"`
+	Kind               string   `json:"kind" binding:"required,promo_kind"`
+	Value              float32  `json:"value" binding:"required,gt=0"`
+	ValidFrom          string   `json:"valid_from" binding:"required,date"`
+	ValidTo            string   `json:"valid_to" binding:"required,date,after=valid_from"`
+	MaxUses            int      `json:"max_uses" binding:"gte=0"`
+	MaxUsesPerCustomer int      `json:"max_uses_per_customer" binding:"gte=0"`
+	MinDays            int      `json:"min_days" binding:"gte=0"`
+	Brands             []string `json:"brands" binding:"dive,required,max=50"`
+}
+
+type UpdatePromoCode struct {
+	Id                 string   `json:"-"`
+	Description        string   `json:"description" binding:"max=200"`
+	Kind               string   `json:"kind" binding:"required,promo_kind"`
+	Value              float32  `json:"value" binding:"required,gt=0"`
+	ValidFrom          string   `json:"valid_from" binding:"required,date"`
+	ValidTo            string   `json:"valid_to" binding:"required,date,after=valid_from"`
+	MaxUses            int      `json:"max_uses" binding:"gte=0"`
+	MaxUsesPerCustomer int      `json:"max_uses_per_customer" binding:"gte=0"`
+	MinDays            int      `json:"min_days" binding:"gte=0"`
+	Brands             []string `json:"brands" binding:"dive,required,max=50"`
+	Active             bool     `json:"active"`
+}
+
+type GetPromoCodesRequest struct {
+	Search string `json:"search" form:"search"`
+	Page   uint64 `json:"page"`
+	Limit  uint64 `json:"limit"`
+}
+
+type GetPromoCodesResponse struct {
+	PromoCodes []PromoCode `json:"promo_codes"`
+	Count      int         `json:"count"`
+}
+
+// PromoUsage counts the redemptions of a code by orders that were not canceled, in total and by one customer.
+type PromoUsage struct {
+	Uses         int
+	CustomerUses int
+}
+
+// PromoRedemption applies a promo code to an order of the customer.
+type PromoRedemption struct {
+	PromoCodeId string
+	CustomerId  string
+	Discount    float32
+}
@@ -0,0 +1,16 @@
+package models
+
+// Filter is one parsed filter[field][op]=value condition of a list request.
+// Value is already converted to the field type: string, int64, float64, bool, time.Time
+// or a slice of one of them for the "in" operator.
+type Filter struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// SortField is one entry of the sort parameter, -created_at sorts by created_at descending.
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
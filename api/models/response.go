@@ -1,7 +1,18 @@
 package models
 
+import "rent-car/pkg/errs"
+
 type Response struct {
 	StatusCode  int
 	Description string
 	Data        interface{}
 }
+
+// ErrorResponse is the Data of every error response. Code is a stable machine readable code,
+// e.g. car_not_found, Type is the error class and Fields lists the invalid request fields.
+type ErrorResponse struct {
+	Code    string            `json:"code"`
+	Type    string            `json:"type"`
+	Message string            `json:"message"`
+	Fields  []errs.FieldError `json:"fields,omitempty"`
+}
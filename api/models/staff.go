@@ -0,0 +1,28 @@
+package models
+
+type Staff struct {
+	Id        string `json:"id"`
+	FullName  string `json:"full_name"`
+	Login     string `json:"login"`
+	Password  string `json:"-"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type CreateStaff struct {
+	FullName string `json:"full_name" binding:"required"`
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required,password"`
+	Role     string `json:"role" binding:"required,staff_role"`
+}
+
+type StaffLoginRequest struct {
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type StaffLoginResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
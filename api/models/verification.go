@@ -0,0 +1,53 @@
+package models
+
+// CustomerVerification is the driver licence and identity a customer submitted and how staff reviewed it.
+type CustomerVerification struct {
+	CustomerId     string                 `json:"customer_id"`
+	LicenceNumber  string                 `json:"licence_number"`
+	LicenceCountry string                 `json:"licence_country"`
+	LicenceExpiry  string                 `json:"licence_expiry"`
+	DateOfBirth    string                 `json:"date_of_birth"`
+	Documents      []VerificationDocument `json:"documents"`
+	Status         string                 `json:"status"`
+	Reason         string                 `json:"reason,omitempty"`
+	ReviewedBy     string                 `json:"reviewed_by,omitempty"`
+	ReviewedAt     string                 `json:"reviewed_at,omitempty"`
+	SubmittedAt    string                 `json:"submitted_at"`
+}
+
+// VerificationDocument points to an uploaded scan, Kind is one of config.DOCUMENT_KINDS.
+type VerificationDocument struct {
+	Id        string `json:"id"`
+	Kind      string `json:"kind" binding:"required,document_kind"`
+	Url       string `json:"url" binding:"required,url"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SubmitVerification replaces the customer's verification and puts it back in review.
+type SubmitVerification struct {
+	CustomerId     string                 `json:"-"`
+	LicenceNumber  string                 `json:"licence_number" binding:"required,max=30"`
+	LicenceCountry string                 `json:"licence_country" binding:"required,iso3166_1_alpha2"`
+	LicenceExpiry  string                 `json:"licence_expiry" binding:"required,date,not_past"`
+	DateOfBirth    string                 `json:"date_of_birth" binding:"required,date,before_today"`
+	Documents      []VerificationDocument `json:"documents" binding:"required,min=1,dive"`
+}
+
+// ReviewVerification approves or rejects a pending verification, a rejection needs a reason.
+type ReviewVerification struct {
+	CustomerId string `json:"-"`
+	ReviewerId string `json:"-"`
+	Status     string `json:"status" binding:"required,oneof=approved rejected"`
+	Reason     string `json:"reason" binding:"required_if=Status rejected"`
+}
+
+type GetVerificationsRequest struct {
+	Status string `json:"status" form:"status" binding:"omitempty,verification_status"`
+	Page   uint64 `json:"page"`
+	Limit  uint64 `json:"limit"`
+}
+
+type GetVerificationsResponse struct {
+	Verifications []CustomerVerification `json:"verifications"`
+	Count         int                    `json:"count"`
+}
@@ -1,15 +1,15 @@
 package api
 
 import (
-	"errors"
-	// "log"
-	"net/http"
 	"rent-car/api/handler"
+	"rent-car/config"
+	"rent-car/pkg/check"
 	"rent-car/pkg/logger"
 	"rent-car/service"
 
-
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -24,45 +24,87 @@ import (
 func New(services service.IServiceManager,log logger.ILogger) *gin.Engine {
 	h := handler.NewStrg(services,log)
 
+	// request models declare their rules in binding tags, see pkg/check
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := check.RegisterRules(v); err != nil {
+			log.Error("error while registering validation rules", logger.Error(err))
+		}
+	}
+
 
 	r := gin.Default()
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	// r.Use(authMiddleware)
-    r.POST("/customer/login",h.CustomerLogin)
-
-	r.POST("/car", h.CreateCar)
-	r.GET("/car/:id", h.GetByIDCar)
-	r.GET("/cars", h.GetAllCars)
-	r.GET("/availablecars", h.GetAvaibleCars)
-	r.PUT("/car/:id", h.UpdateCar)
-	r.DELETE("/car/:id", h.DeleteCar)
-	
+
+	// public routes
+	r.POST("/customer/login",h.CustomerLogin)
+	r.POST("/staff/login", h.StaffLogin)
+	r.POST("/auth/refresh", h.RefreshToken)
+	r.POST("/auth/logout", h.Logout)
 	r.POST("/customer", h.CreateCustomer)
-	r.GET("/customer/:id", h.GetByIDCustomer)
-	r.GET("/customers", h.GetAllCustomer)
-	r.PUT("/customer/:id", h.UpdateCustomer)
-	r.PATCH("/customer/password",h.UpdateCustomerPassword)
-	r.DELETE("/customer/:id", h.DeleteCustomer)
-
-	r.POST("/order", h.CreateOrder)
-	r.GET("/order/:id", h.GetAllOrder)
-	r.GET("/orders", h.GetAllOrder)
-	r.PATCH("/order/status/:id",h.UpdateOrderStatus)
-	r.PUT("/order/:id", h.UpdateOrder)
-	r.DELETE("/order/:id", h.DeleteOrder)
 
-	return r
+	// every route below requires a valid access token
+	authorized := r.Group("/", h.Authenticate)
+	staff := authorized.Group("/", h.RequireRoles(config.STAFF_ROLES...))
+	fleet := authorized.Group("/", h.RequireRoles(config.ADMIN_ROLE, config.MANAGER_ROLE))
+	admin := authorized.Group("/", h.RequireRoles(config.ADMIN_ROLE))
 
-}
+	admin.POST("/staff", h.CreateStaff)
+	staff.GET("/staff/:id", h.GetByIDStaff)
 
+	fleet.POST("/car", h.CreateCar)
+	authorized.GET("/car/:id", h.GetByIDCar)
+	authorized.GET("/cars", h.GetAllCars)
+	authorized.GET("/availablecars", h.GetAvaibleCars)
+	fleet.PUT("/car/:id", h.UpdateCar)
+	fleet.DELETE("/car/:id", h.DeleteCar)
+	authorized.GET("/car/:id/quote", h.QuoteCar)
+	fleet.POST("/car/:id/rates", h.CreateSeasonalRate)
+	fleet.DELETE("/car/:id/rates/:rate_id", h.DeleteSeasonalRate)
+	admin.GET("/cars/deleted", h.GetDeletedCars)
+	admin.POST("/car/:id/restore", h.RestoreCar)
+	admin.DELETE("/cars/deleted", h.PurgeDeletedCars)
 
-func authMiddleware(c *gin.Context) {
-	auth := c.GetHeader("Authorization")
-	if auth == "" {
-		c.AbortWithError(http.StatusUnauthorized, errors.New("unauthorized"))
-	}
-	c.Next()
-}
+	authorized.GET("/customer/:id", h.CustomerOwnerOrStaff, h.GetByIDCustomer)
+	staff.GET("/customers", h.GetAllCustomer)
+	authorized.PUT("/customer/:id", h.CustomerOwnerOrStaff, h.UpdateCustomer)
+	authorized.PATCH("/customer/password",h.UpdateCustomerPassword)
+	authorized.DELETE("/customer/:id", h.CustomerOwnerOrStaff, h.DeleteCustomer)
+	admin.GET("/customers/deleted", h.GetDeletedCustomers)
+	admin.POST("/customer/:id/restore", h.RestoreCustomer)
+	admin.DELETE("/customers/deleted", h.PurgeDeletedCustomers)
+	admin.POST("/customer/:id/block", h.BlockCustomer)
+	admin.POST("/customer/:id/unblock", h.UnblockCustomer)
+	staff.GET("/customer/:id/blocks", h.GetCustomerBlocks)
+	authorized.GET("/customer/:id/loyalty", h.CustomerOwnerOrStaff, h.GetCustomerLoyalty)
+
+	authorized.PUT("/customer/:id/verification", h.CustomerOwnerOrStaff, h.SubmitVerification)
+	authorized.GET("/customer/:id/verification", h.CustomerOwnerOrStaff, h.GetVerification)
+	staff.GET("/verifications", h.GetVerifications)
+	staff.POST("/customer/:id/verification/review", h.ReviewVerification)
+
+	authorized.POST("/order", h.CreateOrder)
+	authorized.GET("/order/:id", h.OrderOwnerOrStaff, h.GetByIDOrder)
+	staff.GET("/orders", h.GetAllOrder)
+	authorized.PATCH("/order/status/:id", h.OrderOwnerOrStaff, h.UpdateOrderStatus)
+	authorized.PUT("/order/:id", h.OrderOwnerOrStaff, h.UpdateOrder)
+	authorized.DELETE("/order/:id", h.OrderOwnerOrStaff, h.DeleteOrder)
+	authorized.POST("/order/:id/cancel", h.OrderOwnerOrStaff, h.CancelOrder)
+	authorized.POST("/order/:id/extend", h.OrderOwnerOrStaff, h.ExtendOrder)
+	staff.POST("/order/:id/extensions/:extension_id/review", h.ReviewExtension)
+	authorized.POST("/order/:id/payments", h.OrderOwnerOrStaff, h.RecordPayment)
+	authorized.GET("/order/:id/payments", h.OrderOwnerOrStaff, h.GetPayments)
+	staff.POST("/order/:id/deposit/settle", h.SettleDeposit)
+	fleet.GET("/reports/finance", h.GetFinanceReport)
 
+	admin.POST("/promo-code", h.CreatePromoCode)
+	admin.GET("/promo-codes", h.GetPromoCodes)
+	admin.GET("/promo-code/:id", h.GetPromoCode)
+	admin.PUT("/promo-code/:id", h.UpdatePromoCode)
+	admin.DELETE("/promo-code/:id", h.DeletePromoCode)
 
+	admin.GET("/outbox", h.GetOutboxEvents)
+	admin.POST("/outbox/:id/replay", h.ReplayOutboxEvent)
 
+	return r
+
+}
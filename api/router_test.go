@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/jwt"
+	"rent-car/pkg/logger"
+	"rent-car/service"
+	"rent-car/storage"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStorage answers only what the tested routes ask for, every other call panics on the nil interface.
+type fakeStorage struct {
+	storage.IStorage
+	customers map[string]models.Customer
+	orders    map[string]models.OrderAll
+}
+
+func (s fakeStorage) Customer() storage.ICustomerStorage {
+	return fakeCustomers{customers: s.customers}
+}
+
+func (s fakeStorage) Token() storage.ITokenStorage {
+	return fakeTokens{}
+}
+
+func (s fakeStorage) Order() storage.IOrderStorage {
+	return fakeOrders{orders: s.orders}
+}
+
+type fakeTokens struct {
+	storage.ITokenStorage
+}
+
+func (fakeTokens) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return false, nil
+}
+
+func (fakeTokens) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	return false, nil
+}
+
+func (fakeTokens) IsUserRevoked(ctx context.Context, userID string, issuedAt int64) (bool, error) {
+	return false, nil
+}
+
+type fakeCustomers struct {
+	storage.ICustomerStorage
+	customers map[string]models.Customer
+}
+
+func (c fakeCustomers) GetByID(ctx context.Context, id string) (models.Customer, error) {
+	customer, ok := c.customers[id]
+	if !ok {
+		return models.Customer{}, errs.NotFound("customer_not_found", "customer not found")
+	}
+	return customer, nil
+}
+
+type fakeOrders struct {
+	storage.IOrderStorage
+	orders map[string]models.OrderAll
+}
+
+func (o fakeOrders) GetByID(ctx context.Context, id string) (models.OrderAll, error) {
+	order, ok := o.orders[id]
+	if !ok {
+		return models.OrderAll{}, errs.NotFound("order_not_found", "order not found")
+	}
+	return order, nil
+}
+
+func TestRouterAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("test")
+
+	customerID, otherCustomerID := uuid.NewString(), uuid.NewString()
+	orderID, otherOrderID := uuid.NewString(), uuid.NewString()
+	strg := fakeStorage{
+		customers: map[string]models.Customer{
+			customerID:      {Id: customerID},
+			otherCustomerID: {Id: otherCustomerID},
+		},
+		orders: map[string]models.OrderAll{
+			orderID:      {Id: orderID, CustomerId: customerID},
+			otherOrderID: {Id: otherOrderID, CustomerId: otherCustomerID},
+		},
+	}
+	r := New(service.New(strg, log, nil, nil, config.Config{}), log)
+
+	tokens := func(userID, role string) (string, string) {
+		access, refresh, err := jwt.GenJWT(map[interface{}]interface{}{
+			"user_id":   userID,
+			"user_role": role,
+			"family_id": uuid.NewString(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return access, refresh
+	}
+	customer, customerRefresh := tokens(customerID, config.CUSTOMER_ROLE)
+	agent, _ := tokens(uuid.NewString(), config.AGENT_ROLE)
+	manager, _ := tokens(uuid.NewString(), config.MANAGER_ROLE)
+	adminToken, _ := tokens(uuid.NewString(), config.ADMIN_ROLE)
+	review := "/customer/" + customerID + "/verification/review"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+		code   string
+	}{
+		{"missing token", http.MethodGet, "/customer/" + customerID, "", http.StatusUnauthorized, "unauthorized"},
+		{"malformed token", http.MethodGet, "/customer/" + customerID, "not-a-jwt", http.StatusUnauthorized, "invalid_token"},
+		{"refresh token used as access token", http.MethodGet, "/customer/" + customerID, customerRefresh, http.StatusUnauthorized, "unauthorized"},
+
+		{"customer reads own account", http.MethodGet, "/customer/" + customerID, customer, http.StatusOK, ""},
+		{"customer reads another customer", http.MethodGet, "/customer/" + otherCustomerID, customer, http.StatusForbidden, "not_owner"},
+		{"agent reads any customer", http.MethodGet, "/customer/" + otherCustomerID, agent, http.StatusOK, ""},
+
+		{"customer reads own order", http.MethodGet, "/order/" + orderID, customer, http.StatusOK, ""},
+		{"customer reads another customer's order", http.MethodGet, "/order/" + otherOrderID, customer, http.StatusForbidden, "not_owner"},
+		{"customer reads unknown order", http.MethodGet, "/order/" + uuid.NewString(), customer, http.StatusNotFound, "order_not_found"},
+		{"customer reads invalid order id", http.MethodGet, "/order/1", customer, http.StatusBadRequest, "invalid_id"},
+		{"agent reads any order", http.MethodGet, "/order/" + otherOrderID, agent, http.StatusOK, ""},
+
+		// past the role gates the handlers refuse the empty body
+		{"customer reviews a verification", http.MethodPost, review, customer, http.StatusForbidden, "role_forbidden"},
+		{"agent reviews a verification", http.MethodPost, review, agent, http.StatusBadRequest, ""},
+		{"agent creates a car", http.MethodPost, "/car", agent, http.StatusForbidden, "role_forbidden"},
+		{"manager creates a car", http.MethodPost, "/car", manager, http.StatusBadRequest, ""},
+		{"admin creates a car", http.MethodPost, "/car", adminToken, http.StatusBadRequest, ""},
+		{"customer reads the finance report", http.MethodGet, "/reports/finance", customer, http.StatusForbidden, "role_forbidden"},
+		{"agent reads the finance report", http.MethodGet, "/reports/finance", agent, http.StatusForbidden, "role_forbidden"},
+		{"manager creates staff", http.MethodPost, "/staff", manager, http.StatusForbidden, "role_forbidden"},
+		{"admin creates staff", http.MethodPost, "/staff", adminToken, http.StatusBadRequest, ""},
+		{"manager creates a promo code", http.MethodPost, "/promo-code", manager, http.StatusForbidden, "role_forbidden"},
+		{"admin creates a promo code", http.MethodPost, "/promo-code", adminToken, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.code != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+tt.code+`"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/check"
+	"rent-car/pkg/logger"
+	"rent-car/pkg/notifier"
+	"rent-car/pkg/payment"
+	"rent-car/service"
+	"rent-car/storage/postgres"
+)
+
+// bootstrap creates the first admin account, e.g.
+// go run cmd/bootstrap/main.go -login admin -password 'Secret#123' -name 'Main Admin'
+func main() {
+	login := flag.String("login", "admin", "admin login")
+	pass := flag.String("password", "", "admin password")
+	name := flag.String("name", "Administrator", "admin full name")
+	flag.Parse()
+
+	if err := check.ValidatePassword(*pass); err != nil {
+		fmt.Println("error while validating password, err: ", err)
+		return
+	}
+
+	cfg := config.Load()
+
+	log := logger.New(cfg.ServiceName)
+	store, err := postgres.New(context.Background(), cfg)
+	if err != nil {
+		fmt.Println("error while connecting db, err: ", err)
+		return
+	}
+	defer store.CloseDB()
+
+	services := service.New(store, log, notifier.New(cfg), payment.NewFake(), cfg)
+
+	id, err := services.Staff().CreateFirstAdmin(context.Background(), models.CreateStaff{
+		FullName: *name,
+		Login:    *login,
+		Password: *pass,
+	})
+	if err != nil {
+		fmt.Println("error while creating admin, err: ", err)
+		return
+	}
+
+	fmt.Println("admin account is created, id: ", id)
+}
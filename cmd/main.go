@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"rent-car/api"
 	"rent-car/config"
 	"rent-car/pkg/logger"
+	"rent-car/pkg/notifier"
+	"rent-car/pkg/payment"
 	"rent-car/service"
 	"rent-car/storage/postgres"
 )
@@ -13,6 +16,20 @@ import (
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			fmt.Println("error while migrating db, err: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	provider, err := payment.New(cfg)
+	if err != nil {
+		fmt.Println("error while creating payment provider, err: ", err)
+		os.Exit(1)
+	}
+
 	log := logger.New(cfg.ServiceName)
 	store, err := postgres.New(context.Background(), cfg)
 	if err != nil {
@@ -21,7 +38,14 @@ func main() {
 	}
 	defer store.CloseDB()
 
-	services := service.New(store,log)
+	services := service.New(store,log,notifier.New(cfg),provider,cfg)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go services.Outbox().Run(jobsCtx)
+	go services.LateReturn().Run(jobsCtx)
+	go services.Loyalty().Run(jobsCtx)
+
 	c := api.New(services, log)
 
 	fmt.Println("programm is running on localhost:8080...")
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rent-car/config"
+	"rent-car/storage/postgres"
+	"strconv"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | goto <version>"
+
+// runMigrate handles the migrate subcommand: migrate up, down [steps], status or goto <version>.
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.MigrateTimeout)
+	defer cancel()
+
+	pool, err := postgres.NewPool(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := postgres.NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return errors.New("steps must be a positive number")
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return errors.New("version must be a number, 0 rolls back everything")
+		}
+		return migrator.Goto(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied at " + s.AppliedAt
+			}
+			fmt.Printf("%06d %-20s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
@@ -3,6 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -16,6 +20,40 @@ type Config struct {
 	PostgresDatabase string
 
 	ServiceName string
+
+	AutoMigrate bool
+
+	TelegramBotToken string
+	TelegramChatID   string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	SMTPFrom     string
+	NotifyEmails []string
+
+	WebhookURL    string
+	WebhookSecret string
+
+	PaymentProvider string
+
+	// AllowFakePayments lets PAYMENT_PROVIDER=fake confirm card payments without moving money,
+	// it is meant for local development only
+	AllowFakePayments bool
+
+	// ExtensionRequiresApproval keeps the extensions customers ask for pending until staff approve them,
+	// otherwise they are applied right away. Extensions asked for by staff never wait.
+	ExtensionRequiresApproval bool
+
+	// MinDriverAge is the age a customer must have reached on the first day of a rental
+	MinDriverAge int
+
+	// LoyaltyPointsExpiry is how long earned points can be spent
+	LoyaltyPointsExpiry time.Duration
+
+	// CancellationPolicy refunds canceled new orders, see CANCELLATION_POLICY
+	CancellationPolicy []CancellationRule
 }
 
 func Load() Config {
@@ -31,6 +69,53 @@ func Load() Config {
 	cfg.PostgresUser = cast.ToString(getOrReturnDefault("POSTGRES_USER", "person"))
 	cfg.PostgresPassword = cast.ToString(getOrReturnDefault("POSTGRES_PASSWORD", "1234"))
     cfg.ServiceName = cast.ToString(getOrReturnDefault("SERVICE_NAME","rent_car_api_gateway"))
+	cfg.AutoMigrate = cast.ToBool(getOrReturnDefault("AUTO_MIGRATE", false))
+
+	cfg.TelegramBotToken = cast.ToString(getOrReturnDefault("TELEGRAM_BOT_TOKEN", ""))
+	cfg.TelegramChatID = cast.ToString(getOrReturnDefault("TELEGRAM_CHAT_ID", ""))
+
+	cfg.SMTPHost = cast.ToString(getOrReturnDefault("SMTP_HOST", ""))
+	cfg.SMTPPort = cast.ToInt(getOrReturnDefault("SMTP_PORT", 587))
+	cfg.SMTPUser = cast.ToString(getOrReturnDefault("SMTP_USER", ""))
+	cfg.SMTPPassword = cast.ToString(getOrReturnDefault("SMTP_PASSWORD", ""))
+	cfg.SMTPFrom = cast.ToString(getOrReturnDefault("SMTP_FROM", ""))
+	cfg.NotifyEmails = splitList(cast.ToString(getOrReturnDefault("NOTIFY_EMAILS", "")))
+
+	cfg.WebhookURL = cast.ToString(getOrReturnDefault("WEBHOOK_URL", ""))
+	cfg.WebhookSecret = cast.ToString(getOrReturnDefault("WEBHOOK_SECRET", ""))
+
+	cfg.PaymentProvider = cast.ToString(getOrReturnDefault("PAYMENT_PROVIDER", ""))
+	cfg.AllowFakePayments = cast.ToBool(getOrReturnDefault("ALLOW_FAKE_PAYMENTS", false))
+
+	cfg.ExtensionRequiresApproval = cast.ToBool(getOrReturnDefault("EXTENSION_REQUIRES_APPROVAL", true))
+	cfg.MinDriverAge = 21
+	if value := cast.ToString(getOrReturnDefault("MIN_DRIVER_AGE", "")); value != "" {
+		age, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || age <= 0 {
+			fmt.Println("error!!! MIN_DRIVER_AGE must be a positive number of years, using 21:", value)
+		} else {
+			cfg.MinDriverAge = age
+		}
+	}
+	cfg.LoyaltyPointsExpiry = 365 * 24 * time.Hour
+	if value := cast.ToString(getOrReturnDefault("LOYALTY_POINTS_EXPIRY", "")); value != "" {
+		expiry, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || expiry <= 0 {
+			fmt.Println("error!!! LOYALTY_POINTS_EXPIRY must be a positive Go duration such as 8760h, using 8760h:", value)
+		} else {
+			cfg.LoyaltyPointsExpiry = expiry
+		}
+	}
+
+	cfg.CancellationPolicy = CANCELLATION_POLICY
+	if value := cast.ToString(getOrReturnDefault("CANCELLATION_POLICY", "")); value != "" {
+		policy, err := parseCancellationPolicy(value)
+		if err != nil {
+			fmt.Println("error!!! CANCELLATION_POLICY, using the default policy:", err)
+		} else {
+			cfg.CancellationPolicy = policy
+		}
+	}
 
 	return cfg
 }
@@ -43,3 +128,47 @@ func getOrReturnDefault(key string, defaultValue interface{}) interface{} {
 	return os.Getenv(key)
 }
 
+// parseCancellationPolicy reads rules written as name:min_notice:refund_percent separated by commas,
+// e.g. free_cancellation:48h:100,late_cancellation:0s:50, and orders them by MinNotice descending.
+func parseCancellationPolicy(value string) ([]CancellationRule, error) {
+	policy := []CancellationRule{}
+	for _, item := range splitList(value) {
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("rule %q is not name:min_notice:refund_percent", item)
+		}
+
+		notice, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || notice < 0 {
+			return nil, fmt.Errorf("rule %q has an invalid min_notice", item)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 32)
+		if err != nil || percent < 0 || percent > 100 {
+			return nil, fmt.Errorf("rule %q has an invalid refund_percent", item)
+		}
+
+		policy = append(policy, CancellationRule{
+			Name:          strings.TrimSpace(parts[0]),
+			MinNotice:     notice,
+			RefundPercent: float32(percent),
+		})
+	}
+	if len(policy) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+
+	sort.SliceStable(policy, func(i, j int) bool {
+		return policy[i].MinNotice > policy[j].MinNotice
+	})
+	return policy, nil
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
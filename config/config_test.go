@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCancellationPolicy(t *testing.T) {
+	policy, err := parseCancellationPolicy("late:0s:50, free:48h:100")
+	if assert.NoError(t, err) {
+		// the longest notice is matched first
+		assert.Equal(t, []CancellationRule{
+			{Name: "free", MinNotice: 48 * time.Hour, RefundPercent: 100},
+			{Name: "late", MinNotice: 0, RefundPercent: 50},
+		}, policy)
+	}
+
+	for _, value := range []string{"", "free:48h", ":48h:100", "free:two days:100", "free:-1h:100", "free:48h:120"} {
+		_, err := parseCancellationPolicy(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLoadMinDriverAge(t *testing.T) {
+	tests := []struct {
+		value string
+		age   int
+	}{
+		{"", 21},
+		{"25", 25},
+		// a bad value never turns the age check off
+		{"twenty-one", 21},
+		{"0", 21},
+		{"-3", 21},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("MIN_DRIVER_AGE", tt.value)
+		assert.Equal(t, tt.age, Load().MinDriverAge, tt.value)
+	}
+}
+
+func TestLoadLoyaltyPointsExpiry(t *testing.T) {
+	tests := []struct {
+		value  string
+		expiry time.Duration
+	}{
+		{"", 365 * 24 * time.Hour},
+		{"720h", 720 * time.Hour},
+		// points never expire right away because of a bad value
+		{"365d", 365 * 24 * time.Hour},
+		{"30", 365 * 24 * time.Hour},
+		{"-1h", 365 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LOYALTY_POINTS_EXPIRY", tt.value)
+		assert.Equal(t, tt.expiry, Load().LoyaltyPointsExpiry, tt.value)
+	}
+}
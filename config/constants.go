@@ -11,16 +11,223 @@ const (
 	ERR_INTERNAL_SERVER = "While the request appears to be valid, the server could not complete the request"
 	CUSTOMER_ROLE       = "customer"
 	ADMIN_ROLE          = "admin"
+	MANAGER_ROLE        = "manager"
+	AGENT_ROLE          = "agent"
 	STATUS_NEW          = "new"
 	STATUS_IN_PROCESS   = "in-process"
 	STATUS_FINISHED     = "finished"
 	STATUS_CANCELED     = "canceled"
+	AUTH_INFO_KEY       = "auth_info"
+	ACCESS_TOKEN_TYPE   = "access"
+	REFRESH_TOKEN_TYPE  = "refresh"
+	LINE_ITEM_BASE      = "base"
+	LINE_ITEM_WEEKEND   = "weekend"
+	LINE_ITEM_SEASONAL  = "seasonal"
+	LINE_ITEM_DISCOUNT  = "discount"
+	EVENT_ORDER_CREATED = "order.created"
+	EVENT_ORDER_UPDATED = "order.updated"
+	EVENT_ORDER_STATUS  = "order.status_changed"
+	OUTBOX_PENDING      = "pending"
+	OUTBOX_SENT         = "sent"
+	OUTBOX_DEAD         = "dead"
+	FILTER_EQ           = "eq"
+	FILTER_NE           = "ne"
+	FILTER_GT           = "gt"
+	FILTER_GTE          = "gte"
+	FILTER_LT           = "lt"
+	FILTER_LTE          = "lte"
+	FILTER_LIKE         = "like"
+	FILTER_IN           = "in"
+	VERIFICATION_PENDING  = "pending"
+	VERIFICATION_APPROVED = "approved"
+	VERIFICATION_REJECTED = "rejected"
+	DOCUMENT_LICENCE_FRONT = "licence_front"
+	DOCUMENT_LICENCE_BACK  = "licence_back"
+	DOCUMENT_IDENTITY      = "identity"
+	BLOCK_ACTION_BLOCKED   = "blocked"
+	BLOCK_ACTION_UNBLOCKED = "unblocked"
+	PAYMENT_KIND_PAYMENT   = "payment"
+	PAYMENT_KIND_DEPOSIT   = "deposit"
+	PAYMENT_KIND_REFUND    = "refund"
+	PAYMENT_METHOD_CASH    = "cash"
+	PAYMENT_METHOD_CARD    = "card"
+	PAYMENT_METHOD_TRANSFER = "transfer"
+	EVENT_PAYMENT_RECORDED = "order.payment_recorded"
+	PAYMENT_PROVIDER_FAKE  = "fake"
+	PAYMENT_KIND_DEPOSIT_RELEASE = "deposit_release"
+	PAYMENT_KIND_DEPOSIT_CAPTURE = "deposit_capture"
+	PAYMENT_PENDING        = "pending"
+	PAYMENT_CONFIRMED      = "confirmed"
+	PAYMENT_FAILED         = "failed"
+	CAR_CATEGORY_ECONOMY   = "economy"
+	CAR_CATEGORY_STANDARD  = "standard"
+	CAR_CATEGORY_PREMIUM   = "premium"
+	CAR_CATEGORY_SUV       = "suv"
+	DEPOSIT_OPEN           = "open"
+	DEPOSIT_HELD           = "held"
+	DEPOSIT_RELEASED       = "released"
+	DEPOSIT_PARTIALLY_CAPTURED = "partially_captured"
+	DEPOSIT_CAPTURED       = "captured"
+	LINE_ITEM_DAMAGE       = "damage"
+	LINE_ITEM_LATE_FEE     = "late_fee"
+	EVENT_DEPOSIT_SETTLED  = "order.deposit_settled"
+	EVENT_ORDER_OVERDUE    = "order.overdue"
+	CANCELLATION_NO_REFUND = "no_refund"
+	EXTENSION_PENDING      = "pending"
+	EXTENSION_APPROVED     = "approved"
+	EXTENSION_REJECTED     = "rejected"
+	EVENT_ORDER_EXTENDED   = "order.extended"
+	PROMO_KIND_PERCENT     = "percent"
+	PROMO_KIND_FIXED       = "fixed"
+	LINE_ITEM_PROMO        = "promo"
+	LINE_ITEM_LOYALTY      = "loyalty"
+	LINE_ITEM_TIER         = "tier"
+	LOYALTY_EARN           = "earn"
+	LOYALTY_REDEEM         = "redeem"
+	LOYALTY_REFUND         = "refund"
+	LOYALTY_EXPIRE         = "expire"
+	TIER_MEMBER            = "member"
+	TIER_SILVER            = "silver"
+	TIER_GOLD              = "gold"
 )
 
 var SignedKey = []byte("MGJd@Ro]yKoCc)mVY1^c:upz~4rn9Pt!hYd]>c8dt#+%")
 
+var STAFF_ROLES = []string{
+	ADMIN_ROLE, MANAGER_ROLE, AGENT_ROLE,
+}
+
+var OUTBOX_STATUS = []string{
+	OUTBOX_PENDING, OUTBOX_SENT, OUTBOX_DEAD,
+}
+
+var VERIFICATION_STATUS = []string{
+	VERIFICATION_PENDING, VERIFICATION_APPROVED, VERIFICATION_REJECTED,
+}
+
+var DOCUMENT_KINDS = []string{
+	DOCUMENT_LICENCE_FRONT, DOCUMENT_LICENCE_BACK, DOCUMENT_IDENTITY,
+}
+
+var PAYMENT_KINDS = []string{
+	PAYMENT_KIND_PAYMENT, PAYMENT_KIND_DEPOSIT, PAYMENT_KIND_REFUND,
+}
+
+var PAYMENT_METHODS = []string{
+	PAYMENT_METHOD_CASH, PAYMENT_METHOD_CARD, PAYMENT_METHOD_TRANSFER,
+}
+
+var PROMO_KINDS = []string{
+	PROMO_KIND_PERCENT, PROMO_KIND_FIXED,
+}
+
+var CAR_CATEGORIES = []string{
+	CAR_CATEGORY_ECONOMY, CAR_CATEGORY_STANDARD, CAR_CATEGORY_PREMIUM, CAR_CATEGORY_SUV,
+}
+
+// CHARGE_KINDS are the line items staff may add to an order when it is returned
+var CHARGE_KINDS = []string{
+	LINE_ITEM_DAMAGE, LINE_ITEM_LATE_FEE,
+}
+
+// CATEGORY_DEPOSITS is the refundable deposit taken for a rental of a car of the category
+var CATEGORY_DEPOSITS = map[string]float32{
+	CAR_CATEGORY_ECONOMY:  100,
+	CAR_CATEGORY_STANDARD: 200,
+	CAR_CATEGORY_PREMIUM:  500,
+	CAR_CATEGORY_SUV:      400,
+}
+
 var ORDER_STATUS = []string{
 	"new", "in-process", "finished", "canceled",
 }
 
-const TimewithContex = 1*time.Second
\ No newline at end of file
+// LONG_RENTAL_DISCOUNTS is ordered by MinDays descending, the first matching tier is applied.
+var LONG_RENTAL_DISCOUNTS = []struct {
+	MinDays int
+	Percent float32
+}{
+	{MinDays: 30, Percent: 15},
+	{MinDays: 14, Percent: 10},
+	{MinDays: 7, Percent: 5},
+}
+
+// CancellationRule refunds RefundPercent of the paid amount of a new order canceled at least MinNotice
+// before the start of from_date.
+type CancellationRule struct {
+	Name          string
+	MinNotice     time.Duration
+	RefundPercent float32
+}
+
+// CANCELLATION_POLICY is the default of config.Config.CancellationPolicy, ordered by MinNotice descending,
+// a canceled new order is refunded by the first rule whose notice it reached. In-process orders and
+// cancellations no rule matches are not refunded.
+var CANCELLATION_POLICY = []CancellationRule{
+	{Name: "free_cancellation", MinNotice: 48*time.Hour, RefundPercent: 100},
+	{Name: "late_cancellation", MinNotice: 0, RefundPercent: 50},
+}
+
+const TimewithContex = 1*time.Second
+
+// MigrateTimeout bounds a whole migration run
+const MigrateTimeout = 5*time.Minute
+
+// NotifyTimeout bounds a single delivery to all notifiers
+const NotifyTimeout = 10*time.Second
+
+const (
+	// OutboxPollInterval is how often the dispatcher looks for pending events
+	OutboxPollInterval = 5*time.Second
+	// OutboxBatchSize is the number of events claimed by the dispatcher at once
+	OutboxBatchSize = 20
+	// OutboxLease keeps a claimed batch away from other dispatchers while it is delivered
+	OutboxLease = OutboxBatchSize*NotifyTimeout + time.Minute
+	// OutboxMaxAttempts is the number of failed deliveries after which an event is dead-lettered
+	OutboxMaxAttempts = 8
+	// OutboxBaseBackoff is doubled after every failed delivery up to OutboxMaxBackoff
+	OutboxBaseBackoff = 30*time.Second
+	OutboxMaxBackoff  = time.Hour
+)
+
+const (
+	// LateReturnCheckInterval is how often the late return job looks for overdue orders
+	LateReturnCheckInterval = 15*time.Minute
+	// LateReturnBatchSize is the number of overdue orders charged by one run of the job
+	LateReturnBatchSize = 100
+	// LateReturnGrace is how long after the start of to_date an in-process order may be returned without a fee,
+	// every started day after that is charged at the car's rate of the day times LateFeeMultiplier
+	LateReturnGrace = 12*time.Hour
+	LateFeeMultiplier = 1.5
+)
+
+// LOYALTY_TIERS is ordered by MinPoints descending, a customer is in the first tier whose MinPoints the points
+// earned in the last LoyaltyTierWindow reach and gets DiscountPercent off the rental price of new orders.
+// Customers below every tier are TIER_MEMBER.
+var LOYALTY_TIERS = []struct {
+	Name            string
+	MinPoints       int
+	DiscountPercent float32
+}{
+	{Name: TIER_GOLD, MinPoints: 2000, DiscountPercent: 10},
+	{Name: TIER_SILVER, MinPoints: 500, DiscountPercent: 5},
+}
+
+const (
+	// LoyaltyPointsPerUnit is the number of points a finished order earns for every unit of money paid for it
+	LoyaltyPointsPerUnit = 1
+	// LoyaltyPointValue is the money a point takes off the price of an order
+	LoyaltyPointValue = 0.05
+	// LoyaltyTierWindow is the period whose earned points decide the tier
+	LoyaltyTierWindow = 365*24*time.Hour
+	// LoyaltyExpiryCheckInterval is how often the loyalty job records the points that expired
+	LoyaltyExpiryCheckInterval = time.Hour
+	// LoyaltyExpiryBatchSize is the number of customers whose points one run of the job expires
+	LoyaltyExpiryBatchSize = 100
+)
+
+// PaymentTimeout bounds recording a payment including the call to the payment provider
+const PaymentTimeout = 30*time.Second
+
+// SoftDeleteRetention is how long soft deleted cars and customers are kept before they can be purged
+const SoftDeleteRetention = 30*24*time.Hour
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS orders;
+
+DROP TABLE IF EXISTS customers;
+
+DROP TABLE IF EXISTS cars;
@@ -6,6 +6,7 @@ CREATE TABLE IF NOT EXISTS cars (
     hourse_power INTEGER DEFAULT 0,
     colour VARCHAR(20) NOT NULL DEFAULT 'black',
     engine_cap DECIMAL(10,2) NOT NULL DEFAULT 1.0,
+    year INTEGER NOT NULL,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP,
     deleted_at INTEGER DEFAULT 0
@@ -17,14 +18,22 @@ CREATE TABLE IF NOT EXISTS customers (
     last_name VARCHAR(50),
     gmail VARCHAR(50) NOT NULL,
     phone VARCHAR(20) NOT NULL,
+    password VARCHAR(255) NOT NULL,
     is_blocked BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP,
     deleted_at INTEGER DEFAULT 0
 );
 
-CREATE UNIQUE INDEX index_phone
-ON Customers(phone,deleted_at);
+-- databases created before the migrations were numbered may lack these columns
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS year INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE cars ALTER COLUMN year DROP DEFAULT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS password VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE customers ALTER COLUMN password DROP DEFAULT;
+ALTER TABLE customers ALTER COLUMN password TYPE VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS index_phone
+ON customers(phone,deleted_at);
 
 CREATE TABLE IF NOT EXISTS orders(
     id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -38,9 +47,3 @@ CREATE TABLE IF NOT EXISTS orders(
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP
 );
-
-ALTER TABLE cars
-ADD year INTEGER NOT NULL;
-
-ALTER TABLE orders ADD FOREIGN KEY (customer_id) REFERENCES customers (id);
-ALTER TABLE orders ADD FOREIGN KEY (car_id) REFERENCES cars (id);
\ No newline at end of file
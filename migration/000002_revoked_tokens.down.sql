@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS revoked_token_families;
+
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti uuid PRIMARY KEY,
+    family_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    reason VARCHAR(50),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS revoked_tokens_family_idx ON revoked_tokens(family_id);
+
+CREATE TABLE IF NOT EXISTS revoked_token_families (
+    family_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    reason VARCHAR(50),
+    created_at TIMESTAMP DEFAULT NOW()
+);
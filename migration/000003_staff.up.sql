@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS staff (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    full_name VARCHAR(100) NOT NULL,
+    login VARCHAR(50) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK(role in('admin','manager','agent')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_car_no_overlap;
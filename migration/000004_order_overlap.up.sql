@@ -0,0 +1,9 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- a car can not have two not canceled orders with overlapping [from_date, to_date) ranges
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_car_no_overlap;
+
+ALTER TABLE orders
+ADD CONSTRAINT orders_car_no_overlap
+EXCLUDE USING gist (car_id WITH =, daterange(from_date, to_date, '[)') WITH &&)
+WHERE (status <> 'canceled');
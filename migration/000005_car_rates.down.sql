@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS order_line_items;
+
+DROP TABLE IF EXISTS car_seasonal_rates;
+
+ALTER TABLE cars DROP COLUMN IF EXISTS weekend_rate;
+ALTER TABLE cars DROP COLUMN IF EXISTS daily_rate;
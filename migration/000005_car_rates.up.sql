@@ -0,0 +1,26 @@
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS daily_rate DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS weekend_rate DECIMAL(10,2);
+
+CREATE TABLE IF NOT EXISTS car_seasonal_rates (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    car_id uuid NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    daily_rate DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (to_date > from_date)
+);
+
+CREATE TABLE IF NOT EXISTS order_line_items (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    description VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_price DECIMAL(10,2) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS order_line_items_order_idx ON order_line_items(order_id);
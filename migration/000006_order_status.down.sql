@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+
+UPDATE orders SET status = 'created' WHERE status = 'new';
+UPDATE orders SET status = 'in process' WHERE status = 'in-process';
+
+ALTER TABLE orders
+ADD CONSTRAINT orders_status_check CHECK(status in('created','in process','canceled'));
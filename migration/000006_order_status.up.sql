@@ -0,0 +1,20 @@
+-- align the status values with the application: new, in-process, finished, canceled
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+
+UPDATE orders SET status = 'new' WHERE status = 'created';
+UPDATE orders SET status = 'in-process' WHERE status = 'in process';
+
+ALTER TABLE orders
+ADD CONSTRAINT orders_status_check CHECK(status in('new','in-process','finished','canceled'));
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(15),
+    to_status VARCHAR(15) NOT NULL,
+    actor_id uuid,
+    actor_role VARCHAR(20),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_idx ON order_status_history(order_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS customers_deleted_at_idx;
+DROP INDEX IF EXISTS cars_deleted_at_idx;
+DROP INDEX IF EXISTS index_phone;
+
+ALTER TABLE customers ALTER COLUMN deleted_at TYPE INTEGER
+USING COALESCE(extract(epoch from deleted_at)::integer, 0);
+ALTER TABLE customers ALTER COLUMN deleted_at SET DEFAULT 0;
+
+ALTER TABLE cars ALTER COLUMN deleted_at TYPE INTEGER
+USING COALESCE(extract(epoch from deleted_at)::integer, 0);
+ALTER TABLE cars ALTER COLUMN deleted_at SET DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS index_phone ON customers(phone,deleted_at);
@@ -0,0 +1,16 @@
+-- deleted_at becomes the time of the soft delete, NULL while the record is active
+DROP INDEX IF EXISTS index_phone;
+
+ALTER TABLE cars ALTER COLUMN deleted_at DROP DEFAULT;
+ALTER TABLE cars ALTER COLUMN deleted_at TYPE TIMESTAMP
+USING CASE WHEN deleted_at IS NULL OR deleted_at = 0 THEN NULL ELSE to_timestamp(deleted_at)::timestamp END;
+
+ALTER TABLE customers ALTER COLUMN deleted_at DROP DEFAULT;
+ALTER TABLE customers ALTER COLUMN deleted_at TYPE TIMESTAMP
+USING CASE WHEN deleted_at IS NULL OR deleted_at = 0 THEN NULL ELSE to_timestamp(deleted_at)::timestamp END;
+
+-- a deleted customer does not keep the phone number from being registered again
+CREATE UNIQUE INDEX IF NOT EXISTS index_phone ON customers(phone) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS cars_deleted_at_idx ON cars(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS customers_deleted_at_idx ON customers(deleted_at) WHERE deleted_at IS NOT NULL;
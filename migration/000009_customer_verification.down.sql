@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS customer_documents;
+DROP TABLE IF EXISTS customer_verifications;
@@ -0,0 +1,26 @@
+-- the driver licence and identity a customer submits, one current record per customer
+CREATE TABLE IF NOT EXISTS customer_verifications (
+    customer_id uuid PRIMARY KEY REFERENCES customers(id),
+    licence_number VARCHAR(30) NOT NULL,
+    licence_country CHAR(2) NOT NULL,
+    licence_expiry DATE NOT NULL,
+    date_of_birth DATE NOT NULL,
+    status VARCHAR(15) NOT NULL DEFAULT 'pending' CHECK(status in('pending','approved','rejected')),
+    reason TEXT,
+    reviewed_by uuid REFERENCES staff(id),
+    reviewed_at TIMESTAMP,
+    submitted_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_verifications_status_idx ON customer_verifications(status, submitted_at);
+
+-- scans are uploaded elsewhere, only their location is kept
+CREATE TABLE IF NOT EXISTS customer_documents (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id uuid NOT NULL REFERENCES customer_verifications(customer_id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_documents_customer_idx ON customer_documents(customer_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS revoked_user_tokens;
+DROP TABLE IF EXISTS customer_block_events;
+
+ALTER TABLE customers DROP COLUMN IF EXISTS blocked_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS blocked_until;
+ALTER TABLE customers DROP COLUMN IF EXISTS blocked_reason;
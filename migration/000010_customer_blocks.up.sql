@@ -0,0 +1,25 @@
+-- a block may end on its own at blocked_until, NULL blocks until lifted
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS blocked_reason TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS blocked_until TIMESTAMP;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMP;
+
+-- audit trail of every block and unblock
+CREATE TABLE IF NOT EXISTS customer_block_events (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id uuid NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    action VARCHAR(10) NOT NULL CHECK(action in('blocked','unblocked')),
+    reason TEXT NOT NULL,
+    blocked_until TIMESTAMP,
+    actor_id uuid,
+    actor_role VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS customer_block_events_customer_idx ON customer_block_events(customer_id, created_at);
+
+-- every token of the user issued up to revoked_at is revoked
+CREATE TABLE IF NOT EXISTS revoked_user_tokens (
+    user_id uuid PRIMARY KEY,
+    reason VARCHAR(50),
+    revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
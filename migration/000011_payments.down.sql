@@ -0,0 +1,3 @@
+ALTER TABLE orders ALTER COLUMN paid SET DEFAULT true;
+
+DROP TABLE IF EXISTS payments;
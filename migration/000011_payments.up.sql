@@ -0,0 +1,24 @@
+-- every money movement of an order, orders.paid is derived from this ledger
+CREATE TABLE IF NOT EXISTS payments (
+    id uuid PRIMARY KEY,
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK(kind in('payment','deposit','refund')),
+    method VARCHAR(10) NOT NULL CHECK(method in('cash','card','transfer')),
+    amount DECIMAL(10,2) NOT NULL CHECK(amount > 0),
+    provider_ref TEXT,
+    note TEXT,
+    actor_id uuid,
+    actor_role VARCHAR(20),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS payments_order_idx ON payments(order_id, created_at);
+
+-- orders marked as paid before the ledger existed keep their state through one payment of the full amount
+INSERT INTO payments(id, order_id, kind, method, amount, note)
+SELECT gen_random_uuid(), id, 'payment', 'transfer', amount, 'recorded before the payments ledger'
+FROM orders
+WHERE paid AND amount > 0;
+
+ALTER TABLE orders ALTER COLUMN paid SET DEFAULT false;
+UPDATE orders SET paid = false WHERE paid IS NULL OR amount IS NULL OR amount <= 0;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS order_deposits;
+
+DELETE FROM payments WHERE kind in('deposit_release','deposit_capture');
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_kind_check;
+ALTER TABLE payments ALTER COLUMN kind TYPE VARCHAR(10);
+ALTER TABLE payments
+ADD CONSTRAINT payments_kind_check CHECK(kind in('payment','deposit','refund'));
+
+ALTER TABLE cars DROP COLUMN IF EXISTS category;
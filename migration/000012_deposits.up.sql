@@ -0,0 +1,27 @@
+-- the deposit of an order is set by the category of the car
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS category VARCHAR(20) NOT NULL DEFAULT 'standard'
+    CHECK(category in('economy','standard','premium','suv'));
+
+-- released and captured deposit money is part of the ledger
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_kind_check;
+ALTER TABLE payments ALTER COLUMN kind TYPE VARCHAR(20);
+ALTER TABLE payments
+ADD CONSTRAINT payments_kind_check CHECK(kind in('payment','deposit','refund','deposit_release','deposit_capture'));
+
+CREATE TABLE IF NOT EXISTS order_deposits (
+    order_id uuid PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK(amount >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK(status in('open','released','partially_captured','captured')),
+    captured DECIMAL(10,2) NOT NULL DEFAULT 0,
+    released DECIMAL(10,2) NOT NULL DEFAULT 0,
+    settled_by uuid,
+    settled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS order_deposits_status_idx ON order_deposits(status);
+
+-- orders placed before deposits existed did not require one
+INSERT INTO order_deposits(order_id, amount)
+SELECT id, 0 FROM orders
+ON CONFLICT (order_id) DO NOTHING;
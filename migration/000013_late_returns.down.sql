@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_in_process_to_date_idx;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS actual_return_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS late_days;
+ALTER TABLE orders DROP COLUMN IF EXISTS overdue_since;
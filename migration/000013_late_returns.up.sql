@@ -0,0 +1,6 @@
+-- late returns: the job flags in-process orders past their to_date and charges every late day once
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS overdue_since TIMESTAMP;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS late_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS actual_return_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS orders_in_process_to_date_idx ON orders(to_date) WHERE status = 'in-process';
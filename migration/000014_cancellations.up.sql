@@ -0,0 +1,11 @@
+-- the cancellation policy applied to a canceled order and the refund it gave
+CREATE TABLE IF NOT EXISTS order_cancellations (
+    order_id uuid PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    policy VARCHAR(30) NOT NULL,
+    refund_percent DECIMAL(5,2) NOT NULL CHECK(refund_percent between 0 and 100),
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    reason TEXT,
+    actor_id uuid,
+    actor_role VARCHAR(20),
+    created_at TIMESTAMP DEFAULT NOW()
+);
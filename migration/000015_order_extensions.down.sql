@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_extension_items;
+DROP TABLE IF EXISTS order_extensions;
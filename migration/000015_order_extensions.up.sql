@@ -0,0 +1,37 @@
+-- extensions of a rental are amendments kept apart from the original booking, the order only
+-- takes the new to_date and the extra days once the extension is approved
+CREATE TABLE IF NOT EXISTS order_extensions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    previous_to_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    days INTEGER NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK(status in('pending','approved','rejected')),
+    note TEXT,
+    requested_by uuid,
+    requested_role VARCHAR(20),
+    reviewed_by uuid,
+    review_reason TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (to_date > previous_to_date)
+);
+
+CREATE INDEX IF NOT EXISTS order_extensions_order_idx ON order_extensions(order_id);
+
+-- an order waits for one extension at a time
+CREATE UNIQUE INDEX IF NOT EXISTS order_extensions_one_pending ON order_extensions(order_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS order_extension_items (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    extension_id uuid NOT NULL REFERENCES order_extensions(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    description VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_price DECIMAL(10,2) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS order_extension_items_extension_idx ON order_extension_items(extension_id);
@@ -0,0 +1,34 @@
+-- promo codes of the discount campaigns, a code may be redeemed while today is in [valid_from, valid_to)
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(30) NOT NULL,
+    description VARCHAR(200),
+    kind VARCHAR(10) NOT NULL CHECK(kind in('percent','fixed')),
+    value DECIMAL(10,2) NOT NULL CHECK(value > 0),
+    valid_from DATE NOT NULL,
+    valid_to DATE NOT NULL,
+    max_uses INTEGER NOT NULL DEFAULT 0 CHECK(max_uses >= 0),
+    max_uses_per_customer INTEGER NOT NULL DEFAULT 0 CHECK(max_uses_per_customer >= 0),
+    min_days INTEGER NOT NULL DEFAULT 0 CHECK(min_days >= 0),
+    brands TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP,
+    CHECK (valid_to > valid_from),
+    CHECK (kind <> 'percent' OR value <= 100)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS promo_codes_code_key ON promo_codes(upper(code));
+
+-- one redemption per order, the orders of a customer count against max_uses_per_customer
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    order_id uuid PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    promo_code_id uuid NOT NULL REFERENCES promo_codes(id),
+    customer_id uuid NOT NULL,
+    discount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS promo_redemptions_code_customer_idx ON promo_redemptions(promo_code_id, customer_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount DECIMAL(10,2) NOT NULL DEFAULT 0;
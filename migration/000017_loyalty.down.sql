@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS loyalty_points;
+DROP FUNCTION IF EXISTS loyalty_points_immutable();
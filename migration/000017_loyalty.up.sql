@@ -0,0 +1,28 @@
+-- the loyalty points ledger, entries are never changed or removed: credits (earn, refund) are positive
+-- and expire, debits (redeem, expire) are negative and use up the oldest credits first
+CREATE TABLE IF NOT EXISTS loyalty_points (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id uuid NOT NULL,
+    order_id uuid,
+    kind VARCHAR(10) NOT NULL CHECK(kind in('earn','redeem','refund','expire')),
+    points INTEGER NOT NULL CHECK(points <> 0),
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((kind in('earn','refund')) = (points > 0)),
+    CHECK ((kind in('earn','refund')) = (expires_at IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS loyalty_points_customer_idx ON loyalty_points(customer_id, created_at);
+
+-- an order earns, redeems and gets its points back at most once
+CREATE UNIQUE INDEX IF NOT EXISTS loyalty_points_order_kind_key ON loyalty_points(order_id, kind) WHERE order_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION loyalty_points_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'loyalty_points entries can not be changed or removed';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS loyalty_points_immutable ON loyalty_points;
+CREATE TRIGGER loyalty_points_immutable BEFORE UPDATE OR DELETE ON loyalty_points
+    FOR EACH ROW EXECUTE FUNCTION loyalty_points_immutable();
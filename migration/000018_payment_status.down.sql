@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS payments_pending_idx;
+
+DELETE FROM payments WHERE status <> 'confirmed';
+ALTER TABLE payments DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+-- card money moves at the provider between writing a ledger row and confirming it, a pending row
+-- reserves its amount while the provider is called and a declined one is kept as failed
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'confirmed'
+    CHECK(status in('pending','confirmed','failed'));
+
+CREATE INDEX IF NOT EXISTS payments_pending_idx ON payments(created_at) WHERE status = 'pending';
@@ -0,0 +1,11 @@
+ALTER TABLE promo_redemptions DROP CONSTRAINT IF EXISTS promo_redemptions_order_id_fkey;
+ALTER TABLE promo_redemptions
+ADD CONSTRAINT promo_redemptions_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+ALTER TABLE order_deposits DROP CONSTRAINT IF EXISTS order_deposits_order_id_fkey;
+ALTER TABLE order_deposits
+ADD CONSTRAINT order_deposits_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_order_id_fkey;
+ALTER TABLE payments
+ADD CONSTRAINT payments_order_id_fkey FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- channels that took an event already, a retry only goes to the channels that failed
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS delivered_channels TEXT[] NOT NULL DEFAULT '{}';
@@ -1,5 +0,0 @@
-Drop table cars;
-
-Drop table customers;
-
-Drop table orders;
\ No newline at end of file
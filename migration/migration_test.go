@@ -0,0 +1,56 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, migrations) {
+		return
+	}
+
+	// versions are continuous so goto can target every step
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version)
+		assert.NotEmpty(t, m.Up)
+		assert.NotEmpty(t, m.Down)
+	}
+	assert.Equal(t, "init", migrations[0].Name)
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"000010_b.up.sql":   {Data: []byte("select 10")},
+		"000010_b.down.sql": {Data: []byte("select -10")},
+		"000002_a.up.sql":   {Data: []byte("select 2")},
+		"000002_a.down.sql": {Data: []byte("select -2")},
+		"README.md":         {Data: []byte("ignored")},
+	})
+	if assert.NoError(t, err) && assert.Len(t, migrations, 2) {
+		assert.Equal(t, Migration{Version: 2, Name: "a", Up: "select 2", Down: "select -2"}, migrations[0])
+		assert.Equal(t, 10, migrations[1].Version)
+	}
+}
+
+func TestLoadRejectsBrokenSets(t *testing.T) {
+	_, err := load(fstest.MapFS{
+		"000001_a.up.sql": {Data: []byte("select 1")},
+	})
+	assert.Error(t, err)
+
+	_, err = load(fstest.MapFS{
+		"car.up.sql":   {Data: []byte("select 1")},
+		"car.down.sql": {Data: []byte("select 1")},
+	})
+	assert.Error(t, err)
+
+	_, err = load(fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("select 1")},
+		"000001_b.down.sql": {Data: []byte("select 1")},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Za-z0-9]{3,30}$`)
+
+// rules are the custom binding tags of the request models in api/models.
+// Cross-field tags take the json name of the other field, e.g. after=from_date.
+var rules = map[string]validator.Func{
+	"car_year": func(fl validator.FieldLevel) bool {
+		return ValidateCarYear(int(fl.Field().Int())) == nil
+	},
+	"password": func(fl validator.FieldLevel) bool {
+		return ValidatePassword(fl.Field().String()) == nil
+	},
+	"gmail": func(fl validator.FieldLevel) bool {
+		return ValidateGmailCustomer(fl.Field().String())
+	},
+	"phone": func(fl validator.FieldLevel) bool {
+		return ValidatePhoneNumberOfCustomer(fl.Field().String())
+	},
+	"order_status": func(fl validator.FieldLevel) bool {
+		return ValidatingOrderStatusForAuth(fl.Field().String()) == nil
+	},
+	"staff_role": func(fl validator.FieldLevel) bool {
+		return IsStaffRole(fl.Field().String())
+	},
+	"outbox_status": func(fl validator.FieldLevel) bool {
+		return ValidateOutboxStatus(fl.Field().String()) == nil
+	},
+	"verification_status": func(fl validator.FieldLevel) bool {
+		return oneOf(config.VERIFICATION_STATUS, fl.Field().String())
+	},
+	"document_kind": func(fl validator.FieldLevel) bool {
+		return oneOf(config.DOCUMENT_KINDS, fl.Field().String())
+	},
+	"payment_kind": func(fl validator.FieldLevel) bool {
+		return oneOf(config.PAYMENT_KINDS, fl.Field().String())
+	},
+	"payment_method": func(fl validator.FieldLevel) bool {
+		return oneOf(config.PAYMENT_METHODS, fl.Field().String())
+	},
+	"car_category": func(fl validator.FieldLevel) bool {
+		return oneOf(config.CAR_CATEGORIES, fl.Field().String())
+	},
+	"charge_kind": func(fl validator.FieldLevel) bool {
+		return oneOf(config.CHARGE_KINDS, fl.Field().String())
+	},
+	"promo_kind": func(fl validator.FieldLevel) bool {
+		return oneOf(config.PROMO_KINDS, fl.Field().String())
+	},
+	"promo_code": func(fl validator.FieldLevel) bool {
+		return promoCodePattern.MatchString(fl.Field().String())
+	},
+	"date": func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(time.DateOnly, fl.Field().String())
+		return err == nil
+	},
+	// not_past accepts today and later dates
+	"not_past": func(fl validator.FieldLevel) bool {
+		date, err := time.Parse(time.DateOnly, fl.Field().String())
+		if err != nil {
+			// reported by the date tag
+			return true
+		}
+		return !date.Before(today())
+	},
+	"before_today": func(fl validator.FieldLevel) bool {
+		date, err := time.Parse(time.DateOnly, fl.Field().String())
+		if err != nil {
+			return true
+		}
+		return date.Before(today())
+	},
+	// future_time accepts an RFC 3339 time after now
+	"future_time": func(fl validator.FieldLevel) bool {
+		value, err := time.Parse(time.RFC3339, fl.Field().String())
+		return err == nil && value.After(time.Now())
+	},
+	// past_time accepts an RFC 3339 time up to now
+	"past_time": func(fl validator.FieldLevel) bool {
+		value, err := time.Parse(time.RFC3339, fl.Field().String())
+		return err == nil && !value.After(time.Now())
+	},
+	"after": func(fl validator.FieldLevel) bool {
+		other, ok := siblingField(fl.Parent(), fl.Param())
+		if !ok {
+			panic(fmt.Sprintf("check: after=%s names an unknown field", fl.Param()))
+		}
+		date, err := time.Parse(time.DateOnly, fl.Field().String())
+		if err != nil {
+			return true
+		}
+		start, err := time.Parse(time.DateOnly, other.String())
+		if err != nil {
+			// the other field reports its own format error
+			return true
+		}
+		return date.After(start)
+	},
+	"differs": func(fl validator.FieldLevel) bool {
+		other, ok := siblingField(fl.Parent(), fl.Param())
+		if !ok {
+			panic(fmt.Sprintf("check: differs=%s names an unknown field", fl.Param()))
+		}
+		return fl.Field().String() != other.String()
+	},
+}
+
+// messages describe a failed tag, %s is replaced with the tag parameter.
+var messages = map[string]string{
+	"required":            "is required",
+	"uuid":                "must be a valid uuid",
+	"gt":                  "must be greater than %s",
+	"gte":                 "must be at least %s",
+	"max":                 "must be at most %s long",
+	"min":                 "must have at least %s items",
+	"oneof":               "must be one of: %s",
+	"url":                 "must be a valid url",
+	"required_if":         "is required",
+	"iso3166_1_alpha2":    "must be a two letter ISO 3166 country code",
+	"car_year":            "is not a valid year",
+	"password":            "must be at least 8 characters with a lower and an upper case letter, a digit and a symbol",
+	"gmail":               "is not a valid email",
+	"phone":               "is not a valid phone number",
+	"order_status":        "is not a valid order status",
+	"staff_role":          "is not a valid staff role",
+	"outbox_status":       "is not a valid outbox status",
+	"verification_status": "is not a valid verification status",
+	"document_kind":       "is not a valid document kind",
+	"payment_kind":        "is not a valid payment kind",
+	"payment_method":      "is not a valid payment method",
+	"car_category":        "is not a valid car category",
+	"charge_kind":         "is not a valid charge kind",
+	"promo_kind":          "is not a valid promo code kind",
+	"promo_code":          "must be 3 to 30 letters or digits",
+	"before_today":        "must be in the past",
+	"date":                "must be a date in YYYY-MM-DD format",
+	"not_past":            "can not be in the past",
+	"future_time":         "must be a future time in RFC 3339 format",
+	"past_time":           "must be a past time in RFC 3339 format",
+	"after":               "must be after %s",
+	"differs":             "must differ from %s",
+}
+
+// RegisterRules adds the custom tags to v and reports fields by their json or form name.
+func RegisterRules(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FieldErrors turns the errors of a failed struct validation into one error per field.
+// ok is false when err did not come from the validator.
+func FieldErrors(err error) ([]errs.FieldError, bool) {
+	var failed validator.ValidationErrors
+	if !errors.As(err, &failed) {
+		return nil, false
+	}
+
+	fields := make([]errs.FieldError, 0, len(failed))
+	for _, fe := range failed {
+		message, ok := messages[fe.Tag()]
+		if !ok {
+			message = "failed the " + fe.Tag() + " rule"
+		}
+		if strings.Contains(message, "%s") {
+			message = fmt.Sprintf(message, fe.Param())
+		}
+		// nested fields keep their path, e.g. documents[0].url
+		field := fe.Field()
+		if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+			field = path
+		}
+		fields = append(fields, errs.FieldError{Field: field, Message: message})
+	}
+	return fields, true
+}
+
+// fieldName is the name a client knows a struct field by.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func siblingField(parent reflect.Value, name string) (reflect.Value, bool) {
+	for parent.Kind() == reflect.Pointer {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	for i := 0; i < parent.NumField(); i++ {
+		if fieldName(parent.Type().Field(i)) == name {
+			return parent.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func oneOf(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
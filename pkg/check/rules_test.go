@@ -0,0 +1,147 @@
+package check
+
+import (
+	"rent-car/api/models"
+	"rent-car/pkg/errs"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidator(t *testing.T) *validator.Validate {
+	// gin reads the rules from the binding tag
+	v := validator.New()
+	v.SetTagName("binding")
+	if err := RegisterRules(v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestRulesReportEveryField(t *testing.T) {
+	v := newValidator(t)
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	order := models.CreateOrder{
+		CarId:    "not-a-uuid",
+		FromDate: yesterday,
+		ToDate:   time.Now().AddDate(0, 0, -2).Format(time.DateOnly),
+	}
+
+	fields, ok := FieldErrors(v.Struct(order))
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.ElementsMatch(t, []string{"car_id", "from_date", "to_date"}, fieldNamesOf(fields))
+	for _, field := range fields {
+		switch field.Field {
+		case "from_date":
+			assert.Equal(t, "can not be in the past", field.Message)
+		case "to_date":
+			assert.Equal(t, "must be after from_date", field.Message)
+		}
+	}
+}
+
+func TestRulesAcceptValidOrder(t *testing.T) {
+	v := newValidator(t)
+
+	order := models.CreateOrder{
+		CarId:    "4b4e3d4c-7b61-4b8e-9f6a-1f4c3b2a1d0e",
+		FromDate: time.Now().Format(time.DateOnly),
+		ToDate:   time.Now().AddDate(0, 0, 3).Format(time.DateOnly),
+	}
+	assert.NoError(t, v.Struct(order))
+}
+
+func TestUpdateOrderRules(t *testing.T) {
+	v := newValidator(t)
+
+	// an update can not move the booking into the past either
+	order := models.UpdateOrder{
+		FromDate: time.Now().AddDate(0, 0, -1).Format(time.DateOnly),
+		ToDate:   time.Now().AddDate(0, 0, 3).Format(time.DateOnly),
+	}
+	fields, ok := FieldErrors(v.Struct(order))
+	if assert.True(t, ok) && assert.Len(t, fields, 1) {
+		assert.Equal(t, "from_date", fields[0].Field)
+		assert.Equal(t, "can not be in the past", fields[0].Message)
+	}
+
+	order.FromDate = time.Now().Format(time.DateOnly)
+	assert.NoError(t, v.Struct(order))
+}
+
+func TestDateRules(t *testing.T) {
+	v := newValidator(t)
+
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		fields []string
+	}{
+		{"valid", "2030-01-01", "2030-01-05", nil},
+		{"same day", "2030-01-01", "2030-01-01", []string{"to"}},
+		{"impossible date", "2030-02-30", "2030-03-01", []string{"from"}},
+		{"missing", "", "", []string{"from", "to"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(models.DateRange{From: tt.from, To: tt.to})
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			fields, _ := FieldErrors(err)
+			assert.ElementsMatch(t, tt.fields, fieldNamesOf(fields))
+		})
+	}
+}
+
+func TestDiffersRule(t *testing.T) {
+	v := newValidator(t)
+
+	fields, _ := FieldErrors(v.Struct(models.PasswordOfCustomer{
+		Phone:       "+998901234567",
+		Password:    "Secret#123",
+		NewPassword: "Secret#123",
+	}))
+	if assert.Len(t, fields, 1) {
+		assert.Equal(t, "new_password", fields[0].Field)
+		assert.Equal(t, "must differ from password", fields[0].Message)
+	}
+}
+
+func fieldNamesOf(fields []errs.FieldError) []string {
+	names := []string{}
+	for _, field := range fields {
+		names = append(names, field.Field)
+	}
+	return names
+}
+
+func TestPromoCodeRules(t *testing.T) {
+	v := newValidator(t)
+
+	promo := models.CreatePromoCode{
+		Code:      "SUMMER30",
+		Kind:      "percent",
+		Value:     30,
+		ValidFrom: "2030-06-01",
+		ValidTo:   "2030-09-01",
+		Brands:    []string{"BMW"},
+	}
+	assert.NoError(t, v.Struct(promo))
+
+	promo.Code = "summer 30%"
+	promo.Kind = "bogof"
+	promo.ValidTo = "2030-05-01"
+	fields, ok := FieldErrors(v.Struct(promo))
+	if assert.True(t, ok) {
+		assert.ElementsMatch(t, []string{"code", "kind", "valid_to"}, fieldNamesOf(fields))
+	}
+}
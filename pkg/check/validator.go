@@ -1,15 +1,20 @@
 package check
 
 import (
-	"errors"
 	"regexp"
 	"rent-car/config"
+	"rent-car/pkg/errs"
 	"time"
 )
 
+// invalid is the validation error of a single field.
+func invalid(field, message string) error {
+	return errs.Validation("invalid_"+field, message, errs.FieldError{Field: field, Message: message})
+}
+
 func ValidateCarYear(year int) error {
 	if year <= 0 || year > time.Now().Year()+1 {
-		return errors.New("year is not valid")
+		return invalid("year", "year is not valid")
 	}
 	return nil
 }
@@ -42,7 +47,7 @@ func ValidatePassword(password string) error {
     return nil
   }
 
-  return errors.New("password does not meet the criteria")
+  return invalid("password", "password does not meet the criteria")
   }
 
 
@@ -53,15 +58,31 @@ func ValidatePassword(password string) error {
 			}
 			
 		}
-		return errors.New("error Valid order status")
+		return invalid("status", "error Valid order status")
 	}
 
 
-  func ValidateDateOfFormatForOrder(dateStr string) error {
-    datePattern := `^\d{4}-\d{2}-\d{2}$`
-    dateRegex := regexp.MustCompile(datePattern)
-    if !dateRegex.MatchString(dateStr) {
-      return errors.New("invalid date format")
+  func IsStaffRole(role string) bool {
+    for _, r := range config.STAFF_ROLES {
+      if r == role {
+        return true
+      }
+    }
+    return false
+  }
+
+  func ValidateStaffRole(role string) error {
+    if !IsStaffRole(role) {
+      return invalid("role", "invalid staff role")
     }
     return nil
-  }
\ No newline at end of file
+  }
+
+  func ValidateOutboxStatus(status string) error {
+    for _, s := range config.OUTBOX_STATUS {
+      if s == status {
+        return nil
+      }
+    }
+    return invalid("status", "invalid outbox status")
+  }
@@ -0,0 +1,112 @@
+// Package errs defines the domain errors shared by the storage, service and handler layers.
+// Storage and services return them, the handler maps the kind to a status code once.
+package errs
+
+import "errors"
+
+// Kind groups errors that are reported to clients the same way.
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindValidation   Kind = "validation_failed"
+	KindForbidden    Kind = "forbidden"
+	KindUnauthorized Kind = "unauthorized"
+	KindInternal     Kind = "internal"
+)
+
+// FieldError is a problem with a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error. Code is a stable machine readable code, e.g. car_not_found,
+// clients may switch on it, Message is meant for humans and may change.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	// Err is the underlying cause, it is logged but never sent to clients
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so a wrapped copy of a sentinel error
+// still matches the sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithFields returns a copy of e with the field details appended.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	wrapped := *e
+	wrapped.Fields = append(append([]FieldError{}, e.Fields...), fields...)
+	return &wrapped
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Validation is a request that can not be processed as sent, fields tell what to fix.
+func Validation(code, message string, fields ...FieldError) *Error {
+	e := New(KindValidation, code, message)
+	e.Fields = fields
+	return e
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// InvalidField is a validation error of a single field.
+func InvalidField(field string, err error) *Error {
+	return Validation("invalid_"+field, err.Error(), FieldError{Field: field, Message: err.Error()})
+}
+
+// From returns the first domain error in the chain of err, any other error is internal.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return New(KindInternal, "internal_error", "internal server error").Wrap(err)
+}
+
+// KindOf is the kind of the first domain error in the chain of err.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	return From(err).Kind
+}
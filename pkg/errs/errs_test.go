@@ -0,0 +1,43 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrappedSentinelStillMatches(t *testing.T) {
+	sentinel := NotFound("car_not_found", "car not found")
+	cause := errors.New("no rows in result set")
+
+	err := fmt.Errorf("getting car: %w", sentinel.Wrap(cause))
+
+	assert.ErrorIs(t, err, sentinel)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, NotFound("order_not_found", "order not found"))
+	assert.Equal(t, KindNotFound, KindOf(err))
+
+	// wrapping returns a copy, the sentinel keeps no cause
+	assert.Nil(t, sentinel.Err)
+}
+
+func TestFromPlainErrorIsInternal(t *testing.T) {
+	e := From(errors.New("connection refused"))
+
+	assert.Equal(t, KindInternal, e.Kind)
+	assert.Equal(t, "internal_error", e.Code)
+	assert.NotContains(t, e.Error(), "connection refused")
+	assert.Equal(t, Kind(""), KindOf(nil))
+}
+
+func TestWithFieldsDoesNotShareFields(t *testing.T) {
+	base := Validation("invalid_car", "car is invalid", FieldError{Field: "year", Message: "is not valid"})
+
+	extended := base.WithFields(FieldError{Field: "daily_rate", Message: "must be positive"})
+
+	assert.Len(t, base.Fields, 1)
+	assert.Len(t, extended.Fields, 2)
+	assert.ErrorIs(t, extended, base)
+}
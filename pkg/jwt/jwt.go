@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 )
 
 func GenJWT(m map[interface{}]interface{}) (string, string, error) {
@@ -28,10 +29,14 @@ func GenJWT(m map[interface{}]interface{}) (string, string, error) {
 	claims["iss"] = "user"
 	claims["iat"] = time.Now().Unix()
 	claims["exp"] = time.Now().AddDate(0, 0, 1).Unix()
+	claims["jti"] = uuid.NewString()
+	claims["token_type"] = config.ACCESS_TOKEN_TYPE
 
 	rClaims["iss"] = "user"
 	rClaims["iat"] = time.Now().Unix()
 	rClaims["exp"] = time.Now().AddDate(0, 0, 10).Unix()
+	rClaims["jti"] = uuid.NewString()
+	rClaims["token_type"] = config.REFRESH_TOKEN_TYPE
 
 	accessTokenString, err := accessToken.SignedString(config.SignedKey)
 	if err != nil {
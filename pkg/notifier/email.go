@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"rent-car/api/models"
+	"strconv"
+	"strings"
+)
+
+type email struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+func NewEmail(host string, port int, user, password, from string, to []string) Notifier {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return email{
+		addr: host + ":" + strconv.Itoa(port),
+		auth: auth,
+		from: from,
+		to:   to,
+	}
+}
+
+func (e email) Notify(ctx context.Context, event models.OrderEvent) error {
+	msg := "From: " + e.from + "\r\n" +
+		"To: " + strings.Join(e.to, ", ") + "\r\n" +
+		"Subject: Rent car: " + event.Type + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		formatText(event) + "\r\n"
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(e.addr, e.auth, e.from, e.to, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("email: %w", ctx.Err())
+	}
+}
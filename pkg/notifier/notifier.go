@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"rent-car/api/models"
+	"rent-car/config"
+	"slices"
+)
+
+// Notifier delivers order events to an external channel.
+type Notifier interface {
+	Notify(ctx context.Context, event models.OrderEvent) error
+}
+
+// channel names, kept with an outbox event once the channel took it
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+)
+
+// New builds a channel for every notifier configured in cfg, channels without settings are skipped.
+func New(cfg config.Config) Channels {
+	channels := Channels{}
+
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		channels = append(channels, Channel{Name: ChannelTelegram, Notifier: NewTelegram(cfg.TelegramBotToken, cfg.TelegramChatID)})
+	}
+
+	if cfg.SMTPHost != "" && len(cfg.NotifyEmails) > 0 {
+		channels = append(channels, Channel{Name: ChannelEmail, Notifier: NewEmail(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom, cfg.NotifyEmails)})
+	}
+
+	if cfg.WebhookURL != "" {
+		channels = append(channels, Channel{Name: ChannelWebhook, Notifier: NewWebhook(cfg.WebhookURL, cfg.WebhookSecret)})
+	}
+
+	return channels
+}
+
+// Channel is a notifier the delivery of an event is tracked for by Name.
+type Channel struct {
+	Name     string
+	Notifier Notifier
+}
+
+// Channels fans an event out to every channel once.
+type Channels []Channel
+
+// Notify delivers the event to the channels that are not in delivered yet and returns delivered with the
+// channels that took it now, the errors of the others are joined so a retry only goes to them.
+func (c Channels) Notify(ctx context.Context, event models.OrderEvent, delivered []string) ([]string, error) {
+	delivered = slices.Clip(delivered)
+
+	var errs []error
+	for _, channel := range c {
+		if slices.Contains(delivered, channel.Name) {
+			continue
+		}
+		if err := channel.Notifier.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name, err))
+			continue
+		}
+		delivered = append(delivered, channel.Name)
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// hideURL drops the request URL from an http client error, the URL can hold a secret such as the
+// telegram bot token and errors end up in the outbox and the logs.
+func hideURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s request: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+func formatText(event models.OrderEvent) string {
+	paid := "not paid"
+	if event.Order.Paid {
+		paid = "paid"
+	}
+
+	late := ""
+	if event.Order.LateDays > 0 {
+		late = fmt.Sprintf(", %d days late", event.Order.LateDays)
+	}
+
+	return fmt.Sprintf("%s\norder: %s\ncar: %s\nclient: %s, %s\ndates: %s - %s\nstatus: %s, %s%s",
+		event.Type,
+		event.OrderId,
+		event.Order.CarName,
+		event.Order.ClientName,
+		event.Order.Phone,
+		event.Order.FromDate,
+		event.Order.ToDate,
+		event.Order.Status,
+		paid,
+		late,
+	)
+}
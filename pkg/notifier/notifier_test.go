@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failing struct{ calls *int }
+
+func (f failing) Notify(ctx context.Context, event models.OrderEvent) error {
+	*f.calls++
+	return errors.New("channel down")
+}
+
+func TestWebhookSignsBody(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Signature")
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL, "secret").Notify(context.Background(), models.OrderEvent{
+		Type:    config.EVENT_ORDER_STATUS,
+		OrderId: "order-id",
+	})
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+func TestWebhookRejectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL, "").Notify(context.Background(), models.OrderEvent{})
+	assert.Error(t, err)
+}
+
+func TestNotifierErrorsHideURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	// the webhook URL can carry a secret, a network failure must not repeat it
+	err := NewWebhook(server.URL+"/hook?token=s3cret", "").Notify(context.Background(), models.OrderEvent{})
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "s3cret")
+	}
+
+	err = NewTelegram("123:bot-token", "chat").Notify(canceled(), models.OrderEvent{})
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "bot-token")
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func canceled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+type counting struct{ calls *int }
+
+func (c counting) Notify(ctx context.Context, event models.OrderEvent) error {
+	*c.calls++
+	return nil
+}
+
+func TestChannelsNotifyEveryChannel(t *testing.T) {
+	calls := 0
+	delivered, err := Channels{
+		{Name: ChannelTelegram, Notifier: failing{&calls}},
+		{Name: ChannelWebhook, Notifier: failing{&calls}},
+	}.Notify(context.Background(), models.OrderEvent{}, nil)
+	assert.Error(t, err)
+	assert.Empty(t, delivered)
+	assert.Equal(t, 2, calls)
+}
+
+func TestChannelsRetryOnlyFailed(t *testing.T) {
+	sent, failed := 0, 0
+	channels := Channels{
+		{Name: ChannelTelegram, Notifier: counting{&sent}},
+		{Name: ChannelWebhook, Notifier: failing{&failed}},
+	}
+
+	delivered, err := channels.Notify(context.Background(), models.OrderEvent{}, nil)
+	assert.ErrorContains(t, err, ChannelWebhook)
+	assert.Equal(t, []string{ChannelTelegram}, delivered)
+
+	// the retry skips the channel that took the event
+	delivered, err = channels.Notify(context.Background(), models.OrderEvent{}, delivered)
+	assert.Error(t, err)
+	assert.Equal(t, []string{ChannelTelegram}, delivered)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 2, failed)
+}
+
+func TestNewWithoutChannels(t *testing.T) {
+	delivered, err := New(config.Config{}).Notify(context.Background(), models.OrderEvent{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, delivered)
+}
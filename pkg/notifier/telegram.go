@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rent-car/api/models"
+)
+
+type telegram struct {
+	botToken string
+	chatID   string
+	client   *http.Client
+}
+
+func NewTelegram(botToken, chatID string) Notifier {
+	return telegram{
+		botToken: botToken,
+		chatID:   chatID,
+		client:   &http.Client{},
+	}
+}
+
+func (t telegram) Notify(ctx context.Context, event models.OrderEvent) error {
+	payload := struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}{
+		ChatID: t.chatID,
+		Text:   formatText(event),
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := "https://api.telegram.org/bot" + t.botToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(data))
+	if err != nil {
+		return hideURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram: %w", hideURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rent-car/api/models"
+)
+
+type webhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhook posts every event as JSON to url. When secret is set the body is signed
+// with HMAC-SHA256 and the hex signature is sent in the X-Signature header.
+func NewWebhook(url, secret string) Notifier {
+	return webhook{
+		url:    url,
+		secret: secret,
+		client: &http.Client{},
+	}
+}
+
+func (w webhook) Notify(ctx context.Context, event models.OrderEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewBuffer(data))
+	if err != nil {
+		return hideURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(data)
+		req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", hideURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
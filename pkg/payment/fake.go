@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Fake is a local provider that accepts every request and keeps it in memory,
+// set Err to make the following requests fail.
+type Fake struct {
+	mu      sync.Mutex
+	Charges []Request
+	Refunds []Request
+	Err     error
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Charge(ctx context.Context, req Request) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return "", fmt.Errorf("fake: %w", f.Err)
+	}
+	f.Charges = append(f.Charges, req)
+	return "fake_ch_" + uuid.NewString(), nil
+}
+
+func (f *Fake) Refund(ctx context.Context, req Request) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return "", fmt.Errorf("fake: %w", f.Err)
+	}
+	f.Refunds = append(f.Refunds, req)
+	return "fake_re_" + uuid.NewString(), nil
+}
@@ -0,0 +1,35 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"rent-car/config"
+)
+
+// Provider moves card money at the payment provider, cash and transfers are only recorded in the ledger.
+type Provider interface {
+	// Charge takes the amount from the customer and returns the provider reference of the charge.
+	Charge(ctx context.Context, req Request) (string, error)
+	// Refund gives the amount back to the customer and returns the provider reference of the refund.
+	Refund(ctx context.Context, req Request) (string, error)
+}
+
+type Request struct {
+	OrderId string
+	Amount  float32
+}
+
+// New builds the provider named by cfg.PaymentProvider. The fake provider confirms every card payment
+// without moving money, so it is built only when cfg.AllowFakePayments is set.
+func New(cfg config.Config) (Provider, error) {
+	switch cfg.PaymentProvider {
+	case "":
+		return nil, fmt.Errorf("payment: no provider configured, set PAYMENT_PROVIDER")
+	case config.PAYMENT_PROVIDER_FAKE:
+		if !cfg.AllowFakePayments {
+			return nil, fmt.Errorf("payment: the fake provider needs ALLOW_FAKE_PAYMENTS=true")
+		}
+		return NewFake(), nil
+	}
+	return nil, fmt.Errorf("payment: unknown provider %q", cfg.PaymentProvider)
+}
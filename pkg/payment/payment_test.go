@@ -0,0 +1,49 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"rent-car/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	// no provider is never silently replaced by the fake
+	_, err := New(config.Config{})
+	assert.Error(t, err)
+	_, err = New(config.Config{AllowFakePayments: true})
+	assert.Error(t, err)
+
+	_, err = New(config.Config{PaymentProvider: config.PAYMENT_PROVIDER_FAKE})
+	assert.Error(t, err)
+
+	provider, err := New(config.Config{PaymentProvider: config.PAYMENT_PROVIDER_FAKE, AllowFakePayments: true})
+	if assert.NoError(t, err) {
+		assert.IsType(t, &Fake{}, provider)
+	}
+
+	_, err = New(config.Config{PaymentProvider: "unknown", AllowFakePayments: true})
+	assert.Error(t, err)
+}
+
+func TestFakeRecordsRequests(t *testing.T) {
+	fake := NewFake()
+
+	ref, err := fake.Charge(context.Background(), Request{OrderId: "order", Amount: 120})
+	if assert.NoError(t, err) {
+		assert.True(t, strings.HasPrefix(ref, "fake_ch_"))
+	}
+	_, err = fake.Refund(context.Background(), Request{OrderId: "order", Amount: 20})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Request{{OrderId: "order", Amount: 120}}, fake.Charges)
+	assert.Equal(t, []Request{{OrderId: "order", Amount: 20}}, fake.Refunds)
+
+	fake.Err = errors.New("card declined")
+	_, err = fake.Charge(context.Background(), Request{OrderId: "order", Amount: 10})
+	assert.ErrorIs(t, err, fake.Err)
+	assert.Len(t, fake.Charges, 1)
+}
@@ -5,10 +5,21 @@ import (
 	"fmt"
 	"rent-car/api/models"
 	"rent-car/config"
+	"rent-car/pkg/errs"
 	"rent-car/pkg/jwt"
 	"rent-car/pkg/logger"
 	"rent-car/pkg/logger/password"
 	"rent-car/storage"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+var (
+	ErrInvalidToken       = errs.Unauthorized("invalid_token", "invalid token")
+	ErrTokenRevoked       = errs.Unauthorized("token_revoked", "token is revoked")
+	ErrTokenReused        = errs.Unauthorized("token_reused", "refresh token was already used, token family is revoked")
+	ErrInvalidCredentials = errs.Unauthorized("invalid_credentials", "login or password is incorrect")
 )
 
 type authService struct {
@@ -28,26 +39,211 @@ func (a authService) CustomerLogin(ctx context.Context, loginRequest models.Cust
 	customer, err := a.storage.Customer().GetByLogin(ctx, loginRequest.Login)
 	if err != nil {
 		a.log.Error("error while getting customer credentials by login", logger.Error(err))
-		return models.CustomerLoginResponse{}, err
+		return models.CustomerLoginResponse{}, credentialsError(err)
 	}
 
 	if err = password.CompareHashAndPassword(customer.Password, loginRequest.Password); err != nil {
 		a.log.Error("error while comparing password", logger.Error(err))
+		return models.CustomerLoginResponse{}, ErrInvalidCredentials.Wrap(err)
+	}
+
+	// checked after the password, so the block does not tell whether a login exists
+	if customer.Is_Blocked {
+		return models.CustomerLoginResponse{}, blockedError(customer.BlockedReason, customer.BlockedUntil)
+	}
+
+	accessToken, refreshToken, err := a.genTokens(customer.Id, config.CUSTOMER_ROLE, uuid.NewString())
+	if err != nil {
+		a.log.Error("error while generating tokens for customer login", logger.Error(err))
 		return models.CustomerLoginResponse{}, err
 	}
 
-	m := make(map[interface{}]interface{})
+	return models.CustomerLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (a authService) StaffLogin(ctx context.Context, loginRequest models.StaffLoginRequest) (models.StaffLoginResponse, error) {
+	staff, err := a.storage.Staff().GetByLogin(ctx, loginRequest.Login)
+	if err != nil {
+		a.log.Error("error while getting staff credentials by login", logger.Error(err))
+		return models.StaffLoginResponse{}, credentialsError(err)
+	}
 
-	m["user_id"] = customer.Id
-	m["user_role"] = config.CUSTOMER_ROLE
+	if err = password.CompareHashAndPassword(staff.Password, loginRequest.Password); err != nil {
+		a.log.Error("error while comparing password", logger.Error(err))
+		return models.StaffLoginResponse{}, ErrInvalidCredentials.Wrap(err)
+	}
 
-    accessToken,refreshToken,err :=jwt.GenJWT(m)
+	accessToken, refreshToken, err := a.genTokens(staff.Id, staff.Role, uuid.NewString())
 	if err != nil {
-		a.log.Error("error while generating tokens for customer login",logger.Error(err))
+		a.log.Error("error while generating tokens for staff login", logger.Error(err))
+		return models.StaffLoginResponse{}, err
 	}
 
-	return models.CustomerLoginResponse{
-		AccessToken: accessToken,
+	return models.StaffLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Refresh swaps a refresh token for a new token pair of the same family.
+// Every refresh token can be used once, presenting it again revokes the whole family.
+func (a authService) Refresh(ctx context.Context, req models.RefreshTokenRequest) (models.TokenResponse, error) {
+	claims, err := a.refreshClaims(req.RefreshToken)
+	if err != nil {
+		return models.TokenResponse{}, err
+	}
+
+	revoked, err := a.storage.Token().IsFamilyRevoked(ctx, claims.FamilyID)
+	if err != nil {
+		a.log.Error("error while checking token family", logger.Error(err))
+		return models.TokenResponse{}, err
+	}
+	if revoked {
+		return models.TokenResponse{}, ErrTokenRevoked
+	}
+
+	revoked, err = a.storage.Token().IsUserRevoked(ctx, claims.UserID, claims.IssuedAt)
+	if err != nil {
+		a.log.Error("error while checking revoked user tokens", logger.Error(err))
+		return models.TokenResponse{}, err
+	}
+	if revoked {
+		return models.TokenResponse{}, ErrTokenRevoked
+	}
+
+	rotated, err := a.storage.Token().Revoke(ctx, claims.RevokedToken("rotated"))
+	if err != nil {
+		a.log.Error("error while rotating refresh token", logger.Error(err))
+		return models.TokenResponse{}, err
+	}
+	if !rotated {
+		a.log.Warning("refresh token reuse detected", logger.String("family_id", claims.FamilyID), logger.String("user_id", claims.UserID))
+		if err = a.storage.Token().RevokeFamily(ctx, claims.FamilyID, claims.UserID, "reuse detected"); err != nil {
+			a.log.Error("error while revoking token family", logger.Error(err))
+			return models.TokenResponse{}, err
+		}
+		return models.TokenResponse{}, ErrTokenReused
+	}
+
+	accessToken, refreshToken, err := a.genTokens(claims.UserID, claims.UserRole, claims.FamilyID)
+	if err != nil {
+		a.log.Error("error while generating tokens for refresh", logger.Error(err))
+		return models.TokenResponse{}, err
+	}
+
+	return models.TokenResponse{
+		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-	},nil
+	}, nil
+}
+
+// Logout revokes the refresh token and its family, so access tokens issued with it stop working too.
+func (a authService) Logout(ctx context.Context, req models.RefreshTokenRequest) error {
+	claims, err := a.refreshClaims(req.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	if _, err = a.storage.Token().Revoke(ctx, claims.RevokedToken("logout")); err != nil {
+		a.log.Error("error while revoking refresh token", logger.Error(err))
+		return err
+	}
+
+	if err = a.storage.Token().RevokeFamily(ctx, claims.FamilyID, claims.UserID, "logout"); err != nil {
+		a.log.Error("error while revoking token family", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (a authService) IsTokenRevoked(ctx context.Context, info models.AuthInfo) (bool, error) {
+	revoked, err := a.storage.Token().IsRevoked(ctx, info.TokenID)
+	if err != nil {
+		a.log.Error("error while checking revoked token", logger.Error(err))
+		return false, err
+	}
+	if revoked {
+		return true, nil
+	}
+
+	revoked, err = a.storage.Token().IsFamilyRevoked(ctx, info.FamilyID)
+	if err != nil {
+		a.log.Error("error while checking token family", logger.Error(err))
+		return false, err
+	}
+	if revoked {
+		return true, nil
+	}
+
+	revoked, err = a.storage.Token().IsUserRevoked(ctx, info.UserID, info.IssuedAt)
+	if err != nil {
+		a.log.Error("error while checking revoked user tokens", logger.Error(err))
+		return false, err
+	}
+	return revoked, nil
+}
+
+// credentialsError hides whether the login exists, an unknown login fails like a wrong password.
+func credentialsError(err error) error {
+	if errs.KindOf(err) == errs.KindNotFound {
+		return ErrInvalidCredentials.Wrap(err)
+	}
+	return err
+}
+
+func (a authService) genTokens(userID, role, familyID string) (string, string, error) {
+	m := make(map[interface{}]interface{})
+
+	m["user_id"] = userID
+	m["user_role"] = role
+	m["family_id"] = familyID
+
+	return jwt.GenJWT(m)
+}
+
+type refreshClaims struct {
+	Jti       string
+	FamilyID  string
+	UserID    string
+	UserRole  string
+	IssuedAt  int64
+	ExpiresAt int64
+}
+
+func (r refreshClaims) RevokedToken(reason string) models.RevokedToken {
+	return models.RevokedToken{
+		Jti:       r.Jti,
+		FamilyID:  r.FamilyID,
+		UserID:    r.UserID,
+		Reason:    reason,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+func (a authService) refreshClaims(token string) (refreshClaims, error) {
+	m, err := jwt.ExtractClaims(token)
+	if err != nil {
+		return refreshClaims{}, ErrInvalidToken.Wrap(err)
+	}
+
+	if tokenType, _ := m["token_type"].(string); tokenType != config.REFRESH_TOKEN_TYPE {
+		return refreshClaims{}, ErrInvalidToken
+	}
+
+	claims := refreshClaims{
+		IssuedAt:  cast.ToInt64(m["iat"]),
+		ExpiresAt: cast.ToInt64(m["exp"]),
+	}
+	claims.Jti, _ = m["jti"].(string)
+	claims.FamilyID, _ = m["family_id"].(string)
+	claims.UserID, _ = m["user_id"].(string)
+	claims.UserRole, _ = m["user_role"].(string)
+
+	if claims.Jti == "" || claims.FamilyID == "" || claims.UserID == "" || claims.UserRole == "" {
+		return refreshClaims{}, ErrInvalidToken
+	}
+	return claims, nil
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"time"
+)
+
+// refundMethods is the order refunds are given back in, card first as the provider returns it to the customer.
+var refundMethods = []string{config.PAYMENT_METHOD_CARD, config.PAYMENT_METHOD_TRANSFER, config.PAYMENT_METHOD_CASH}
+
+// Cancel cancels the order on behalf of the actor and refunds the part of the paid amount
+// the cancellation policy allows, see config.Config.CancellationPolicy. The cancellation and its refunds are
+// claimed in the ledger before card money is given back by the payment provider, a refund the provider
+// declines does not undo the cancellation and is reported in RefundFailed.
+func (os orderService) Cancel(ctx context.Context, req models.CancelOrder, actor models.AuthInfo) (models.OrderCancellation, error) {
+	order, err := os.storage.Order().GetByID(ctx, req.OrderId)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while getting order for cancellation", logger.Error(err))
+		return models.OrderCancellation{}, err
+	}
+
+	if actor.UserRole == config.CUSTOMER_ROLE && order.CustomerId != actor.UserID {
+		return models.OrderCancellation{}, ErrStatusTransitionForbidden
+	}
+	if err = checkStatusTransition(order.Status, config.STATUS_CANCELED, actor.UserRole); err != nil {
+		return models.OrderCancellation{}, err
+	}
+
+	policy, percent, err := cancellationPolicy(os.cancellationPolicy, order.Status, order.FromDate, time.Now())
+	if err != nil {
+		return models.OrderCancellation{}, err
+	}
+
+	cancellation := models.OrderCancellation{
+		OrderId:       order.Id,
+		Policy:        policy,
+		RefundPercent: percent,
+		RefundAmount:  roundPrice(float64(order.Balance.PaidAmount) * float64(percent) / 100),
+		Reason:        req.Reason,
+		ActorId:       actor.UserID,
+		ActorRole:     actor.UserRole,
+		FromStatus:    order.Status,
+		// points spent on the order are given back for another full period
+		PointsExpireAt: time.Now().Add(os.loyaltyPointsExpiry).Format(time.RFC3339),
+	}
+
+	if cancellation.RefundAmount > 0 {
+		payments, err := os.storage.Payment().GetByOrderID(ctx, order.Id)
+		if err != nil {
+			return models.OrderCancellation{}, err
+		}
+		refunds := allocateRefund(payments, cancellation.RefundAmount)
+		for i := range refunds {
+			refunds[i].OrderId = order.Id
+			refunds[i].Note = "cancellation: " + policy
+			refunds[i].ActorId = actor.UserID
+			refunds[i].ActorRole = actor.UserRole
+			refunds[i].Status = config.PAYMENT_CONFIRMED
+			if refunds[i].Method == config.PAYMENT_METHOD_CARD {
+				refunds[i].Status = config.PAYMENT_PENDING
+			}
+		}
+		cancellation.Refunds = refunds
+	}
+
+	refundIDs, err := os.storage.Order().Cancel(ctx, cancellation)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while canceling order", logger.Error(err), logger.String("order_id", order.Id))
+		return models.OrderCancellation{}, err
+	}
+
+	for i, refund := range cancellation.Refunds {
+		if refund.Status != config.PAYMENT_PENDING {
+			continue
+		}
+		if err = os.payments.move(ctx, refundIDs[i], refund); err != nil {
+			os.logger.Error("ERROR in service layer while refunding canceled order",
+				logger.Error(err),
+				logger.String("order_id", order.Id),
+				logger.Any("amount", refund.Amount))
+			cancellation.RefundFailed = roundPrice(float64(cancellation.RefundFailed + refund.Amount))
+		}
+	}
+	os.logger.Info("order canceled",
+		logger.String("order_id", order.Id),
+		logger.String("policy", policy),
+		logger.Any("refund", cancellation.RefundAmount))
+	return cancellation, nil
+}
+
+// cancellationPolicy picks the rule of rules for canceling an order in status at the given time.
+func cancellationPolicy(rules []config.CancellationRule, status, fromDate string, at time.Time) (string, float32, error) {
+	if status != config.STATUS_NEW {
+		return config.CANCELLATION_NO_REFUND, 0, nil
+	}
+
+	from, err := time.Parse(time.DateOnly, fromDate)
+	if err != nil {
+		return "", 0, errs.InvalidField("from_date", err)
+	}
+
+	notice := from.Sub(at)
+	for _, rule := range rules {
+		if notice >= rule.MinNotice {
+			return rule.Name, rule.RefundPercent, nil
+		}
+	}
+	return config.CANCELLATION_NO_REFUND, 0, nil
+}
+
+// allocateRefund splits amount over the methods the order was paid with, in the order of refundMethods,
+// no method gives back more than was paid and not refunded with it.
+func allocateRefund(payments []models.Payment, amount float32) []models.RecordPayment {
+	paid := map[string]float64{}
+	for _, payment := range payments {
+		// pending refunds are given back already, pending and failed charges are not paid
+		if payment.Status == config.PAYMENT_FAILED ||
+			(payment.Status == config.PAYMENT_PENDING && payment.Kind != config.PAYMENT_KIND_REFUND) {
+			continue
+		}
+		switch payment.Kind {
+		case config.PAYMENT_KIND_PAYMENT:
+			paid[payment.Method] += float64(payment.Amount)
+		case config.PAYMENT_KIND_REFUND:
+			paid[payment.Method] -= float64(payment.Amount)
+		}
+	}
+
+	refunds := []models.RecordPayment{}
+	left := float64(amount)
+	for _, method := range refundMethods {
+		if left <= 0 {
+			break
+		}
+		part := float64(roundPrice(min(left, paid[method])))
+		if part <= 0 {
+			continue
+		}
+		refunds = append(refunds, models.RecordPayment{
+			Kind:   config.PAYMENT_KIND_REFUND,
+			Method: method,
+			Amount: float32(part),
+		})
+		left = float64(roundPrice(left - part))
+	}
+	return refunds
+}
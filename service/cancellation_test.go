@@ -0,0 +1,85 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationPolicy(t *testing.T) {
+	cases := []struct {
+		status  string
+		at      string
+		policy  string
+		percent float32
+	}{
+		{config.STATUS_NEW, "2030-07-05T23:59:00Z", "free_cancellation", 100},
+		{config.STATUS_NEW, "2030-07-06T00:00:00Z", "free_cancellation", 100},
+		{config.STATUS_NEW, "2030-07-06T00:01:00Z", "late_cancellation", 50},
+		{config.STATUS_NEW, "2030-07-08T00:00:00Z", "late_cancellation", 50},
+		// after the rental started
+		{config.STATUS_NEW, "2030-07-08T10:00:00Z", config.CANCELLATION_NO_REFUND, 0},
+		{config.STATUS_IN_PROCESS, "2030-07-01T00:00:00Z", config.CANCELLATION_NO_REFUND, 0},
+	}
+	for _, c := range cases {
+		at, err := time.Parse(time.RFC3339, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		policy, percent, err := cancellationPolicy(config.CANCELLATION_POLICY, c.status, "2030-07-08", at)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.policy, policy, c.at)
+			assert.Equal(t, c.percent, percent, c.at)
+		}
+	}
+
+	_, _, err := cancellationPolicy(config.CANCELLATION_POLICY, config.STATUS_NEW, "08.07.2030", time.Now())
+	assert.Error(t, err)
+
+	// a deployment without late refunds
+	strict := []config.CancellationRule{{Name: "free_cancellation", MinNotice: 7 * 24 * time.Hour, RefundPercent: 100}}
+	policy, percent, err := cancellationPolicy(strict, config.STATUS_NEW, "2030-07-08", time.Date(2030, time.July, 2, 0, 0, 0, 0, time.UTC))
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.CANCELLATION_NO_REFUND, policy)
+		assert.Zero(t, percent)
+	}
+}
+
+func TestAllocateRefund(t *testing.T) {
+	payments := []models.Payment{
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CASH, Amount: 100},
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CARD, Amount: 80},
+		{Kind: config.PAYMENT_KIND_REFUND, Method: config.PAYMENT_METHOD_CARD, Amount: 30},
+		// deposits are settled separately
+		{Kind: config.PAYMENT_KIND_DEPOSIT, Method: config.PAYMENT_METHOD_CARD, Amount: 200},
+	}
+
+	refunds := allocateRefund(payments, 90)
+	if assert.Len(t, refunds, 2) {
+		assert.Equal(t, config.PAYMENT_METHOD_CARD, refunds[0].Method)
+		assert.Equal(t, float32(50), refunds[0].Amount)
+		assert.Equal(t, config.PAYMENT_METHOD_CASH, refunds[1].Method)
+		assert.Equal(t, float32(40), refunds[1].Amount)
+		assert.Equal(t, config.PAYMENT_KIND_REFUND, refunds[1].Kind)
+	}
+
+	assert.Empty(t, allocateRefund(payments, 0))
+	assert.Len(t, allocateRefund(payments, 20), 1)
+
+	// only money the provider moved is given back
+	payments = []models.Payment{
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CASH, Amount: 100, Status: config.PAYMENT_CONFIRMED},
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CARD, Amount: 80, Status: config.PAYMENT_FAILED},
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CARD, Amount: 60, Status: config.PAYMENT_CONFIRMED},
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CARD, Amount: 40, Status: config.PAYMENT_PENDING},
+		{Kind: config.PAYMENT_KIND_REFUND, Method: config.PAYMENT_METHOD_CARD, Amount: 20, Status: config.PAYMENT_PENDING},
+	}
+	refunds = allocateRefund(payments, 90)
+	if assert.Len(t, refunds, 2) {
+		assert.Equal(t, float32(40), refunds[0].Amount)
+		assert.Equal(t, float32(50), refunds[1].Amount)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"rent-car/api/models"
 	"rent-car/pkg/logger"
 	"rent-car/storage"
+	"time"
 )
 
 
@@ -65,7 +66,7 @@ func (u carService) GetCarAll(ctx context.Context,car models.GetAllCarsRequest)
 	return cars,nil
 }
 
-func (u carService) GetAvaibleCars(ctx context.Context,car models.GetAllCarsRequest) (models.GetAllCarsResponse, error) {
+func (u carService) GetAvaibleCars(ctx context.Context,car models.GetAvailableCarsRequest) (models.GetAllCarsResponse, error) {
 	cars, err := u.storage.Car().GetAvaibleCars(ctx,car)
 	if err != nil {
 		u.logger.Error("error service layer while getting free cars", logger.Error(err))
@@ -74,4 +75,49 @@ func (u carService) GetAvaibleCars(ctx context.Context,car models.GetAllCarsRequ
 	return cars,nil
 }
 
- 
\ No newline at end of file
+func (u carService) CreateSeasonalRate(ctx context.Context, rate models.SeasonalRate) (string, error) {
+	pkey, err := u.storage.Car().CreateSeasonalRate(ctx, rate)
+	if err != nil {
+		u.logger.Error("ERROR in service layer while creating seasonal rate", logger.Error(err))
+		return "", err
+	}
+	return pkey, nil
+}
+
+func (u carService) DeleteSeasonalRate(ctx context.Context, carID, id string) error {
+	err := u.storage.Car().DeleteSeasonalRate(ctx, carID, id)
+	if err != nil {
+		u.logger.Error("ERROR in service layer while deleting seasonal rate", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (u carService) GetDeleted(ctx context.Context, req models.GetDeletedRequest) (models.GetAllCarsResponse, error) {
+	cars, err := u.storage.Car().GetDeleted(ctx, req)
+	if err != nil {
+		u.logger.Error("ERROR in service layer while getting deleted cars", logger.Error(err))
+		return cars, err
+	}
+	return cars, nil
+}
+
+func (u carService) Restore(ctx context.Context, id string) error {
+	err := u.storage.Car().Restore(ctx, id)
+	if err != nil {
+		u.logger.Error("ERROR in service layer while restoring car", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Purge removes the cars deleted more than retention ago which no order references.
+func (u carService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, err := u.storage.Car().Purge(ctx, retention)
+	if err != nil {
+		u.logger.Error("ERROR in service layer while purging deleted cars", logger.Error(err))
+		return 0, err
+	}
+	u.logger.Info("purged deleted cars", logger.Any("count", purged))
+	return purged, nil
+}
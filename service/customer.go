@@ -2,11 +2,25 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
 	"rent-car/pkg/logger"
 	"rent-car/storage"
+	"time"
 )
 
+// ErrCustomerBlocked is returned when a blocked customer logs in or books a car.
+var ErrCustomerBlocked = errs.Forbidden("customer_blocked", "customer is blocked")
+
+// blockedError tells the customer why and until when the block lasts.
+func blockedError(reason, until string) error {
+	if until == "" {
+		return fmt.Errorf("%w: %s", ErrCustomerBlocked, reason)
+	}
+	return fmt.Errorf("%w until %s: %s", ErrCustomerBlocked, until, reason)
+}
 
 type customerService struct {
 	storage storage.IStorage
@@ -29,7 +43,7 @@ func (cs customerService) Create(ctx context.Context, customer models.Customer)
 	return pkey,nil
 }
 
-func (cs customerService) Update(ctx context.Context, customer models.Customer) (string,error) {
+func (cs customerService) Update(ctx context.Context, customer models.UpdateCustomer) (string,error) {
 	pkey, err := cs.storage.Customer().UpdateCustomer(ctx,customer)
 	if err != nil {
 		cs.logger.Error("ERROR in service layer while updating customer", logger.Error(err))
@@ -84,4 +98,85 @@ func (u customerService) GetPasswordforLogin(ctx context.Context, phone string)
 	}
 
 	return pKey, nil
-}
\ No newline at end of file
+}
+func (cs customerService) GetDeleted(ctx context.Context, req models.GetDeletedRequest) (models.GetDeletedCustomersResponse, error) {
+	customers, err := cs.storage.Customer().GetDeleted(ctx, req)
+	if err != nil {
+		cs.logger.Error("ERROR in service layer while getting deleted customers", logger.Error(err))
+		return customers, err
+	}
+	return customers, nil
+}
+
+func (cs customerService) Restore(ctx context.Context, id string) error {
+	err := cs.storage.Customer().Restore(ctx, id)
+	if err != nil {
+		cs.logger.Error("ERROR in service layer while restoring customer", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Purge removes the customers deleted more than retention ago which no order references.
+func (cs customerService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, err := cs.storage.Customer().Purge(ctx, retention)
+	if err != nil {
+		cs.logger.Error("ERROR in service layer while purging deleted customers", logger.Error(err))
+		return 0, err
+	}
+	cs.logger.Info("purged deleted customers", logger.Any("count", purged))
+	return purged, nil
+}
+
+// Block blocks the customer on behalf of the actor, the customer's tokens stop working at once.
+func (cs customerService) Block(ctx context.Context, customerID string, req models.BlockCustomerRequest, actor models.AuthInfo) error {
+	event := models.CustomerBlockEvent{
+		CustomerId:   customerID,
+		Action:       config.BLOCK_ACTION_BLOCKED,
+		Reason:       req.Reason,
+		BlockedUntil: req.Until,
+		ActorId:      actor.UserID,
+		ActorRole:    actor.UserRole,
+	}
+	if err := cs.storage.Customer().Block(ctx, event); err != nil {
+		cs.logger.Error("ERROR in service layer while blocking customer", logger.Error(err))
+		return err
+	}
+	cs.logger.Info("customer blocked",
+		logger.String("customer_id", customerID),
+		logger.String("actor_id", actor.UserID),
+		logger.String("until", req.Until))
+	return nil
+}
+
+func (cs customerService) Unblock(ctx context.Context, customerID string, req models.UnblockCustomerRequest, actor models.AuthInfo) error {
+	event := models.CustomerBlockEvent{
+		CustomerId: customerID,
+		Action:     config.BLOCK_ACTION_UNBLOCKED,
+		Reason:     req.Reason,
+		ActorId:    actor.UserID,
+		ActorRole:  actor.UserRole,
+	}
+	if err := cs.storage.Customer().Unblock(ctx, event); err != nil {
+		cs.logger.Error("ERROR in service layer while unblocking customer", logger.Error(err))
+		return err
+	}
+	cs.logger.Info("customer unblocked",
+		logger.String("customer_id", customerID),
+		logger.String("actor_id", actor.UserID))
+	return nil
+}
+
+func (cs customerService) GetBlockEvents(ctx context.Context, customerID string) ([]models.CustomerBlockEvent, error) {
+	// an unknown customer is not found rather than a customer without blocks
+	if _, err := cs.storage.Customer().GetByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	events, err := cs.storage.Customer().GetBlockEvents(ctx, customerID)
+	if err != nil {
+		cs.logger.Error("ERROR in service layer while getting customer block events", logger.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"time"
+)
+
+var (
+	ErrOrderNotExtendable = errs.Conflict("order_not_extendable", "only new or in-process orders that are not overdue can be extended")
+	ErrInvalidExtension   = errs.Validation("invalid_extension", "to_date must be after the current to_date",
+		errs.FieldError{Field: "to_date", Message: "must be after the current to_date"})
+)
+
+// Extend asks to move the to_date of the order later on behalf of the actor. The extra days are priced on
+// their own with the pricing rules and recorded as an extension, the original booking is left as it was.
+// The extension is applied right away unless it waits for staff, see config.Config.ExtensionRequiresApproval.
+func (os orderService) Extend(ctx context.Context, req models.ExtendOrder, actor models.AuthInfo) (models.OrderExtension, error) {
+	order, err := os.storage.Order().GetByID(ctx, req.OrderId)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while getting order for extension", logger.Error(err))
+		return models.OrderExtension{}, err
+	}
+
+	if actor.UserRole == config.CUSTOMER_ROLE && order.CustomerId != actor.UserID {
+		return models.OrderExtension{}, ErrStatusTransitionForbidden
+	}
+	if err = checkExtension(order, req.ToDate); err != nil {
+		return models.OrderExtension{}, err
+	}
+
+	// the licence must still be valid on the new to_date
+	if err = os.verification.checkDriver(ctx, order.CustomerId, order.FromDate, req.ToDate); err != nil {
+		return models.OrderExtension{}, err
+	}
+
+	quote, err := os.pricing.Quote(ctx, order.CarId, order.ToDate, req.ToDate)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while pricing extension", logger.Error(err))
+		return models.OrderExtension{}, err
+	}
+
+	id, err := os.storage.Extension().Create(ctx, models.OrderExtension{
+		OrderId:        order.Id,
+		PreviousToDate: order.ToDate,
+		ToDate:         req.ToDate,
+		Days:           quote.Days,
+		Amount:         quote.Amount,
+		LineItems:      quote.LineItems,
+		Status:         extensionStatus(os.extensionRequiresApproval, actor.UserRole),
+		Note:           req.Note,
+		RequestedBy:    actor.UserID,
+		RequestedRole:  actor.UserRole,
+	})
+	if err != nil {
+		os.logger.Error("ERROR in service layer while extending order", logger.Error(err), logger.String("order_id", order.Id))
+		return models.OrderExtension{}, err
+	}
+
+	return os.storage.Extension().GetByID(ctx, order.Id, id)
+}
+
+// ReviewExtension approves or rejects a pending extension on behalf of the staff member,
+// an approved extension is applied when the car is still free.
+func (os orderService) ReviewExtension(ctx context.Context, req models.ReviewExtension) (models.OrderExtension, error) {
+	if err := os.storage.Extension().Review(ctx, req); err != nil {
+		os.logger.Error("ERROR in service layer while reviewing extension", logger.Error(err), logger.String("extension_id", req.ExtensionId))
+		return models.OrderExtension{}, err
+	}
+	return os.storage.Extension().GetByID(ctx, req.OrderId, req.ExtensionId)
+}
+
+// checkExtension refuses to extend an order that is over, overdue or would not end later than now.
+func checkExtension(order models.OrderAll, toDate string) error {
+	if (order.Status != config.STATUS_NEW && order.Status != config.STATUS_IN_PROCESS) || order.LateDays > 0 {
+		return ErrOrderNotExtendable
+	}
+
+	current, err := time.Parse(time.DateOnly, order.ToDate)
+	if err != nil {
+		return err
+	}
+	to, err := time.Parse(time.DateOnly, toDate)
+	if err != nil {
+		return errs.InvalidField("to_date", err)
+	}
+	if !to.After(current) {
+		return ErrInvalidExtension
+	}
+	return nil
+}
+
+// extensionStatus is the status a new extension starts in, customers wait for staff when approval is required.
+func extensionStatus(requiresApproval bool, role string) string {
+	if requiresApproval && role == config.CUSTOMER_ROLE {
+		return config.EXTENSION_PENDING
+	}
+	return config.EXTENSION_APPROVED
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExtension(t *testing.T) {
+	order := models.OrderAll{Status: config.STATUS_IN_PROCESS, FromDate: "2030-07-01", ToDate: "2030-07-08"}
+
+	assert.NoError(t, checkExtension(order, "2030-07-09"))
+	assert.ErrorIs(t, checkExtension(order, "2030-07-08"), ErrInvalidExtension)
+	assert.ErrorIs(t, checkExtension(order, "2030-07-05"), ErrInvalidExtension)
+	assert.Error(t, checkExtension(order, "09.07.2030"))
+
+	overdue := order
+	overdue.LateDays = 1
+	assert.ErrorIs(t, checkExtension(overdue, "2030-07-12"), ErrOrderNotExtendable)
+
+	for _, status := range []string{config.STATUS_FINISHED, config.STATUS_CANCELED} {
+		over := order
+		over.Status = status
+		assert.ErrorIs(t, checkExtension(over, "2030-07-12"), ErrOrderNotExtendable, status)
+	}
+}
+
+func TestExtensionStatus(t *testing.T) {
+	assert.Equal(t, config.EXTENSION_PENDING, extensionStatus(true, config.CUSTOMER_ROLE))
+	assert.Equal(t, config.EXTENSION_APPROVED, extensionStatus(true, config.AGENT_ROLE))
+	assert.Equal(t, config.EXTENSION_APPROVED, extensionStatus(false, config.CUSTOMER_ROLE))
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"time"
+)
+
+type lateReturnService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewLateReturnService(storage storage.IStorage, logger logger.ILogger) lateReturnService {
+	return lateReturnService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Run charges the late days of overdue orders every config.LateReturnCheckInterval until ctx is canceled.
+func (ls lateReturnService) Run(ctx context.Context) {
+	ticker := time.NewTicker(config.LateReturnCheckInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		ls.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check flags one batch of overdue orders, charges the late days that were not charged yet
+// and returns how many orders were charged. The customer and staff are notified through the outbox.
+func (ls lateReturnService) Check(ctx context.Context) int {
+	orders, err := ls.storage.Order().GetOverdue(ctx, config.LateReturnGrace, config.LateReturnBatchSize)
+	if err != nil {
+		ls.logger.Error("ERROR in service layer while getting overdue orders", logger.Error(err))
+		return 0
+	}
+
+	now := time.Now()
+	charged := 0
+	for _, order := range orders {
+		fee, err := ls.lateFee(ctx, order.Id, order.CarId, order.ToDate, order.LateDays, now)
+		if err != nil {
+			ls.logger.Error("ERROR in service layer while pricing late fee", logger.String("order_id", order.Id), logger.Error(err))
+			continue
+		}
+		if fee.LateDays <= fee.ChargedDays {
+			continue
+		}
+
+		err = ls.storage.Order().ChargeLateFee(ctx, fee)
+		if errors.Is(err, storage.ErrOrderStatusChanged) {
+			// returned or charged by another request meanwhile
+			continue
+		}
+		if err != nil {
+			ls.logger.Error("ERROR in service layer while charging late fee", logger.String("order_id", order.Id), logger.Error(err))
+			continue
+		}
+		ls.logger.Info("late fee charged", logger.String("order_id", order.Id), logger.Int("late_days", fee.LateDays))
+		charged++
+	}
+	return charged
+}
+
+// lateFee prices the late days of the order up to returnedAt that were not charged yet.
+func (ls lateReturnService) lateFee(ctx context.Context, orderID, carID, toDate string, chargedDays int, returnedAt time.Time) (models.LateFee, error) {
+	fee := models.LateFee{OrderId: orderID, ChargedDays: chargedDays, LateDays: chargedDays}
+
+	days, err := lateDays(toDate, returnedAt)
+	if err != nil || days <= chargedDays {
+		return fee, err
+	}
+
+	car, err := ls.storage.Car().GetByID(ctx, carID)
+	if err != nil {
+		return fee, err
+	}
+	fee.LateDays = days
+	fee.Items = lateFeeItems(car, toDate, chargedDays, days)
+	return fee, nil
+}
+
+// lateDays counts the started days since the start of toDate once config.LateReturnGrace has passed.
+func lateDays(toDate string, returnedAt time.Time) (int, error) {
+	due, err := time.Parse(time.DateOnly, toDate)
+	if err != nil {
+		return 0, errs.InvalidField("to_date", err)
+	}
+	if !returnedAt.After(due.Add(config.LateReturnGrace)) {
+		return 0, nil
+	}
+	return int(math.Ceil(returnedAt.Sub(due).Hours() / 24)), nil
+}
+
+// lateFeeItems charges the late days [from, to) after toDate at the car's rate of each day times config.LateFeeMultiplier.
+func lateFeeItems(car models.Car, toDate string, from, to int) []models.OrderLineItem {
+	due, err := time.Parse(time.DateOnly, toDate)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		items = []models.OrderLineItem{}
+		index = map[float32]int{}
+	)
+	for i := from; i < to; i++ {
+		rate := dailyRate(car, due.AddDate(0, 0, i))
+		price := roundPrice(float64(rate.UnitPrice) * config.LateFeeMultiplier)
+
+		j, ok := index[price]
+		if !ok {
+			j = len(items)
+			index[price] = j
+			items = append(items, models.OrderLineItem{
+				Kind:        config.LINE_ITEM_LATE_FEE,
+				Description: fmt.Sprintf("Late return, %s x%v", rate.Description, config.LateFeeMultiplier),
+				UnitPrice:   price,
+			})
+		}
+		items[j].Quantity++
+	}
+
+	for i := range items {
+		items[i].Amount = roundPrice(float64(items[i].UnitPrice) * float64(items[i].Quantity))
+	}
+	return items
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLateDays(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	cases := []struct {
+		returnedAt string
+		days       int
+	}{
+		{"2030-07-07T18:00:00Z", 0},
+		{"2030-07-08T09:00:00Z", 0},
+		// within the grace period
+		{"2030-07-08T12:00:00Z", 0},
+		{"2030-07-08T12:01:00Z", 1},
+		{"2030-07-09T00:30:00Z", 2},
+		{"2030-07-10T23:00:00Z", 3},
+	}
+	for _, c := range cases {
+		days, err := lateDays("2030-07-08", at(c.returnedAt))
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.days, days, c.returnedAt)
+		}
+	}
+}
+
+func TestLateFeeItems(t *testing.T) {
+	car := models.Car{DailyRate: 40, WeekendRate: 60}
+
+	// 2030-07-05 is a Friday, the next two late days fall on the weekend
+	items := lateFeeItems(car, "2030-07-05", 0, 3)
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, config.LINE_ITEM_LATE_FEE, items[0].Kind)
+		assert.Equal(t, float32(60), items[0].UnitPrice)
+		assert.Equal(t, 1, items[0].Quantity)
+		assert.Equal(t, float32(90), items[1].UnitPrice)
+		assert.Equal(t, float32(180), items[1].Amount)
+	}
+
+	// days charged already are skipped
+	items = lateFeeItems(car, "2030-07-05", 2, 3)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, float32(90), items[0].Amount)
+	}
+}
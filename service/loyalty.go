@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"strings"
+	"time"
+)
+
+type loyaltyService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewLoyaltyService(storage storage.IStorage, logger logger.ILogger) loyaltyService {
+	return loyaltyService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Get returns the points balance and tier of the customer with a page of the points ledger.
+func (ls loyaltyService) Get(ctx context.Context, req models.GetLoyaltyRequest) (models.CustomerLoyalty, error) {
+	if _, err := ls.storage.Customer().GetByID(ctx, req.CustomerId); err != nil {
+		return models.CustomerLoyalty{}, err
+	}
+
+	balance, err := ls.balance(ctx, req.CustomerId)
+	if err != nil {
+		return models.CustomerLoyalty{}, err
+	}
+	history, err := ls.storage.Loyalty().GetHistory(ctx, req)
+	if err != nil {
+		ls.logger.Error("ERROR in service layer while getting loyalty history", logger.Error(err))
+		return models.CustomerLoyalty{}, err
+	}
+
+	tier, discount := loyaltyTier(balance.TierPoints)
+	nextTier, missing := nextLoyaltyTier(balance.TierPoints)
+	return models.CustomerLoyalty{
+		CustomerId:          req.CustomerId,
+		Points:              balance.Points,
+		PointValue:          config.LoyaltyPointValue,
+		Tier:                tier,
+		TierDiscountPercent: discount,
+		TierPoints:          balance.TierPoints,
+		NextTier:            nextTier,
+		PointsToNextTier:    missing,
+		History:             history.Entries,
+		Count:               history.Count,
+	}, nil
+}
+
+func (ls loyaltyService) balance(ctx context.Context, customerID string) (models.LoyaltyBalance, error) {
+	balance, err := ls.storage.Loyalty().GetBalance(ctx, customerID, time.Now().Add(-config.LoyaltyTierWindow))
+	if err != nil {
+		ls.logger.Error("ERROR in service layer while getting loyalty balance", logger.Error(err))
+		return models.LoyaltyBalance{}, err
+	}
+	return balance, nil
+}
+
+// Run records the points that expired every config.LoyaltyExpiryCheckInterval until ctx is canceled.
+func (ls loyaltyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(config.LoyaltyExpiryCheckInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		ls.Expire(ctx)
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// Expire records the expired points of one batch of customers and returns how many customers lost points.
+// Balances leave expired points out before they are recorded, so a late run only delays the ledger entry.
+func (ls loyaltyService) Expire(ctx context.Context) int {
+	customers, err := ls.storage.Loyalty().GetExpired(ctx, config.LoyaltyExpiryBatchSize)
+	if err != nil {
+		ls.logger.Error("ERROR in service layer while getting expired loyalty points", logger.Error(err))
+		return 0
+	}
+
+	expired := 0
+	for _, customerID := range customers {
+		points, err := ls.storage.Loyalty().Expire(ctx, customerID)
+		if err != nil {
+			ls.logger.Error("ERROR in service layer while expiring loyalty points", logger.String("customer_id", customerID), logger.Error(err))
+			continue
+		}
+		if points > 0 {
+			ls.logger.Info("loyalty points expired", logger.String("customer_id", customerID), logger.Int("points", points))
+			expired++
+		}
+	}
+	return expired
+}
+
+// earnedPoints are the points an order earns for the amount paid for it.
+func earnedPoints(paid float32) int {
+	if paid <= 0 {
+		return 0
+	}
+	// paid is rounded to cents first so 19.99 never earns 19.989999 worth of points
+	return int(math.Floor(math.Round(float64(paid)*100) * config.LoyaltyPointsPerUnit / 100))
+}
+
+// loyaltyTier is the tier of config.LOYALTY_TIERS the points earned in the tier window reach and its discount.
+func loyaltyTier(tierPoints int) (string, float32) {
+	for _, tier := range config.LOYALTY_TIERS {
+		if tierPoints >= tier.MinPoints {
+			return tier.Name, tier.DiscountPercent
+		}
+	}
+	return config.TIER_MEMBER, 0
+}
+
+// nextLoyaltyTier is the tier above the current one and the points still missing for it, empty at the top.
+func nextLoyaltyTier(tierPoints int) (string, int) {
+	next, missing := "", 0
+	for _, tier := range config.LOYALTY_TIERS {
+		if tierPoints >= tier.MinPoints {
+			break
+		}
+		next, missing = tier.Name, tier.MinPoints-tierPoints
+	}
+	return next, missing
+}
+
+// tierDiscount is the discount line item of the customer's tier on amount, ok is false without a tier discount.
+func tierDiscount(tierPoints int, amount float32) (models.OrderLineItem, bool) {
+	tier, percent := loyaltyTier(tierPoints)
+	if percent <= 0 || amount <= 0 {
+		return models.OrderLineItem{}, false
+	}
+
+	discount := roundPrice(float64(amount) * float64(percent) / 100)
+	return models.OrderLineItem{
+		Kind:        config.LINE_ITEM_TIER,
+		Description: fmt.Sprintf("%s tier discount %v%%", strings.ToUpper(tier[:1])+tier[1:], percent),
+		Quantity:    1,
+		UnitPrice:   -discount,
+		Amount:      -discount,
+	}, true
+}
+
+// pointsDiscount spends up to points of the available ones on amount, no more points are spent than
+// the amount is worth. It returns the points spent and their discount line item.
+func pointsDiscount(points, available int, amount float32) (int, models.OrderLineItem, error) {
+	if points > available {
+		return 0, models.OrderLineItem{}, storage.ErrNotEnoughPoints
+	}
+
+	spent := min(points, int(math.Floor(float64(amount)/config.LoyaltyPointValue+1e-9)))
+	discount := roundPrice(float64(spent) * config.LoyaltyPointValue)
+	return spent, models.OrderLineItem{
+		Kind:        config.LINE_ITEM_LOYALTY,
+		Description: fmt.Sprintf("Loyalty points (%d)", spent),
+		Quantity:    spent,
+		UnitPrice:   -config.LoyaltyPointValue,
+		Amount:      -discount,
+	}, nil
+}
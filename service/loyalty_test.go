@@ -0,0 +1,71 @@
+package service
+
+import (
+	"rent-car/config"
+	"rent-car/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarnedPoints(t *testing.T) {
+	assert.Equal(t, 0, earnedPoints(0))
+	assert.Equal(t, 0, earnedPoints(-10))
+	assert.Equal(t, 19, earnedPoints(19.99))
+	assert.Equal(t, 250, earnedPoints(250))
+}
+
+func TestLoyaltyTier(t *testing.T) {
+	cases := []struct {
+		points   int
+		tier     string
+		discount float32
+		next     string
+		missing  int
+	}{
+		{0, config.TIER_MEMBER, 0, config.TIER_SILVER, 500},
+		{499, config.TIER_MEMBER, 0, config.TIER_SILVER, 1},
+		{500, config.TIER_SILVER, 5, config.TIER_GOLD, 1500},
+		{2000, config.TIER_GOLD, 10, "", 0},
+	}
+	for _, c := range cases {
+		tier, discount := loyaltyTier(c.points)
+		assert.Equal(t, c.tier, tier, c.points)
+		assert.Equal(t, c.discount, discount, c.points)
+
+		next, missing := nextLoyaltyTier(c.points)
+		assert.Equal(t, c.next, next, c.points)
+		assert.Equal(t, c.missing, missing, c.points)
+	}
+}
+
+func TestTierDiscount(t *testing.T) {
+	_, ok := tierDiscount(100, 200)
+	assert.False(t, ok)
+
+	item, ok := tierDiscount(2500, 200)
+	if assert.True(t, ok) {
+		assert.Equal(t, config.LINE_ITEM_TIER, item.Kind)
+		assert.Equal(t, "Gold tier discount 10%", item.Description)
+		assert.Equal(t, float32(-20), item.Amount)
+	}
+}
+
+func TestPointsDiscount(t *testing.T) {
+	spent, item, err := pointsDiscount(100, 300, 200)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100, spent)
+		assert.Equal(t, config.LINE_ITEM_LOYALTY, item.Kind)
+		assert.Equal(t, float32(-100*config.LoyaltyPointValue), item.Amount)
+	}
+
+	// never more points than the price is worth
+	spent, item, err = pointsDiscount(300, 300, 10)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int(10/config.LoyaltyPointValue), spent)
+		assert.Equal(t, float32(-10), item.Amount)
+	}
+
+	_, _, err = pointsDiscount(301, 300, 200)
+	assert.ErrorIs(t, err, storage.ErrNotEnoughPoints)
+}
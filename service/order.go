@@ -2,26 +2,113 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
 	"rent-car/pkg/logger"
+	"rent-car/pkg/payment"
 	"rent-car/storage"
+	"time"
 )
 
 
 type orderService struct {
 	storage storage.IStorage
 	logger logger.ILogger
+	pricing pricingService
+	verification verificationService
+	lateReturns lateReturnService
+	payments paymentService
+	promos promoService
+	loyalty loyaltyService
+	// extensionRequiresApproval is config.Config.ExtensionRequiresApproval
+	extensionRequiresApproval bool
+	// loyaltyPointsExpiry is config.Config.LoyaltyPointsExpiry
+	loyaltyPointsExpiry time.Duration
+	// cancellationPolicy is config.Config.CancellationPolicy
+	cancellationPolicy []config.CancellationRule
 }
 
-func NewOrderService(storage storage.IStorage,logger logger.ILogger) orderService {
+func NewOrderService(storage storage.IStorage,logger logger.ILogger,provider payment.Provider,cfg config.Config) orderService {
 	return orderService{
 		storage: storage,
 		logger: logger,
+		extensionRequiresApproval: cfg.ExtensionRequiresApproval,
+		loyaltyPointsExpiry: cfg.LoyaltyPointsExpiry,
+		cancellationPolicy: cfg.CancellationPolicy,
+		pricing: NewPricingService(storage,logger),
+		verification: NewVerificationService(storage,logger,cfg),
+		lateReturns: NewLateReturnService(storage,logger),
+		payments: NewPaymentService(storage,logger,provider),
+		promos: NewPromoService(storage,logger),
+		loyalty: NewLoyaltyService(storage,logger),
 	}
 }
 
+// Create always prices the order on the server, the amount sent by the client is ignored.
+// Blocked customers can not book, the others need an approved driver licence, see checkVerification.
+// The discounts apply one after another to what is left of the price: the loyalty tier of the customer,
+// the promo code and the loyalty points spent. The deposit is not discounted.
 func (os orderService) Create(ctx context.Context, order models.CreateOrder) (string,error) {
+	order.Status = config.STATUS_NEW
+
+	customer, err := os.storage.Customer().GetByID(ctx, order.CustomerId)
+	if err != nil {
+		return "", err
+	}
+	if customer.Is_Blocked {
+		return "", blockedError(customer.BlockedReason, customer.BlockedUntil)
+	}
+
+	if err = os.verification.checkDriver(ctx, order.CustomerId, order.FromDate, order.ToDate); err != nil {
+		return "", err
+	}
+
+	quote, err := os.pricing.Quote(ctx, order.CarId, order.FromDate, order.ToDate)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while pricing order", logger.Error(err))
+		return "", err
+	}
+	order.Amount = quote.Amount
+	order.Deposit = quote.Deposit
+	order.LineItems = quote.LineItems
+
+	loyalty, err := os.loyalty.balance(ctx, order.CustomerId)
+	if err != nil {
+		return "", err
+	}
+	if item, ok := tierDiscount(loyalty.TierPoints, order.Amount); ok {
+		order.LineItems, order.Amount = addDiscount(order.LineItems, order.Amount, item)
+	}
+
+	if order.PromoCode != "" {
+		priced := quote
+		priced.Amount = order.Amount
+		redemption, item, err := os.promos.redeem(ctx, order.PromoCode, order.CustomerId, order.CarId, priced)
+		if err != nil {
+			return "", err
+		}
+		order.Redemption = &redemption
+		order.LineItems, order.Amount = addDiscount(order.LineItems, order.Amount, item)
+	}
+
+	if order.LoyaltyPoints > 0 {
+		spent, item, err := pointsDiscount(order.LoyaltyPoints, loyalty.Points, order.Amount)
+		if err != nil {
+			return "", err
+		}
+		if spent > 0 {
+			order.LoyaltyRedemption = &models.LoyaltyEntry{
+				CustomerId: order.CustomerId,
+				Kind:       config.LOYALTY_REDEEM,
+				Points:     -spent,
+			}
+			order.LineItems, order.Amount = addDiscount(order.LineItems, order.Amount, item)
+		}
+	}
+
 	pkey,err := os.storage.Order().Create(ctx,order)
 	if err != nil {
 		os.logger.Error("ERROR in service layer while creating order", logger.Error(err))
@@ -30,7 +117,62 @@ func (os orderService) Create(ctx context.Context, order models.CreateOrder) (st
 	return pkey,nil
 }
 
+// Update reprices the order for the new dates, the amount sent by the client is ignored.
+// The promo code the order was booked with must still apply to the new dates.
+// The discounts apply in the same order as in Create.
 func (os orderService) Update(ctx context.Context, order models.UpdateOrder) (string,error) {
+	current, err := os.storage.Order().GetByID(ctx, order.Id)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while getting order for update", logger.Error(err))
+		return "", err
+	}
+	if current.Status != config.STATUS_NEW {
+		return "", ErrOrderNotEditable
+	}
+
+	// new dates may end after the licence expires
+	if err = os.verification.checkDriver(ctx, current.CustomerId, order.FromDate, order.ToDate); err != nil {
+		return "", err
+	}
+
+	quote, err := os.pricing.Quote(ctx, current.CarId, order.FromDate, order.ToDate)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while pricing order", logger.Error(err))
+		return "", err
+	}
+	order.Amount = quote.Amount
+	order.LineItems = quote.LineItems
+
+	loyalty, err := os.loyalty.balance(ctx, current.CustomerId)
+	if err != nil {
+		return "", err
+	}
+	if item, ok := tierDiscount(loyalty.TierPoints, order.Amount); ok {
+		order.LineItems, order.Amount = addDiscount(order.LineItems, order.Amount, item)
+	}
+
+	if current.PromoCode != "" {
+		priced := quote
+		priced.Amount = order.Amount
+		item, err := os.promos.reprice(ctx, current.PromoCode, current.CarId, priced)
+		if err != nil {
+			return "", err
+		}
+		order.Discount = -item.Amount
+		order.LineItems, order.Amount = addDiscount(order.LineItems, order.Amount, item)
+	}
+
+	// the points spent when booking keep their discount as far as the new price allows
+	for _, item := range current.LineItems {
+		if item.Kind != config.LINE_ITEM_LOYALTY {
+			continue
+		}
+		discount := min(-item.Amount, order.Amount)
+		item.Id = ""
+		item.Amount = -discount
+		order.LineItems, order.Amount = addDiscount(order.LineItems, order.Amount, item)
+	}
+
 	pkey, err := os.storage.Order().Update(ctx,order)
 	if err != nil {
 		os.logger.Error("ERROR in service layer while updating order", logger.Error(err))
@@ -67,9 +209,54 @@ func (os orderService) GetOrderAll(ctx context.Context,order models.GetAllOrders
 	return orders,nil
 }
 
-func (os orderService) UpdateStatus(ctx context.Context, Order models.GetOrder) (string, error) {
+// UpdateStatus moves the order along the lifecycle in orderTransitions on behalf of the actor.
+func (os orderService) UpdateStatus(ctx context.Context, req models.UpdateOrderStatus, actor models.AuthInfo) (string, error) {
+	order, err := os.storage.Order().GetByID(ctx, req.Id)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while getting order for status update", logger.Error(err))
+		return "", err
+	}
+
+	if actor.UserRole == config.CUSTOMER_ROLE && order.CustomerId != actor.UserID {
+		return "", ErrStatusTransitionForbidden
+	}
+
+	if err = checkStatusTransition(order.Status, req.Status, actor.UserRole); err != nil {
+		return "", err
+	}
 
-	pKey, err := os.storage.Order().UpdateOrderStatus(ctx, Order)
+	// canceling refunds by the cancellation policy
+	if req.Status == config.STATUS_CANCELED {
+		if _, err = os.Cancel(ctx, models.CancelOrder{OrderId: order.Id}, actor); err != nil {
+			return "", err
+		}
+		return order.Id, nil
+	}
+
+	change := models.OrderStatusChange{
+		OrderId:    order.Id,
+		FromStatus: order.Status,
+		ToStatus:   req.Status,
+		ActorId:    actor.UserID,
+		ActorRole:  actor.UserRole,
+	}
+	if req.Status == config.STATUS_FINISHED {
+		if err = os.returnOrder(ctx, order, req.ActualReturnAt, &change); err != nil {
+			return "", err
+		}
+		// the customer earns points for what was paid for the order so far
+		change.Loyalty = models.LoyaltyEntry{
+			CustomerId: order.CustomerId,
+			OrderId:    order.Id,
+			Kind:       config.LOYALTY_EARN,
+			Points:     earnedPoints(order.Balance.PaidAmount),
+			ExpiresAt:  time.Now().Add(os.loyaltyPointsExpiry).Format(time.RFC3339),
+		}
+	} else if req.ActualReturnAt != "" {
+		return "", errs.InvalidField("actual_return_at", errors.New("is only accepted when the order is finished"))
+	}
+
+	pKey, err := os.storage.Order().UpdateOrderStatus(ctx, change)
 	if err != nil {
 		os.logger.Error("ERROR in service layer while updating Order", logger.Error(err))
 		return "", err
@@ -77,3 +264,33 @@ func (os orderService) UpdateStatus(ctx context.Context, Order models.GetOrder)
 
 	return pKey, nil
 }
+
+// returnOrder records when the order came back, now when actualReturnAt is empty, and charges
+// the late days up to then that the late return job did not charge yet.
+func (os orderService) returnOrder(ctx context.Context, order models.OrderAll, actualReturnAt string, change *models.OrderStatusChange) error {
+	returnedAt := time.Now()
+	if actualReturnAt != "" {
+		parsed, err := time.Parse(time.RFC3339, actualReturnAt)
+		if err != nil {
+			return errs.InvalidField("actual_return_at", err)
+		}
+		returnedAt = parsed
+	}
+	if from, err := time.Parse(time.DateOnly, order.FromDate); err == nil && returnedAt.Before(from) {
+		return errs.InvalidField("actual_return_at", errors.New("can not be before from_date"))
+	}
+
+	fee, err := os.lateReturns.lateFee(ctx, order.Id, order.CarId, order.ToDate, order.LateDays, returnedAt)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while pricing late fee", logger.Error(err))
+		return err
+	}
+	change.ActualReturnAt = returnedAt.Format(time.RFC3339)
+	change.LateFee = fee
+	return nil
+}
+
+// addDiscount adds the discount line item to the order items and takes it off amount.
+func addDiscount(items []models.OrderLineItem, amount float32, item models.OrderLineItem) ([]models.OrderLineItem, float32) {
+	return append(items, item), roundPrice(float64(amount) + float64(item.Amount))
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"fmt"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+)
+
+var (
+	ErrInvalidStatusTransition   = errs.Conflict("invalid_status_transition", "order status transition is not allowed")
+	ErrStatusTransitionForbidden = errs.Forbidden("status_transition_forbidden", "role is not allowed to perform the order status transition")
+	ErrOrderNotEditable          = errs.Conflict("order_not_editable", "only new orders can be edited")
+)
+
+type statusTransition struct {
+	to    string
+	roles []string
+}
+
+// orderTransitions is the order lifecycle: every status lists the statuses it may move to
+// and the roles allowed to perform each move. finished and canceled are final.
+var orderTransitions = map[string][]statusTransition{
+	config.STATUS_NEW: {
+		{to: config.STATUS_IN_PROCESS, roles: config.STAFF_ROLES},
+		{to: config.STATUS_CANCELED, roles: append([]string{config.CUSTOMER_ROLE}, config.STAFF_ROLES...)},
+	},
+	config.STATUS_IN_PROCESS: {
+		{to: config.STATUS_FINISHED, roles: config.STAFF_ROLES},
+		{to: config.STATUS_CANCELED, roles: []string{config.ADMIN_ROLE, config.MANAGER_ROLE}},
+	},
+}
+
+func checkStatusTransition(from, to, role string) error {
+	for _, transition := range orderTransitions[from] {
+		if transition.to != to {
+			continue
+		}
+		for _, allowed := range transition.roles {
+			if allowed == role {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s can not move order from %s to %s", ErrStatusTransitionForbidden, role, from, to)
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"rent-car/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	assert.NoError(t, checkStatusTransition(config.STATUS_NEW, config.STATUS_IN_PROCESS, config.AGENT_ROLE))
+	assert.NoError(t, checkStatusTransition(config.STATUS_IN_PROCESS, config.STATUS_FINISHED, config.ADMIN_ROLE))
+	assert.NoError(t, checkStatusTransition(config.STATUS_NEW, config.STATUS_CANCELED, config.CUSTOMER_ROLE))
+
+	assert.ErrorIs(t, checkStatusTransition(config.STATUS_NEW, config.STATUS_FINISHED, config.ADMIN_ROLE), ErrInvalidStatusTransition)
+	assert.ErrorIs(t, checkStatusTransition(config.STATUS_FINISHED, config.STATUS_NEW, config.ADMIN_ROLE), ErrInvalidStatusTransition)
+	assert.ErrorIs(t, checkStatusTransition(config.STATUS_NEW, config.STATUS_IN_PROCESS, config.CUSTOMER_ROLE), ErrStatusTransitionForbidden)
+	assert.ErrorIs(t, checkStatusTransition(config.STATUS_IN_PROCESS, config.STATUS_CANCELED, config.AGENT_ROLE), ErrStatusTransitionForbidden)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/logger"
+	"rent-car/pkg/notifier"
+	"rent-car/storage"
+	"time"
+)
+
+type outboxService struct {
+	storage  storage.IStorage
+	logger   logger.ILogger
+	notifier notifier.Channels
+}
+
+func NewOutboxService(storage storage.IStorage, logger logger.ILogger, notifier notifier.Channels) outboxService {
+	return outboxService{
+		storage:  storage,
+		logger:   logger,
+		notifier: notifier,
+	}
+}
+
+// Run delivers pending outbox events until ctx is canceled.
+func (os outboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(config.OutboxPollInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		// a full batch means more events may be due, so only wait after a partial one
+		if os.Dispatch(ctx) == config.OutboxBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch claims one batch of due events, delivers them and returns how many were claimed.
+func (os outboxService) Dispatch(ctx context.Context) int {
+	events, err := os.storage.Outbox().ClaimPending(ctx, config.OutboxBatchSize, config.OutboxLease)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while claiming outbox events", logger.Error(err))
+		return 0
+	}
+
+	for _, event := range events {
+		os.deliver(ctx, event)
+	}
+	return len(events)
+}
+
+// deliver sends the event to the channels that did not take it yet, a failed attempt keeps the channels
+// that took it, so the retry only goes to the failed ones.
+func (os outboxService) deliver(ctx context.Context, event models.OutboxEvent) {
+	notifyCtx, cancel := context.WithTimeout(ctx, config.NotifyTimeout)
+	delivered, err := os.notifier.Notify(notifyCtx, event.Payload, event.DeliveredChannels)
+	cancel()
+
+	if err == nil {
+		if err = os.storage.Outbox().MarkSent(ctx, event.Id, delivered); err != nil {
+			os.logger.Error("ERROR in service layer while marking outbox event sent", logger.String("id", event.Id), logger.Error(err))
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	failure := models.OutboxFailure{
+		Id:        event.Id,
+		Error:     err.Error(),
+		Delivered: delivered,
+		RetryIn:   outboxBackoff(attempts).Seconds(),
+		Dead:      attempts >= config.OutboxMaxAttempts,
+	}
+
+	if failure.Dead {
+		os.logger.Error("outbox event is dead-lettered", logger.String("id", event.Id), logger.Int("attempts", attempts), logger.Error(err))
+	} else {
+		os.logger.Warning("outbox event delivery failed", logger.String("id", event.Id), logger.Int("attempts", attempts), logger.Error(err))
+	}
+
+	if err = os.storage.Outbox().MarkFailed(ctx, failure); err != nil {
+		os.logger.Error("ERROR in service layer while marking outbox event failed", logger.String("id", event.Id), logger.Error(err))
+	}
+}
+
+// outboxBackoff is the delay before the next delivery after the given number of failed attempts.
+func outboxBackoff(attempts int) time.Duration {
+	delay := config.OutboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= config.OutboxMaxBackoff {
+			return config.OutboxMaxBackoff
+		}
+	}
+	return delay
+}
+
+func (os outboxService) GetList(ctx context.Context, req models.GetOutboxEventsRequest) (models.GetOutboxEventsResponse, error) {
+	events, err := os.storage.Outbox().GetList(ctx, req)
+	if err != nil {
+		os.logger.Error("ERROR in service layer while getting outbox events", logger.Error(err))
+		return events, err
+	}
+	return events, nil
+}
+
+func (os outboxService) Replay(ctx context.Context, id string) error {
+	if err := os.storage.Outbox().Replay(ctx, id); err != nil {
+		os.logger.Error("ERROR in service layer while replaying outbox event", logger.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"rent-car/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, config.OutboxBaseBackoff, outboxBackoff(1))
+	assert.Equal(t, 2*config.OutboxBaseBackoff, outboxBackoff(2))
+	assert.Equal(t, 8*config.OutboxBaseBackoff, outboxBackoff(4))
+	assert.Equal(t, config.OutboxMaxBackoff, outboxBackoff(config.OutboxMaxAttempts))
+	assert.Equal(t, config.OutboxMaxBackoff, outboxBackoff(100))
+}
+
+func TestOutboxBackoffGrows(t *testing.T) {
+	prev := time.Duration(0)
+	for attempt := 1; attempt <= config.OutboxMaxAttempts; attempt++ {
+		delay := outboxBackoff(attempt)
+		assert.GreaterOrEqual(t, delay, prev)
+		prev = delay
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/pkg/payment"
+	"rent-car/storage"
+)
+
+var (
+	// ErrPaymentForbidden is returned when a customer records anything but a card payment or deposit.
+	ErrPaymentForbidden = errs.Forbidden("payment_forbidden", "customers can only pay by card")
+	// ErrPaymentExceedsBalance is returned for a payment above the outstanding amount of the order.
+	ErrPaymentExceedsBalance = errs.Conflict("payment_exceeds_balance", "payment is more than the outstanding amount")
+	// ErrRefundExceedsPaid is returned for a refund above the paid amount of the order.
+	ErrRefundExceedsPaid = errs.Conflict("refund_exceeds_paid", "refund is more than the paid amount")
+	// ErrOrderCanceled is returned when paying for a canceled order, it only takes refunds.
+	ErrOrderCanceled = errs.Conflict("order_canceled", "canceled orders only accept refunds")
+	// ErrPaymentDeclined is returned when the payment provider refuses a card payment or refund.
+	ErrPaymentDeclined = errs.Conflict("payment_declined", "payment provider declined the request")
+	// ErrOrderNotReturned is returned when settling the deposit of an order that is not finished or canceled.
+	ErrOrderNotReturned = errs.Conflict("order_not_returned", "deposit is settled once the order is finished or canceled")
+)
+
+type paymentService struct {
+	storage  storage.IStorage
+	logger   logger.ILogger
+	provider payment.Provider
+}
+
+func NewPaymentService(storage storage.IStorage, logger logger.ILogger, provider payment.Provider) paymentService {
+	return paymentService{
+		storage:  storage,
+		logger:   logger,
+		provider: provider,
+	}
+}
+
+// Record adds a payment, deposit or refund to the ledger of the order. Card money is written as a pending
+// entry first, so the order lock and the balance check come before the payment provider is called, and the
+// entry is confirmed once the provider moved the money.
+func (ps paymentService) Record(ctx context.Context, req models.RecordPayment, actor models.AuthInfo) (string, error) {
+	if actor.UserRole == config.CUSTOMER_ROLE &&
+		(req.Method != config.PAYMENT_METHOD_CARD || req.Kind == config.PAYMENT_KIND_REFUND) {
+		return "", ErrPaymentForbidden
+	}
+
+	order, err := ps.storage.Order().GetByID(ctx, req.OrderId)
+	if err != nil {
+		return "", err
+	}
+	if err = checkPayment(order.Status, order.Balance, req); err != nil {
+		return "", err
+	}
+
+	req.Status = config.PAYMENT_CONFIRMED
+	if req.Method == config.PAYMENT_METHOD_CARD {
+		req.Status = config.PAYMENT_PENDING
+	}
+	req.ActorId = actor.UserID
+	req.ActorRole = actor.UserRole
+	id, err := ps.storage.Payment().Record(ctx, req)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while recording payment", logger.Error(err), logger.String("order_id", req.OrderId))
+		return "", err
+	}
+
+	if req.Status == config.PAYMENT_PENDING {
+		if err = ps.move(ctx, id, req); err != nil {
+			return "", err
+		}
+	}
+	ps.logger.Info("payment recorded",
+		logger.String("order_id", req.OrderId),
+		logger.String("kind", req.Kind),
+		logger.String("method", req.Method),
+		logger.Any("amount", req.Amount))
+	return id, nil
+}
+
+// move moves the money of the pending ledger entry id at the payment provider and confirms the entry,
+// an entry the provider declines is failed. Once the provider moved the money the request deadline no
+// longer applies. A charge whose confirmation can't be written is refunded and failed, a refund can't be
+// taken back, its entry stays pending and is reconciled by hand with the logged reference.
+func (ps paymentService) move(ctx context.Context, id string, req models.RecordPayment) error {
+	providerReq := payment.Request{OrderId: req.OrderId, Amount: req.Amount}
+	charge := req.Kind == config.PAYMENT_KIND_PAYMENT || req.Kind == config.PAYMENT_KIND_DEPOSIT
+
+	var (
+		ref string
+		err error
+	)
+	if charge {
+		ref, err = ps.provider.Charge(ctx, providerReq)
+	} else {
+		ref, err = ps.provider.Refund(ctx, providerReq)
+	}
+	if err != nil {
+		ps.fail(ctx, id)
+		return ErrPaymentDeclined.Wrap(err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if err = ps.storage.Payment().Confirm(ctx, id, ref); err != nil {
+		ps.logger.Error("ERROR in service layer while confirming payment",
+			logger.Error(err),
+			logger.String("payment_id", id),
+			logger.String("order_id", req.OrderId),
+			logger.String("provider_ref", ref))
+		if !charge {
+			return err
+		}
+		if _, refundErr := ps.provider.Refund(ctx, providerReq); refundErr != nil {
+			ps.logger.Error("ERROR in service layer while refunding unconfirmed charge",
+				logger.Error(refundErr),
+				logger.String("payment_id", id),
+				logger.String("provider_ref", ref))
+			return err
+		}
+		ps.fail(ctx, id)
+		return err
+	}
+	return nil
+}
+
+// fail marks a pending ledger entry whose money did not move, an entry left pending keeps its amount reserved.
+func (ps paymentService) fail(ctx context.Context, id string) {
+	if err := ps.storage.Payment().Fail(context.WithoutCancel(ctx), id); err != nil {
+		ps.logger.Error("ERROR in service layer while failing payment", logger.Error(err), logger.String("payment_id", id))
+	}
+}
+
+func (ps paymentService) GetByOrderID(ctx context.Context, orderID string) (models.GetPaymentsResponse, error) {
+	balance, err := ps.storage.Payment().GetBalance(ctx, orderID)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while getting order balance", logger.Error(err))
+		return models.GetPaymentsResponse{}, err
+	}
+
+	payments, err := ps.storage.Payment().GetByOrderID(ctx, orderID)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while getting payments", logger.Error(err))
+		return models.GetPaymentsResponse{}, err
+	}
+	return models.GetPaymentsResponse{Payments: payments, Balance: balance}, nil
+}
+
+// checkPayment refuses payments above the outstanding amount and refunds above the paid amount,
+// deposits are not limited by the order amount.
+func checkPayment(status string, balance models.OrderBalance, req models.RecordPayment) error {
+	switch req.Kind {
+	case config.PAYMENT_KIND_PAYMENT:
+		if status == config.STATUS_CANCELED {
+			return ErrOrderCanceled
+		}
+		if req.Amount > balance.Outstanding {
+			return fmt.Errorf("%w: %.2f outstanding", ErrPaymentExceedsBalance, balance.Outstanding)
+		}
+	case config.PAYMENT_KIND_DEPOSIT:
+		if status == config.STATUS_CANCELED {
+			return ErrOrderCanceled
+		}
+	case config.PAYMENT_KIND_REFUND:
+		if req.Amount > balance.PaidAmount {
+			return fmt.Errorf("%w: %.2f paid", ErrRefundExceedsPaid, balance.PaidAmount)
+		}
+	}
+	return nil
+}
+
+// SettleDeposit adds the charges to a returned order, captures the held deposit against what the customer
+// still owes and releases the rest. The deposit is claimed under the order lock first, a released card deposit
+// is then refunded through the payment provider, and a declined refund reopens the deposit for what is still held.
+func (ps paymentService) SettleDeposit(ctx context.Context, req models.SettleDeposit, actor models.AuthInfo) (models.OrderDeposit, error) {
+	order, err := ps.storage.Order().GetByID(ctx, req.OrderId)
+	if err != nil {
+		return models.OrderDeposit{}, err
+	}
+	if order.Status != config.STATUS_FINISHED && order.Status != config.STATUS_CANCELED {
+		return models.OrderDeposit{}, ErrOrderNotReturned
+	}
+	if order.Deposit.Status != config.DEPOSIT_OPEN && order.Deposit.Status != config.DEPOSIT_HELD {
+		return models.OrderDeposit{}, storage.ErrDepositSettled
+	}
+
+	req.Capture, req.Release = planSettlement(order.Status, order.Balance, req.Charges)
+	req.Method = order.Deposit.Method
+	req.ActorId = actor.UserID
+	req.ActorRole = actor.UserRole
+	releaseID, err := ps.storage.Deposit().Settle(ctx, req)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while settling deposit", logger.Error(err), logger.String("order_id", req.OrderId))
+		return models.OrderDeposit{}, err
+	}
+
+	if releaseID != "" {
+		release := models.RecordPayment{
+			OrderId: req.OrderId,
+			Kind:    config.PAYMENT_KIND_DEPOSIT_RELEASE,
+			Amount:  req.Release,
+		}
+		if err = ps.move(ctx, releaseID, release); err != nil {
+			return models.OrderDeposit{}, err
+		}
+	}
+	ps.logger.Info("deposit settled",
+		logger.String("order_id", req.OrderId),
+		logger.Any("captured", req.Capture),
+		logger.Any("released", req.Release))
+
+	return ps.storage.Deposit().GetByOrderID(ctx, req.OrderId)
+}
+
+func (ps paymentService) Report(ctx context.Context, req models.FinanceReportRequest) (models.FinanceReport, error) {
+	report, err := ps.storage.Payment().Report(ctx, req)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while building finance report", logger.Error(err))
+		return report, err
+	}
+	return report, nil
+}
+
+// planSettlement splits the held deposit: what the customer owes after the charges is captured,
+// the rest is released. The rent of a canceled order is not owed, only its charges are captured.
+func planSettlement(status string, balance models.OrderBalance, charges []models.OrderCharge) (capture, release float32) {
+	owed := balance.Outstanding
+	if status == config.STATUS_CANCELED {
+		owed = 0
+	}
+	for _, charge := range charges {
+		owed += charge.Amount
+	}
+
+	capture = float32(math.Min(float64(balance.DepositHeld), float64(owed)))
+	capture = float32(math.Round(float64(capture)*100) / 100)
+	release = float32(math.Round(float64(balance.DepositHeld-capture)*100) / 100)
+	return capture, release
+}
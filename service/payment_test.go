@@ -0,0 +1,60 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPayment(t *testing.T) {
+	balance := models.OrderBalance{Amount: 300, PaidAmount: 100, Outstanding: 200}
+
+	payment := func(kind string, amount float32) models.RecordPayment {
+		return models.RecordPayment{Kind: kind, Method: config.PAYMENT_METHOD_CASH, Amount: amount}
+	}
+
+	assert.NoError(t, checkPayment(config.STATUS_NEW, balance, payment(config.PAYMENT_KIND_PAYMENT, 200)))
+	assert.ErrorIs(t, checkPayment(config.STATUS_NEW, balance, payment(config.PAYMENT_KIND_PAYMENT, 200.5)), ErrPaymentExceedsBalance)
+
+	assert.NoError(t, checkPayment(config.STATUS_NEW, balance, payment(config.PAYMENT_KIND_REFUND, 100)))
+	assert.ErrorIs(t, checkPayment(config.STATUS_NEW, balance, payment(config.PAYMENT_KIND_REFUND, 101)), ErrRefundExceedsPaid)
+
+	// deposits are held apart from the order amount
+	assert.NoError(t, checkPayment(config.STATUS_NEW, balance, payment(config.PAYMENT_KIND_DEPOSIT, 500)))
+
+	// canceled orders only give money back
+	assert.ErrorIs(t, checkPayment(config.STATUS_CANCELED, balance, payment(config.PAYMENT_KIND_PAYMENT, 10)), ErrOrderCanceled)
+	assert.ErrorIs(t, checkPayment(config.STATUS_CANCELED, balance, payment(config.PAYMENT_KIND_DEPOSIT, 10)), ErrOrderCanceled)
+	assert.NoError(t, checkPayment(config.STATUS_CANCELED, balance, payment(config.PAYMENT_KIND_REFUND, 50)))
+}
+
+func TestPlanSettlement(t *testing.T) {
+	// nothing owed, the whole deposit goes back
+	capture, release := planSettlement(config.STATUS_FINISHED, models.OrderBalance{Amount: 300, PaidAmount: 300, DepositHeld: 200}, nil)
+	assert.Equal(t, float32(0), capture)
+	assert.Equal(t, float32(200), release)
+
+	// damage is captured, the rest released
+	capture, release = planSettlement(config.STATUS_FINISHED, models.OrderBalance{Amount: 300, PaidAmount: 300, DepositHeld: 200},
+		[]models.OrderCharge{{Kind: config.LINE_ITEM_DAMAGE, Amount: 80.5}})
+	assert.Equal(t, float32(80.5), capture)
+	assert.Equal(t, float32(119.5), release)
+
+	// the customer owes more than is held
+	capture, release = planSettlement(config.STATUS_FINISHED, models.OrderBalance{Amount: 300, PaidAmount: 250, Outstanding: 50, DepositHeld: 100},
+		[]models.OrderCharge{{Kind: config.LINE_ITEM_LATE_FEE, Amount: 70}})
+	assert.Equal(t, float32(100), capture)
+	assert.Equal(t, float32(0), release)
+
+	// a canceled order still shows its rent as outstanding, only the charges are captured
+	capture, release = planSettlement(config.STATUS_CANCELED, models.OrderBalance{Amount: 300, PaidAmount: 150, Outstanding: 150, DepositHeld: 200}, nil)
+	assert.Equal(t, float32(0), capture)
+	assert.Equal(t, float32(200), release)
+
+	capture, release = planSettlement(config.STATUS_CANCELED, models.OrderBalance{Amount: 300, Outstanding: 300, DepositHeld: 200},
+		[]models.OrderCharge{{Kind: config.LINE_ITEM_DAMAGE, Amount: 40}})
+	assert.Equal(t, float32(40), capture)
+	assert.Equal(t, float32(160), release)
+}
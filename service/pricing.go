@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"time"
+)
+
+var (
+	ErrCarHasNoRate     = errs.Validation("car_has_no_rate", "car has no daily rate", errs.FieldError{Field: "car_id", Message: "car has no daily rate"})
+	ErrInvalidDateRange = errs.Validation("invalid_date_range", "to_date must be after from_date", errs.FieldError{Field: "to_date", Message: "must be after from_date"})
+)
+
+type pricingService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewPricingService(storage storage.IStorage, logger logger.ILogger) pricingService {
+	return pricingService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Quote prices renting the car for every day in [fromDate, toDate).
+func (p pricingService) Quote(ctx context.Context, carID, fromDate, toDate string) (models.PriceQuote, error) {
+	car, err := p.storage.Car().GetByID(ctx, carID)
+	if err != nil {
+		p.logger.Error("ERROR in service layer while getting car for pricing", logger.Error(err))
+		return models.PriceQuote{}, err
+	}
+
+	quote, err := calculatePrice(car, fromDate, toDate)
+	if err != nil {
+		p.logger.Error("ERROR in service layer while calculating price", logger.Error(err))
+		return models.PriceQuote{}, err
+	}
+	return quote, nil
+}
+
+// calculatePrice picks a rate for each rental day, a seasonal rate wins over the weekend rate
+// and the weekend rate wins over the base daily rate, then applies the long rental discount.
+// The deposit is taken on top of the price and depends on the category of the car.
+func calculatePrice(car models.Car, fromDate, toDate string) (models.PriceQuote, error) {
+	from, err := time.Parse(time.DateOnly, fromDate)
+	if err != nil {
+		return models.PriceQuote{}, errs.InvalidField("from_date", err)
+	}
+	to, err := time.Parse(time.DateOnly, toDate)
+	if err != nil {
+		return models.PriceQuote{}, errs.InvalidField("to_date", err)
+	}
+	if !to.After(from) {
+		return models.PriceQuote{}, ErrInvalidDateRange
+	}
+	if car.DailyRate <= 0 {
+		return models.PriceQuote{}, ErrCarHasNoRate
+	}
+
+	quote := models.PriceQuote{
+		CarId:    car.Id,
+		FromDate: fromDate,
+		ToDate:   toDate,
+		Deposit:  config.CATEGORY_DEPOSITS[car.Category],
+	}
+
+	var (
+		items    = []models.OrderLineItem{}
+		index    = map[string]int{}
+		subtotal float64
+	)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		item := dailyRate(car, day)
+
+		key := fmt.Sprintf("%s|%s|%v", item.Kind, item.Description, item.UnitPrice)
+		i, ok := index[key]
+		if !ok {
+			i = len(items)
+			index[key] = i
+			items = append(items, item)
+		}
+		items[i].Quantity++
+
+		quote.Days++
+		subtotal += float64(item.UnitPrice)
+	}
+
+	for i := range items {
+		items[i].Amount = roundPrice(float64(items[i].UnitPrice) * float64(items[i].Quantity))
+	}
+
+	for _, tier := range config.LONG_RENTAL_DISCOUNTS {
+		if quote.Days < tier.MinDays {
+			continue
+		}
+		discount := roundPrice(subtotal * float64(tier.Percent) / 100)
+		items = append(items, models.OrderLineItem{
+			Kind:        config.LINE_ITEM_DISCOUNT,
+			Description: fmt.Sprintf("Long rental discount %v%% (%d+ days)", tier.Percent, tier.MinDays),
+			Quantity:    1,
+			UnitPrice:   -discount,
+			Amount:      -discount,
+		})
+		break
+	}
+
+	var total float64
+	for _, item := range items {
+		total += float64(item.Amount)
+	}
+
+	quote.LineItems = items
+	quote.Amount = roundPrice(total)
+	return quote, nil
+}
+
+func dailyRate(car models.Car, day time.Time) models.OrderLineItem {
+	for _, season := range car.SeasonalRates {
+		seasonFrom, errFrom := time.Parse(time.DateOnly, season.FromDate)
+		seasonTo, errTo := time.Parse(time.DateOnly, season.ToDate)
+		if errFrom != nil || errTo != nil {
+			continue
+		}
+		if !day.Before(seasonFrom) && day.Before(seasonTo) {
+			return models.OrderLineItem{
+				Kind:        config.LINE_ITEM_SEASONAL,
+				Description: "Seasonal rate: " + season.Name,
+				UnitPrice:   season.DailyRate,
+			}
+		}
+	}
+
+	if weekday := day.Weekday(); car.WeekendRate > 0 && (weekday == time.Saturday || weekday == time.Sunday) {
+		return models.OrderLineItem{
+			Kind:        config.LINE_ITEM_WEEKEND,
+			Description: "Weekend rate",
+			UnitPrice:   car.WeekendRate,
+		}
+	}
+
+	return models.OrderLineItem{
+		Kind:        config.LINE_ITEM_BASE,
+		Description: "Daily rate",
+		UnitPrice:   car.DailyRate,
+	}
+}
+
+func roundPrice(value float64) float32 {
+	return float32(math.Round(value*100) / 100)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculatePrice(t *testing.T) {
+	car := models.Car{
+		Id:          "cf180a59-0594-4da3-9d6c-2d79ef3c7eaf",
+		DailyRate:   50,
+		WeekendRate: 70,
+	}
+
+	// 2030-04-05 is a Friday: Fri, Sat, Sun, Mon
+	quote, err := calculatePrice(car, "2030-04-05", "2030-04-09")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 4, quote.Days)
+		assert.Equal(t, float32(2*50+2*70), quote.Amount)
+		assert.Len(t, quote.LineItems, 2)
+	}
+}
+
+func TestCalculatePriceSeasonAndDiscount(t *testing.T) {
+	car := models.Car{
+		DailyRate:   50,
+		WeekendRate: 70,
+		SeasonalRates: []models.SeasonalRate{
+			{Name: "Summer", FromDate: "2030-07-01", ToDate: "2030-09-01", DailyRate: 80},
+		},
+	}
+
+	quote, err := calculatePrice(car, "2030-07-01", "2030-07-08")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 7, quote.Days)
+
+		discount := quote.LineItems[len(quote.LineItems)-1]
+		assert.Equal(t, config.LINE_ITEM_DISCOUNT, discount.Kind)
+		assert.Equal(t, float32(-28), discount.Amount)
+		assert.Equal(t, float32(7*80-28), quote.Amount)
+	}
+}
+
+func TestCalculatePriceWithoutRate(t *testing.T) {
+	_, err := calculatePrice(models.Car{}, "2030-07-01", "2030-07-08")
+	assert.ErrorIs(t, err, ErrCarHasNoRate)
+
+	_, err = calculatePrice(models.Car{DailyRate: 10}, "2030-07-08", "2030-07-01")
+	assert.Error(t, err)
+}
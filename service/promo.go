@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPromoCodeNotFound is returned when an order is booked with a code that does not exist.
+	ErrPromoCodeNotFound = errs.Validation("promo_code_not_found", "promo code does not exist",
+		errs.FieldError{Field: "promo_code", Message: "does not exist"})
+	// ErrPromoCodeNotValid is returned when the code is deactivated or today is outside its validity window.
+	ErrPromoCodeNotValid = errs.Validation("promo_code_not_valid", "promo code is not valid today",
+		errs.FieldError{Field: "promo_code", Message: "is not valid today"})
+	// ErrPromoCodeNotApplicable is returned when the rental is too short or the car brand is not eligible.
+	ErrPromoCodeNotApplicable = errs.Validation("promo_code_not_applicable", "promo code does not apply to this rental",
+		errs.FieldError{Field: "promo_code", Message: "does not apply to this rental"})
+	ErrInvalidPromoValue = errs.Validation("invalid_promo_value", "a percent discount can not exceed 100",
+		errs.FieldError{Field: "value", Message: "can not exceed 100 for a percent discount"})
+)
+
+type promoService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewPromoService(storage storage.IStorage, logger logger.ILogger) promoService {
+	return promoService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Create adds a promo code, codes are stored upper case and redeemed case insensitively.
+func (ps promoService) Create(ctx context.Context, req models.CreatePromoCode) (string, error) {
+	if req.Kind == config.PROMO_KIND_PERCENT && req.Value > 100 {
+		return "", ErrInvalidPromoValue
+	}
+	req.Code = strings.ToUpper(req.Code)
+
+	id, err := ps.storage.Promo().Create(ctx, req)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while creating promo code", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (ps promoService) GetByID(ctx context.Context, id string) (models.PromoCode, error) {
+	promo, err := ps.storage.Promo().GetByID(ctx, id)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while getting promo code", logger.Error(err))
+		return models.PromoCode{}, err
+	}
+	return promo, nil
+}
+
+func (ps promoService) GetList(ctx context.Context, req models.GetPromoCodesRequest) (models.GetPromoCodesResponse, error) {
+	promos, err := ps.storage.Promo().GetList(ctx, req)
+	if err != nil {
+		ps.logger.Error("ERROR in service layer while getting promo codes", logger.Error(err))
+		return promos, err
+	}
+	return promos, nil
+}
+
+// Update changes the terms of a promo code, orders that redeemed it keep their discount.
+func (ps promoService) Update(ctx context.Context, req models.UpdatePromoCode) error {
+	if req.Kind == config.PROMO_KIND_PERCENT && req.Value > 100 {
+		return ErrInvalidPromoValue
+	}
+	if err := ps.storage.Promo().Update(ctx, req); err != nil {
+		ps.logger.Error("ERROR in service layer while updating promo code", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (ps promoService) Delete(ctx context.Context, id string) error {
+	if err := ps.storage.Promo().Delete(ctx, id); err != nil {
+		ps.logger.Error("ERROR in service layer while deleting promo code", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// redeem checks the customer may book the quoted rental of the car with code today and returns the
+// redemption with its discount line item. The usage limits are checked again when the order is stored.
+func (ps promoService) redeem(ctx context.Context, code, customerID, carID string, quote models.PriceQuote) (models.PromoRedemption, models.OrderLineItem, error) {
+	promo, err := ps.storage.Promo().GetByCode(ctx, code)
+	if errs.KindOf(err) == errs.KindNotFound {
+		return models.PromoRedemption{}, models.OrderLineItem{}, ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return models.PromoRedemption{}, models.OrderLineItem{}, err
+	}
+
+	usage, err := ps.storage.Promo().GetUsage(ctx, promo.Id, customerID)
+	if err != nil {
+		return models.PromoRedemption{}, models.OrderLineItem{}, err
+	}
+	if err = checkPromoCode(promo, usage, time.Now()); err != nil {
+		return models.PromoRedemption{}, models.OrderLineItem{}, err
+	}
+
+	car, err := ps.storage.Car().GetByID(ctx, carID)
+	if err != nil {
+		return models.PromoRedemption{}, models.OrderLineItem{}, err
+	}
+	item, err := promoDiscount(promo, car.Brand, quote)
+	if err != nil {
+		return models.PromoRedemption{}, models.OrderLineItem{}, err
+	}
+
+	return models.PromoRedemption{
+		PromoCodeId: promo.Id,
+		CustomerId:  customerID,
+		Discount:    -item.Amount,
+	}, item, nil
+}
+
+// reprice gives the discount of the code an order was booked with for its new quote, the validity window
+// and usage limits were checked when it was redeemed.
+func (ps promoService) reprice(ctx context.Context, code, carID string, quote models.PriceQuote) (models.OrderLineItem, error) {
+	promo, err := ps.storage.Promo().GetByCode(ctx, code)
+	if err != nil {
+		return models.OrderLineItem{}, err
+	}
+	car, err := ps.storage.Car().GetByID(ctx, carID)
+	if err != nil {
+		return models.OrderLineItem{}, err
+	}
+	return promoDiscount(promo, car.Brand, quote)
+}
+
+// checkPromoCode refuses a code that is deactivated, outside [valid_from, valid_to) at the given time or
+// reached the limit of uses in total or by the customer, a zero limit is unlimited.
+func checkPromoCode(promo models.PromoCode, usage models.PromoUsage, at time.Time) error {
+	from, err := time.Parse(time.DateOnly, promo.ValidFrom)
+	if err != nil {
+		return err
+	}
+	to, err := time.Parse(time.DateOnly, promo.ValidTo)
+	if err != nil {
+		return err
+	}
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	if !promo.Active || day.Before(from) || !day.Before(to) {
+		return ErrPromoCodeNotValid
+	}
+
+	if (promo.MaxUses > 0 && usage.Uses >= promo.MaxUses) ||
+		(promo.MaxUsesPerCustomer > 0 && usage.CustomerUses >= promo.MaxUsesPerCustomer) {
+		return storage.ErrPromoCodeUsedUp
+	}
+	return nil
+}
+
+// promoDiscount is the discount line item of the code on the quoted rental of a car of brand.
+// A percent discount applies to the quoted amount, a fixed one is capped at it.
+func promoDiscount(promo models.PromoCode, brand string, quote models.PriceQuote) (models.OrderLineItem, error) {
+	if quote.Days < promo.MinDays || !eligibleBrand(promo.Brands, brand) {
+		return models.OrderLineItem{}, ErrPromoCodeNotApplicable
+	}
+
+	var discount float32
+	switch promo.Kind {
+	case config.PROMO_KIND_PERCENT:
+		discount = roundPrice(float64(quote.Amount) * float64(promo.Value) / 100)
+	case config.PROMO_KIND_FIXED:
+		discount = min(promo.Value, quote.Amount)
+	default:
+		return models.OrderLineItem{}, errors.New("unknown promo code kind " + promo.Kind)
+	}
+
+	return models.OrderLineItem{
+		Kind:        config.LINE_ITEM_PROMO,
+		Description: fmt.Sprintf("Promo code %s", promo.Code),
+		Quantity:    1,
+		UnitPrice:   -discount,
+		Amount:      -discount,
+	}, nil
+}
+
+// eligibleBrand reports whether brand is one of brands, every brand is eligible when brands is empty.
+func eligibleBrand(brands []string, brand string) bool {
+	if len(brands) == 0 {
+		return true
+	}
+	for _, eligible := range brands {
+		if strings.EqualFold(eligible, brand) {
+			return true
+		}
+	}
+	return false
+}
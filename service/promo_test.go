@@ -0,0 +1,67 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPromoCode(t *testing.T) {
+	promo := models.PromoCode{
+		Active:             true,
+		ValidFrom:          "2030-06-01",
+		ValidTo:            "2030-09-01",
+		MaxUses:            10,
+		MaxUsesPerCustomer: 1,
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	assert.NoError(t, checkPromoCode(promo, models.PromoUsage{}, at("2030-06-01T00:00:00Z")))
+	assert.NoError(t, checkPromoCode(promo, models.PromoUsage{Uses: 9}, at("2030-08-31T23:59:00Z")))
+	assert.ErrorIs(t, checkPromoCode(promo, models.PromoUsage{}, at("2030-05-31T23:59:00Z")), ErrPromoCodeNotValid)
+	assert.ErrorIs(t, checkPromoCode(promo, models.PromoUsage{}, at("2030-09-01T00:00:00Z")), ErrPromoCodeNotValid)
+	assert.ErrorIs(t, checkPromoCode(promo, models.PromoUsage{Uses: 10}, at("2030-07-01T00:00:00Z")), storage.ErrPromoCodeUsedUp)
+	assert.ErrorIs(t, checkPromoCode(promo, models.PromoUsage{Uses: 1, CustomerUses: 1}, at("2030-07-01T00:00:00Z")), storage.ErrPromoCodeUsedUp)
+
+	inactive := promo
+	inactive.Active = false
+	assert.ErrorIs(t, checkPromoCode(inactive, models.PromoUsage{}, at("2030-07-01T00:00:00Z")), ErrPromoCodeNotValid)
+
+	unlimited := promo
+	unlimited.MaxUses, unlimited.MaxUsesPerCustomer = 0, 0
+	assert.NoError(t, checkPromoCode(unlimited, models.PromoUsage{Uses: 100, CustomerUses: 5}, at("2030-07-01T00:00:00Z")))
+}
+
+func TestPromoDiscount(t *testing.T) {
+	quote := models.PriceQuote{Days: 5, Amount: 250}
+
+	percent := models.PromoCode{Code: "SUMMER", Kind: config.PROMO_KIND_PERCENT, Value: 10, MinDays: 3, Brands: []string{"BMW", "Audi"}}
+	item, err := promoDiscount(percent, "bmw", quote)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.LINE_ITEM_PROMO, item.Kind)
+		assert.Equal(t, float32(-25), item.Amount)
+		assert.Equal(t, "Promo code SUMMER", item.Description)
+	}
+
+	_, err = promoDiscount(percent, "Kia", quote)
+	assert.ErrorIs(t, err, ErrPromoCodeNotApplicable)
+	_, err = promoDiscount(percent, "BMW", models.PriceQuote{Days: 2, Amount: 100})
+	assert.ErrorIs(t, err, ErrPromoCodeNotApplicable)
+
+	// a fixed discount never exceeds the price
+	fixed := models.PromoCode{Kind: config.PROMO_KIND_FIXED, Value: 300}
+	item, err = promoDiscount(fixed, "Kia", quote)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float32(-250), item.Amount)
+	}
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"rent-car/config"
 	"rent-car/pkg/logger"
+	"rent-car/pkg/notifier"
+	"rent-car/pkg/payment"
 	"rent-car/storage"
 )
 
@@ -12,6 +15,14 @@ type IServiceManager interface {
     Customer() customerService
 	Order() orderService
 	Auth()  authService
+	Staff() staffService
+	Pricing() pricingService
+	Outbox() outboxService
+	Verification() verificationService
+	Payment() paymentService
+	LateReturn() lateReturnService
+	Promo() promoService
+	Loyalty() loyaltyService
 }
 
 type Service struct {
@@ -19,16 +30,32 @@ type Service struct {
 	customerService customerService
 	orderService orderService
     auth authService
+	staffService staffService
+	pricingService pricingService
+	outboxService outboxService
+	verificationService verificationService
+	paymentService paymentService
+	lateReturnService lateReturnService
+	promoService promoService
+	loyaltyService loyaltyService
 
 	logger logger.ILogger
 }
 
-func New(storage storage.IStorage,log logger.ILogger) Service  {
+func New(storage storage.IStorage,log logger.ILogger,notifier notifier.Channels,provider payment.Provider,cfg config.Config) Service  {
 	services := Service{}
 	services.carService = NewCarService(storage,log)
 	services.customerService = NewCustomerService(storage,log)
-	services.orderService = NewOrderService(storage,log)
+	services.orderService = NewOrderService(storage,log,provider,cfg)
 	services.auth = NewAuthService(storage,log)
+	services.staffService = NewStaffService(storage,log)
+	services.pricingService = NewPricingService(storage,log)
+	services.outboxService = NewOutboxService(storage,log,notifier)
+	services.verificationService = NewVerificationService(storage,log,cfg)
+	services.paymentService = NewPaymentService(storage,log,provider)
+	services.lateReturnService = NewLateReturnService(storage,log)
+	services.promoService = NewPromoService(storage,log)
+	services.loyaltyService = NewLoyaltyService(storage,log)
 	services.logger=log
 
 	return services
@@ -48,4 +75,36 @@ func (s Service) Order() orderService {
 
 func (s Service) Auth() authService {
 	return s.auth
-}
\ No newline at end of file
+}
+
+func (s Service) Staff() staffService {
+	return s.staffService
+}
+
+func (s Service) Pricing() pricingService {
+	return s.pricingService
+}
+
+func (s Service) Outbox() outboxService {
+	return s.outboxService
+}
+
+func (s Service) Verification() verificationService {
+	return s.verificationService
+}
+
+func (s Service) Payment() paymentService {
+	return s.paymentService
+}
+
+func (s Service) LateReturn() lateReturnService {
+	return s.lateReturnService
+}
+
+func (s Service) Promo() promoService {
+	return s.promoService
+}
+
+func (s Service) Loyalty() loyaltyService {
+	return s.loyaltyService
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/check"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/pkg/logger/password"
+	"rent-car/storage"
+)
+
+var ErrAdminExists = errs.Conflict("admin_exists", "admin account already exists")
+
+type staffService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewStaffService(storage storage.IStorage, logger logger.ILogger) staffService {
+	return staffService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s staffService) Create(ctx context.Context, staff models.CreateStaff) (string, error) {
+	if err := check.ValidateStaffRole(staff.Role); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := password.HashPassword(staff.Password)
+	if err != nil {
+		s.logger.Error("ERROR in service layer while hashing staff password", logger.Error(err))
+		return "", err
+	}
+	staff.Password = hashedPassword
+
+	pkey, err := s.storage.Staff().Create(ctx, staff)
+	if err != nil {
+		s.logger.Error("ERROR in service layer while creating staff", logger.Error(err))
+		return "", err
+	}
+	return pkey, nil
+}
+
+// CreateFirstAdmin bootstraps the very first admin account and refuses to run once an admin exists.
+func (s staffService) CreateFirstAdmin(ctx context.Context, staff models.CreateStaff) (string, error) {
+	exists, err := s.storage.Staff().HasRole(ctx, config.ADMIN_ROLE)
+	if err != nil {
+		s.logger.Error("ERROR in service layer while checking admin accounts", logger.Error(err))
+		return "", err
+	}
+	if exists {
+		return "", ErrAdminExists
+	}
+
+	staff.Role = config.ADMIN_ROLE
+	return s.Create(ctx, staff)
+}
+
+func (s staffService) GetByID(ctx context.Context, id string) (models.Staff, error) {
+	staff, err := s.storage.Staff().GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("ERROR in service layer while getting staff by id", logger.Error(err))
+		return models.Staff{}, err
+	}
+	return staff, nil
+}
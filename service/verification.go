@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"time"
+)
+
+var (
+	// ErrCustomerNotVerified is returned when a customer without an approved licence books a car.
+	ErrCustomerNotVerified = errs.Forbidden("customer_not_verified", "customer driver licence is not verified")
+	// ErrDriverTooYoung is returned when the customer is younger than config.Config.MinDriverAge on the first rental day.
+	ErrDriverTooYoung = errs.Forbidden("driver_too_young", "customer is too young to rent a car")
+	// ErrLicenceExpires is returned when the licence expires before the car is returned.
+	ErrLicenceExpires = errs.Forbidden("licence_expires", "driver licence expires before the end of the rental")
+)
+
+type verificationService struct {
+	storage      storage.IStorage
+	logger       logger.ILogger
+	minDriverAge int
+}
+
+func NewVerificationService(storage storage.IStorage, logger logger.ILogger, cfg config.Config) verificationService {
+	return verificationService{
+		storage:      storage,
+		logger:       logger,
+		minDriverAge: cfg.MinDriverAge,
+	}
+}
+
+// Submit stores the licence of the customer and queues it for review.
+func (vs verificationService) Submit(ctx context.Context, req models.SubmitVerification) error {
+	if err := vs.storage.Verification().Submit(ctx, req); err != nil {
+		vs.logger.Error("ERROR in service layer while submitting verification", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (vs verificationService) GetByCustomerID(ctx context.Context, customerID string) (models.CustomerVerification, error) {
+	verification, err := vs.storage.Verification().GetByCustomerID(ctx, customerID)
+	if err != nil {
+		vs.logger.Error("ERROR in service layer while getting verification", logger.Error(err))
+		return models.CustomerVerification{}, err
+	}
+	return verification, nil
+}
+
+func (vs verificationService) GetList(ctx context.Context, req models.GetVerificationsRequest) (models.GetVerificationsResponse, error) {
+	verifications, err := vs.storage.Verification().GetList(ctx, req)
+	if err != nil {
+		vs.logger.Error("ERROR in service layer while getting verifications", logger.Error(err))
+		return verifications, err
+	}
+	return verifications, nil
+}
+
+// Review approves or rejects a pending verification on behalf of the staff member.
+func (vs verificationService) Review(ctx context.Context, req models.ReviewVerification) error {
+	// a missing verification is reported as not found rather than as already reviewed
+	if _, err := vs.storage.Verification().GetByCustomerID(ctx, req.CustomerId); err != nil {
+		return err
+	}
+
+	if err := vs.storage.Verification().Review(ctx, req); err != nil {
+		vs.logger.Error("ERROR in service layer while reviewing verification", logger.Error(err))
+		return err
+	}
+	vs.logger.Info("verification reviewed",
+		logger.String("customer_id", req.CustomerId),
+		logger.String("status", req.Status),
+		logger.String("reviewer_id", req.ReviewerId))
+	return nil
+}
+
+// checkDriver refuses a rental of [fromDate, toDate) unless the customer's licence is approved,
+// the customer is old enough on fromDate and the licence is still valid on toDate.
+func (vs verificationService) checkDriver(ctx context.Context, customerID, fromDate, toDate string) error {
+	verification, err := vs.storage.Verification().GetByCustomerID(ctx, customerID)
+	if errs.KindOf(err) == errs.KindNotFound {
+		return ErrCustomerNotVerified
+	}
+	if err != nil {
+		return err
+	}
+	return checkVerification(verification, vs.minDriverAge, fromDate, toDate)
+}
+
+func checkVerification(verification models.CustomerVerification, minAge int, fromDate, toDate string) error {
+	if verification.Status != config.VERIFICATION_APPROVED {
+		return ErrCustomerNotVerified
+	}
+
+	from, err := time.Parse(time.DateOnly, fromDate)
+	if err != nil {
+		return errs.InvalidField("from_date", err)
+	}
+	to, err := time.Parse(time.DateOnly, toDate)
+	if err != nil {
+		return errs.InvalidField("to_date", err)
+	}
+	birth, err := time.Parse(time.DateOnly, verification.DateOfBirth)
+	if err != nil {
+		return err
+	}
+	expiry, err := time.Parse(time.DateOnly, verification.LicenceExpiry)
+	if err != nil {
+		return err
+	}
+
+	if age := ageOn(birth, from); age < minAge {
+		return fmt.Errorf("%w: must be at least %d, is %d", ErrDriverTooYoung, minAge, age)
+	}
+	// the car is returned on toDate, so the licence must be valid through that day
+	if expiry.Before(to) {
+		return fmt.Errorf("%w: licence expires on %s", ErrLicenceExpires, verification.LicenceExpiry)
+	}
+	return nil
+}
+
+// ageOn is the age in full years of someone born on birth.
+func ageOn(birth, day time.Time) int {
+	age := day.Year() - birth.Year()
+	if day.Month() < birth.Month() || (day.Month() == birth.Month() && day.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVerification(t *testing.T) {
+	approved := models.CustomerVerification{
+		Status:        config.VERIFICATION_APPROVED,
+		DateOfBirth:   "2000-06-15",
+		LicenceExpiry: "2031-01-10",
+	}
+
+	assert.NoError(t, checkVerification(approved, 21, "2030-07-01", "2030-07-08"))
+
+	pending := approved
+	pending.Status = config.VERIFICATION_PENDING
+	assert.ErrorIs(t, checkVerification(pending, 21, "2030-07-01", "2030-07-08"), ErrCustomerNotVerified)
+
+	rejected := approved
+	rejected.Status = config.VERIFICATION_REJECTED
+	assert.ErrorIs(t, checkVerification(rejected, 21, "2030-07-01", "2030-07-08"), ErrCustomerNotVerified)
+
+	// 21 on 2021-06-15, one day short on the first rental day
+	assert.ErrorIs(t, checkVerification(approved, 21, "2021-06-14", "2021-06-20"), ErrDriverTooYoung)
+	assert.NoError(t, checkVerification(approved, 21, "2021-06-15", "2021-06-20"))
+	// a deployment with a higher minimum age
+	assert.ErrorIs(t, checkVerification(approved, 25, "2021-06-15", "2021-06-20"), ErrDriverTooYoung)
+
+	// the licence has to be valid on the return day
+	assert.NoError(t, checkVerification(approved, 21, "2031-01-01", "2031-01-10"))
+	assert.ErrorIs(t, checkVerification(approved, 21, "2031-01-01", "2031-01-11"), ErrLicenceExpires)
+}
+
+func TestAgeOn(t *testing.T) {
+	birth := time.Date(2004, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 20, ageOn(birth, time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 21, ageOn(birth, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, ageOn(birth, birth))
+}
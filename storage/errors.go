@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"rent-car/pkg/errs"
+)
+
+// ErrOrderStatusChanged is returned when the order left the expected status before the update.
+var ErrOrderStatusChanged = errs.Conflict("order_status_changed", "order status was changed by another request")
+
+// ErrOutboxEventNotDead is returned when replaying an outbox event that does not exist or was not dead-lettered.
+var ErrOutboxEventNotDead = errs.NotFound("dead_outbox_event_not_found", "dead outbox event not found")
+
+// ErrInvalidCursor is returned for a pagination cursor that is malformed or was issued for another sort order.
+var ErrInvalidCursor = errs.Validation("invalid_cursor", "invalid cursor",
+	errs.FieldError{Field: "cursor", Message: "is malformed or belongs to another sort order"})
+
+// ErrNotDeleted is returned when restoring a record that does not exist or is not deleted.
+var ErrNotDeleted = errs.NotFound("deleted_record_not_found", "deleted record not found")
+
+// ErrRestoreConflict is returned when a restored record clashes with an active one, e.g. a customer phone number taken again.
+var ErrRestoreConflict = errs.Conflict("restore_conflict", "record conflicts with an active record")
+
+// ErrCarHasActiveOrders is returned when deleting a car that still has new or in process orders.
+var ErrCarHasActiveOrders = errs.Conflict("car_has_active_orders", "car has new or in process orders, finish or cancel them first")
+
+// ErrCustomerNotBlocked is returned when unblocking a customer that does not exist or is not blocked.
+var ErrCustomerNotBlocked = errs.NotFound("blocked_customer_not_found", "blocked customer not found")
+
+// ErrVerificationNotPending is returned when reviewing a verification that does not exist or was already reviewed.
+var ErrVerificationNotPending = errs.Conflict("verification_not_pending", "verification is not waiting for review")
+
+// ErrLedgerChanged is returned when the payments of an order changed after the payment was checked.
+var ErrLedgerChanged = errs.Conflict("ledger_changed", "payments of the order were changed by another request")
+
+// ErrPaymentNotPending is returned when confirming or failing a payment that was confirmed or failed already.
+var ErrPaymentNotPending = errs.Conflict("payment_not_pending", "payment is not waiting for the payment provider")
+
+// ErrOrderNotDeletable is returned when deleting an order that left new or has payments, a promo code or loyalty points.
+var ErrOrderNotDeletable = errs.Conflict("order_not_deletable", "only new orders without payments can be deleted, cancel the order instead")
+
+// ErrDepositSettled is returned when settling a deposit that was released or captured already.
+var ErrDepositSettled = errs.Conflict("deposit_settled", "deposit of the order was settled already")
+
+// ErrExtensionNotPending is returned when reviewing an extension that was approved or rejected already.
+var ErrExtensionNotPending = errs.Conflict("extension_not_pending", "extension is not waiting for review")
+
+// ErrPromoCodeUsedUp is returned when a promo code was deactivated or reached a usage limit before it was redeemed.
+var ErrPromoCodeUsedUp = errs.Conflict("promo_code_used_up", "promo code reached its usage limit")
+
+// ErrNotEnoughPoints is returned when a customer spends more loyalty points than the balance holds.
+var ErrNotEnoughPoints = errs.Conflict("not_enough_points", "customer does not have enough loyalty points")
+
+// ErrBookingConflict is the domain error behind every BookingConflictError.
+var ErrBookingConflict = errs.Conflict("car_already_booked", "car is already booked")
+
+// BookingConflictError is returned when an order overlaps another not canceled order of the same car.
+type BookingConflictError struct {
+	CarID              string
+	FromDate           string
+	ToDate             string
+	ConflictingOrderID string
+}
+
+func (e *BookingConflictError) Error() string {
+	if e.ConflictingOrderID == "" {
+		return fmt.Sprintf("car %s is already booked between %s and %s", e.CarID, e.FromDate, e.ToDate)
+	}
+	return fmt.Sprintf("car %s is already booked between %s and %s by order %s", e.CarID, e.FromDate, e.ToDate, e.ConflictingOrderID)
+}
+
+func (e *BookingConflictError) Unwrap() error {
+	return ErrBookingConflict
+}
@@ -0,0 +1,368 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"strings"
+)
+
+// likeEscaper escapes the LIKE wildcards, so a search term only matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// listColumn is the SQL expression behind a list field. Cast is the SQL type
+// a cursor value of the column is converted back to.
+type listColumn struct {
+	expr string
+	cast string
+}
+
+type sortKey struct {
+	field  string
+	column listColumn
+	desc   bool
+}
+
+// cursor is the decoded form of the opaque next_cursor/prev_cursor tokens.
+// Values are the sort keys of the row the page starts after, Sort guards against reusing
+// a cursor with another sort order.
+type cursor struct {
+	Sort   string    `json:"s"`
+	Prev   bool      `json:"p,omitempty"`
+	Values []*string `json:"v"`
+}
+
+// queryBuilder assembles the WHERE, ORDER BY and pagination parts of a list query.
+// Values never end up in the SQL text, each one is sent as a positional argument:
+// conditions are written with ? placeholders which are numbered $1, $2, ... in order.
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+	keys       []sortKey
+	offset     uint64
+	limit      uint64
+
+	// keyset mode, set by Cursor
+	keyset bool
+	after  *cursor
+}
+
+func newQueryBuilder() *queryBuilder {
+	return &queryBuilder{}
+}
+
+// Where adds a condition, every ? in it is bound to the next value.
+func (b *queryBuilder) Where(condition string, values ...interface{}) *queryBuilder {
+	if n := strings.Count(condition, "?"); n != len(values) {
+		panic(fmt.Sprintf("query builder: %q has %d placeholders for %d values", condition, n, len(values)))
+	}
+
+	var sb strings.Builder
+	for _, value := range values {
+		before, after, _ := strings.Cut(condition, "?")
+		b.args = append(b.args, value)
+		sb.WriteString(before)
+		sb.WriteString(fmt.Sprintf("$%d", len(b.args)))
+		condition = after
+	}
+	sb.WriteString(condition)
+
+	b.conditions = append(b.conditions, sb.String())
+	return b
+}
+
+// Search matches term as a case insensitive substring of any of the columns.
+// An empty term adds nothing.
+func (b *queryBuilder) Search(term string, columns ...string) *queryBuilder {
+	if term == "" || len(columns) == 0 {
+		return b
+	}
+
+	pattern := "%" + likeEscaper.Replace(term) + "%"
+	b.args = append(b.args, pattern)
+
+	matches := make([]string, 0, len(columns))
+	for _, column := range columns {
+		matches = append(matches, fmt.Sprintf("%s ILIKE $%d", column, len(b.args)))
+	}
+	b.conditions = append(b.conditions, "("+strings.Join(matches, " OR ")+")")
+	return b
+}
+
+var filterOperators = map[string]string{
+	config.FILTER_EQ:  "=",
+	config.FILTER_NE:  "<>",
+	config.FILTER_GT:  ">",
+	config.FILTER_GTE: ">=",
+	config.FILTER_LT:  "<",
+	config.FILTER_LTE: "<=",
+}
+
+// Filter adds the typed filters parsed by the handler, columns maps every allowed field to its SQL column.
+// Strings compare case insensitively.
+func (b *queryBuilder) Filter(filters []models.Filter, columns map[string]listColumn) error {
+	for _, filter := range filters {
+		col, ok := columns[filter.Field]
+		if !ok {
+			return fmt.Errorf("unknown filter field %q", filter.Field)
+		}
+		column := col.expr
+
+		text, isText := filter.Value.(string)
+		switch {
+		case filter.Op == config.FILTER_LIKE && isText:
+			b.Where(column+" ILIKE ?", "%"+likeEscaper.Replace(text)+"%")
+		case filter.Op == config.FILTER_EQ && isText:
+			b.Where(column+" ILIKE ?", likeEscaper.Replace(text))
+		case filter.Op == config.FILTER_NE && isText:
+			b.Where(column+" NOT ILIKE ?", likeEscaper.Replace(text))
+		case filter.Op == config.FILTER_IN:
+			if values, ok := filter.Value.([]string); ok {
+				lowered := make([]string, 0, len(values))
+				for _, value := range values {
+					lowered = append(lowered, strings.ToLower(value))
+				}
+				b.Where("lower("+column+") = ANY(?)", lowered)
+			} else {
+				b.Where(column+" = ANY(?)", filter.Value)
+			}
+		default:
+			operator, ok := filterOperators[filter.Op]
+			if !ok {
+				return fmt.Errorf("unknown filter operator %q", filter.Op)
+			}
+			b.Where(column+" "+operator+" ?", filter.Value)
+		}
+	}
+	return nil
+}
+
+// Sort appends sort keys, columns maps every sortable field to its SQL column.
+// The last key should be unique, so the order and the cursors are stable.
+func (b *queryBuilder) Sort(sorts []models.SortField, columns map[string]listColumn) error {
+	for _, sort := range sorts {
+		column, ok := columns[sort.Field]
+		if !ok {
+			return fmt.Errorf("unknown sort field %q", sort.Field)
+		}
+		b.keys = append(b.keys, sortKey{field: sort.Field, column: column, desc: sort.Desc})
+	}
+	return nil
+}
+
+// Page selects the 1-based page of limit rows.
+func (b *queryBuilder) Page(page, limit uint64) *queryBuilder {
+	if page == 0 {
+		page = 1
+	}
+	b.offset = (page - 1) * limit
+	b.limit = limit
+	return b
+}
+
+// Cursor switches to keyset pagination: limit rows after (or before) the row encoded in token.
+// An empty token starts at the first row. Call it after Sort.
+func (b *queryBuilder) Cursor(token string, limit uint64) error {
+	b.keyset = true
+	b.limit = limit
+
+	if token == "" {
+		return nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return storage.ErrInvalidCursor
+	}
+	c := cursor{}
+	if err = json.Unmarshal(data, &c); err != nil {
+		return storage.ErrInvalidCursor
+	}
+	if c.Sort != b.sortSignature() || len(c.Values) != len(b.keys) {
+		return storage.ErrInvalidCursor
+	}
+
+	b.after = &c
+	return nil
+}
+
+func (b *queryBuilder) sortSignature() string {
+	fields := make([]string, 0, len(b.keys))
+	for _, key := range b.keys {
+		if key.desc {
+			fields = append(fields, "-"+key.field)
+		} else {
+			fields = append(fields, key.field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// KeySelect is the select expression of the row's sort keys, scan it into a string and pass
+// the collected keys to pageRows.
+func (b *queryBuilder) KeySelect() string {
+	exprs := make([]string, 0, len(b.keys))
+	for _, key := range b.keys {
+		exprs = append(exprs, key.column.expr+"::text")
+	}
+	return "json_build_array(" + strings.Join(exprs, ", ") + ")"
+}
+
+func (b *queryBuilder) where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// CountQuery returns base with the conditions, ready for counting all matching rows.
+func (b *queryBuilder) CountQuery(base string) (string, []interface{}) {
+	return base + b.where(b.conditions), b.args
+}
+
+// Query returns base with the conditions, the sort order and the page.
+func (b *queryBuilder) Query(base string) (string, []interface{}) {
+	conditions := b.conditions
+	args := append([]interface{}{}, b.args...)
+
+	// a previous page is read backwards from the cursor and put in order again by pageRows
+	backwards := b.after != nil && b.after.Prev
+
+	if b.after != nil {
+		var condition string
+		condition, args = b.afterCondition(args, backwards)
+		conditions = append(append([]string{}, conditions...), condition)
+	}
+
+	query := base + b.where(conditions)
+
+	if len(b.keys) > 0 {
+		order := make([]string, 0, len(b.keys))
+		for _, key := range b.keys {
+			if key.desc != backwards {
+				order = append(order, key.column.expr+" DESC")
+			} else {
+				order = append(order, key.column.expr)
+			}
+		}
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+
+	switch {
+	case b.keyset:
+		// one extra row tells whether another page follows
+		args = append(args, b.limit+1)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	case b.limit > 0:
+		args = append(args, b.offset, b.limit)
+		query += fmt.Sprintf(" OFFSET $%d LIMIT $%d", len(args)-1, len(args))
+	}
+	return query, args
+}
+
+// afterCondition matches the rows that come strictly after the cursor row in the scan order:
+// (k1 after v1) OR (k1 = v1 AND k2 after v2) OR ...
+// NULLs follow the Postgres default, last in ascending and first in descending order.
+func (b *queryBuilder) afterCondition(args []interface{}, backwards bool) (string, []interface{}) {
+	alternatives := []string{}
+	equal := []string{}
+
+	for i, key := range b.keys {
+		value := b.after.Values[i]
+		column := key.column.expr
+		desc := key.desc != backwards
+
+		param := ""
+		if value != nil {
+			args = append(args, *value)
+			param = fmt.Sprintf("$%d::%s", len(args), key.column.cast)
+		}
+
+		var after string
+		switch {
+		case value == nil && !desc:
+			after = ""
+		case value == nil && desc:
+			after = column + " IS NOT NULL"
+		case desc:
+			after = column + " < " + param
+		default:
+			after = "(" + column + " > " + param + " OR " + column + " IS NULL)"
+		}
+
+		if after != "" {
+			alternatives = append(alternatives, "("+strings.Join(append(append([]string{}, equal...), after), " AND ")+")")
+		}
+
+		if value == nil {
+			equal = append(equal, column+" IS NULL")
+		} else {
+			equal = append(equal, column+" = "+param)
+		}
+	}
+
+	if len(alternatives) == 0 {
+		return "FALSE", args
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+func (b *queryBuilder) encodeCursor(key string, prev bool) (string, error) {
+	values := []*string{}
+	if err := json.Unmarshal([]byte(key), &values); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(cursor{Sort: b.sortSignature(), Prev: prev, Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// pageRows finishes a page read with the builder: in keyset mode it drops the extra row,
+// restores the order of a previous page and builds the next and previous cursors.
+// keys holds the KeySelect value of every row.
+func pageRows[T any](b *queryBuilder, rows []T, keys []string) ([]T, string, string, error) {
+	if !b.keyset {
+		return rows, "", "", nil
+	}
+
+	backwards := b.after != nil && b.after.Prev
+	more := uint64(len(rows)) > b.limit
+	if more {
+		rows, keys = rows[:b.limit], keys[:b.limit]
+	}
+
+	if backwards {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return rows, "", "", nil
+	}
+
+	var (
+		next, prev string
+		err        error
+	)
+	// going forward there is a next page when the extra row came back and a previous one
+	// when we started from a cursor, going backwards it is the other way round
+	if (!backwards && more) || backwards {
+		if next, err = b.encodeCursor(keys[len(keys)-1], false); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if (backwards && more) || (!backwards && b.after != nil) {
+		if prev, err = b.encodeCursor(keys[0], true); err != nil {
+			return nil, "", "", err
+		}
+	}
+	return rows, next, prev, nil
+}
@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var maliciousSearches = []string{
+	`'; DROP TABLE cars; --`,
+	`%' OR '1'='1`,
+	`' UNION SELECT id, password FROM staff --`,
+	`$1) OR (1=1`,
+	`\'; select pg_sleep(10); --`,
+}
+
+func TestBuilderSearchNeverInlinesValues(t *testing.T) {
+	for _, search := range maliciousSearches {
+		query, args := newQueryBuilder().
+			Where("deleted_at = 0").
+			Search(search, "name", "brand").
+			Page(2, 10).
+			Query("SELECT id FROM cars")
+
+		assert.NotContains(t, query, search)
+		assert.NotContains(t, query, "'")
+		assert.Equal(t, "SELECT id FROM cars WHERE deleted_at = 0 AND (name ILIKE $1 OR brand ILIKE $1) OFFSET $2 LIMIT $3", query)
+		assert.Equal(t, []interface{}{"%" + likeEscaper.Replace(search) + "%", uint64(10), uint64(10)}, args)
+	}
+}
+
+func TestBuilderEscapesLikeWildcards(t *testing.T) {
+	_, args := newQueryBuilder().Search(`100%_\`, "name").Query("SELECT id FROM cars")
+	assert.Equal(t, []interface{}{`%100\%\_\\%`}, args)
+}
+
+func TestBuilderNumbersPlaceholders(t *testing.T) {
+	builder := newQueryBuilder().
+		Where("status <> ? AND daterange(?::date, ?::date) && period", "canceled", "2024-01-01", "2024-01-05").
+		Search("bmw", "c.name").
+		Where("year >= ?", 2018).
+		Page(1, 20)
+	assert.NoError(t, builder.Sort([]models.SortField{{Field: "name"}, {Field: "id"}}, carListColumns))
+
+	query, args := builder.Query("SELECT c.id FROM cars c")
+	assert.Equal(t, "SELECT c.id FROM cars c WHERE status <> $1 AND daterange($2::date, $3::date) && period AND (c.name ILIKE $4) AND year >= $5 ORDER BY c.name, c.id OFFSET $6 LIMIT $7", query)
+	assert.Len(t, args, 7)
+
+	countQuery, countArgs := builder.CountQuery("SELECT COUNT(*) FROM cars c")
+	assert.False(t, strings.Contains(countQuery, "LIMIT"))
+	assert.Len(t, countArgs, 5)
+}
+
+func TestBuilderWithoutConditions(t *testing.T) {
+	query, args := newQueryBuilder().Search("", "name").Query("SELECT id FROM cars")
+	assert.Equal(t, "SELECT id FROM cars", query)
+	assert.Empty(t, args)
+}
+
+func TestBuilderPanicsOnPlaceholderMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		newQueryBuilder().Where("a = ? AND b = ?", 1)
+	})
+}
+
+func TestListQueriesWithMaliciousSearch(t *testing.T) {
+	carRepo := NewCar(db)
+	customerRepo := NewCustomer(db, logg)
+	orderRepo := NewOrder(db)
+
+	for _, search := range maliciousSearches {
+		_, err := carRepo.GetAll(context.Background(), models.GetAllCarsRequest{Search: search, Page: 1, Limit: 10})
+		assert.NoError(t, err, search)
+
+		_, err = carRepo.GetAvaibleCars(context.Background(), models.GetAvailableCarsRequest{
+			From: "2024-04-01", To: "2024-04-05", Search: search, Brand: search, Page: 1, Limit: 10,
+		})
+		assert.NoError(t, err, search)
+
+		_, err = customerRepo.GetAllCustomer(context.Background(), models.GetAllCustomersRequest{Search: search, Page: 1, Limit: 10})
+		assert.NoError(t, err, search)
+
+		_, err = orderRepo.GetAll(context.Background(), models.GetAllOrdersRequest{Search: search, Page: 1, Limit: 10})
+		assert.NoError(t, err, search)
+	}
+
+	// the table the first payload tries to drop is still there
+	_, err := carRepo.GetAll(context.Background(), models.GetAllCarsRequest{Page: 1, Limit: 1})
+	assert.NoError(t, err)
+}
+
+func TestBuilderFilterAndSort(t *testing.T) {
+	builder := newQueryBuilder()
+	err := builder.Filter([]models.Filter{
+		{Field: "brand", Op: "eq", Value: "BMW_"},
+		{Field: "year", Op: "gte", Value: int64(2018)},
+		{Field: "colour", Op: "in", Value: []string{"Red", "black"}},
+		{Field: "name", Op: "like", Value: "x5"},
+	}, carListColumns)
+	assert.NoError(t, err)
+	assert.NoError(t, builder.Sort([]models.SortField{{Field: "created_at", Desc: true}, {Field: "name"}}, carListColumns))
+
+	query, args := builder.Query("SELECT id FROM cars")
+	assert.Equal(t, "SELECT id FROM cars WHERE c.brand ILIKE $1 AND c.year >= $2 AND lower(c.colour) = ANY($3) AND c.name ILIKE $4 ORDER BY c.created_at DESC, c.name", query)
+	assert.Equal(t, []interface{}{`BMW\_`, int64(2018), []string{"red", "black"}, "%x5%"}, args)
+}
+
+func TestBuilderRejectsUnmappedFields(t *testing.T) {
+	assert.Error(t, newQueryBuilder().Filter([]models.Filter{{Field: "password", Op: "eq", Value: "x"}}, customerListColumns))
+	assert.Error(t, newQueryBuilder().Sort([]models.SortField{{Field: "password"}}, customerListColumns))
+}
+
+func cursorBuilder(t *testing.T, token string) *queryBuilder {
+	builder := newQueryBuilder().Where("c.deleted_at = 0")
+	assert.NoError(t, builder.Sort([]models.SortField{{Field: "created_at", Desc: true}, {Field: "id"}}, carListColumns))
+	assert.NoError(t, builder.Cursor(token, 2))
+	return builder
+}
+
+func TestBuilderCursorFirstPage(t *testing.T) {
+	builder := cursorBuilder(t, "")
+
+	query, args := builder.Query("SELECT id FROM cars c")
+	assert.Equal(t, "SELECT id FROM cars c WHERE c.deleted_at = 0 ORDER BY c.created_at DESC, c.id LIMIT $1", query)
+	assert.Equal(t, []interface{}{uint64(3)}, args)
+	assert.Equal(t, "json_build_array(c.created_at::text, c.id::text)", builder.KeySelect())
+
+	rows, next, prev, err := pageRows(builder, []string{"a", "b", "c"}, []string{
+		`["2024-04-03 10:00:00", "a"]`,
+		`["2024-04-02 10:00:00", "b"]`,
+		`["2024-04-01 10:00:00", "c"]`,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b"}, rows)
+		assert.NotEmpty(t, next)
+		assert.Empty(t, prev)
+	}
+
+	// the next page starts strictly after row b
+	builder = cursorBuilder(t, next)
+	query, args = builder.Query("SELECT id FROM cars c")
+	assert.Equal(t, "SELECT id FROM cars c WHERE c.deleted_at = 0 AND "+
+		"((c.created_at < $1::timestamp) OR (c.created_at = $1::timestamp AND (c.id > $2::uuid OR c.id IS NULL))) "+
+		"ORDER BY c.created_at DESC, c.id LIMIT $3", query)
+	assert.Equal(t, []interface{}{"2024-04-02 10:00:00", "b", uint64(3)}, args)
+
+	rows, next, prev, err = pageRows(builder, []string{"c"}, []string{`["2024-04-01 10:00:00", "c"]`})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"c"}, rows)
+		assert.Empty(t, next)
+		assert.NotEmpty(t, prev)
+	}
+
+	// the previous page is read backwards from row c and returned in order
+	builder = cursorBuilder(t, prev)
+	query, _ = builder.Query("SELECT id FROM cars c")
+	assert.Equal(t, "SELECT id FROM cars c WHERE c.deleted_at = 0 AND "+
+		"(((c.created_at > $1::timestamp OR c.created_at IS NULL)) OR (c.created_at = $1::timestamp AND c.id < $2::uuid)) "+
+		"ORDER BY c.created_at, c.id DESC LIMIT $3", query)
+
+	rows, next, prev, err = pageRows(builder, []string{"b", "a"}, []string{
+		`["2024-04-02 10:00:00", "b"]`,
+		`["2024-04-03 10:00:00", "a"]`,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b"}, rows)
+		assert.NotEmpty(t, next)
+		assert.Empty(t, prev)
+	}
+}
+
+func TestBuilderCursorNullKeys(t *testing.T) {
+	builder := newQueryBuilder()
+	assert.NoError(t, builder.Sort([]models.SortField{{Field: "amount"}, {Field: "id"}}, orderListColumns))
+	assert.NoError(t, builder.Cursor("", 10))
+	token, err := builder.encodeCursor(`[null, "b"]`, false)
+	assert.NoError(t, err)
+
+	builder = newQueryBuilder()
+	assert.NoError(t, builder.Sort([]models.SortField{{Field: "amount"}, {Field: "id"}}, orderListColumns))
+	assert.NoError(t, builder.Cursor(token, 10))
+
+	// NULL amounts sort last, so only rows with a NULL amount and a greater id follow
+	query, args := builder.Query("SELECT o.id FROM orders o")
+	assert.Equal(t, "SELECT o.id FROM orders o WHERE ((o.amount IS NULL AND (o.id > $1::uuid OR o.id IS NULL))) ORDER BY o.amount, o.id LIMIT $2", query)
+	assert.Equal(t, []interface{}{"b", uint64(11)}, args)
+}
+
+func TestBuilderRejectsForeignCursors(t *testing.T) {
+	builder := newQueryBuilder()
+	assert.NoError(t, builder.Sort([]models.SortField{{Field: "name"}, {Field: "id"}}, carListColumns))
+	assert.NoError(t, builder.Cursor("", 10))
+	token, err := builder.encodeCursor(`["bmw", "a"]`, false)
+	assert.NoError(t, err)
+
+	other := newQueryBuilder()
+	assert.NoError(t, other.Sort([]models.SortField{{Field: "year"}, {Field: "id"}}, carListColumns))
+	assert.ErrorIs(t, other.Cursor(token, 10), storage.ErrInvalidCursor)
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24", maliciousSearches[0]} {
+		assert.ErrorIs(t, cursorBuilder(t, "").Cursor(token, 10), storage.ErrInvalidCursor)
+	}
+}
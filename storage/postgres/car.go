@@ -3,10 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"rent-car/api/models"
 	"rent-car/config"
 	"rent-car/pkg"
+	"rent-car/storage"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -33,8 +34,12 @@ func (c *carRepo) Create(ctx context.Context,car models.CreateCar) (string, erro
 		model,
 		hourse_power,
 		colour,
-		engine_cap)
-		VALUES($1,$2,$3,$4,$5,$6,$7) 
+		engine_cap,
+		year,
+		daily_rate,
+		weekend_rate,
+		category)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,NULLIF($10::numeric, 0),COALESCE(NULLIF($11, ''), $12)) 
 	`
 
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
@@ -44,11 +49,12 @@ func (c *carRepo) Create(ctx context.Context,car models.CreateCar) (string, erro
 		id.String(),
 		car.Name, car.Brand,
 		car.Model, car.HoursePower,
-		car.Colour, car.EngineCap)
+		car.Colour, car.EngineCap, car.Year,
+		car.DailyRate, car.WeekendRate,
+		car.Category, config.CAR_CATEGORY_STANDARD)
 
 	if err != nil {
-		fmt.Println(err.Error())
-		panic(err)
+		return "", dbError(err, "car")
 	}
 
 	return id.String(), nil
@@ -63,144 +69,290 @@ func (c *carRepo) Update(ctx context.Context,car models.Car) (string, error) {
 			hourse_power=$4,
 			colour=$5,
 			engine_cap=$6,
+			daily_rate=$7,
+			weekend_rate=NULLIF($8::numeric, 0),
+			category=COALESCE(NULLIF($9, ''), category),
 			updated_at=CURRENT_TIMESTAMP
-		WHERE id = $7 AND deleted_at = 0
+		WHERE id = $10 AND deleted_at IS NULL
 	`
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
 
-	_, err := c.db.Exec(ctx,query,
+	tag, err := c.db.Exec(ctx,query,
 		car.Name, car.Brand,
 		car.Model, car.HoursePower,
-		car.Colour, car.EngineCap, car.Id)
+		car.Colour, car.EngineCap,
+		car.DailyRate, car.WeekendRate, car.Category, car.Id)
 
 	if err != nil {
-		fmt.Println(err.Error())
-		panic(err)
+		return "", dbError(err, "car")
+	}
+	if tag.RowsAffected() == 0 {
+		return "", notFound("car")
 	}
 
 	return car.Id, nil
 }
 
+// carListColumns maps the filterable and sortable fields of the car list to columns.
+var carListColumns = map[string]listColumn{
+	"id":           {expr: "c.id", cast: "uuid"},
+	"name":         {expr: "c.name", cast: "text"},
+	"brand":        {expr: "c.brand", cast: "text"},
+	"model":        {expr: "c.model", cast: "text"},
+	"colour":       {expr: "c.colour", cast: "text"},
+	"category":     {expr: "c.category", cast: "text"},
+	"year":         {expr: "c.year", cast: "integer"},
+	"hourse_power": {expr: "c.hourse_power", cast: "integer"},
+	"engine_cap":   {expr: "c.engine_cap", cast: "numeric"},
+	"daily_rate":   {expr: "c.daily_rate", cast: "numeric"},
+	"created_at":   {expr: "c.created_at", cast: "timestamp"},
+	"deleted_at":   {expr: "c.deleted_at", cast: "timestamp"},
+}
+
 func (c *carRepo) GetAll(ctx context.Context,req models.GetAllCarsRequest) (models.GetAllCarsResponse, error) {
-	var (
-		resp   = models.GetAllCarsResponse{}
-		filter = ""
-	)
-	offset := (req.Page - 1) * req.Limit
+	resp := models.GetAllCarsResponse{}
 
-	if req.Search != "" {
-		filter += fmt.Sprintf(` and name ILIKE  '%%%v%%' `, req.Search)
+	builder := newQueryBuilder().
+		Where("c.deleted_at IS NULL").
+		Search(req.Search, "c.name")
+
+	if err := builder.Filter(req.Filters, carListColumns); err != nil {
+		return resp, err
+	}
+
+	sorts := req.Sort
+	if len(sorts) == 0 {
+		sorts = []models.SortField{{Field: "created_at", Desc: true}}
+	}
+	if err := builder.Sort(append(sorts, models.SortField{Field: "id"}), carListColumns); err != nil {
+		return resp, err
 	}
 
-	filter += fmt.Sprintf("OFFSET %v LIMIT %v", offset, req.Limit)
-	fmt.Println("filter:", filter)
+	if req.UseCursor {
+		if err := builder.Cursor(req.Cursor, req.Limit); err != nil {
+			return resp, err
+		}
+	} else {
+		builder.Page(req.Page, req.Limit)
+	}
 
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
 
-	rows, err := c.db.Query(ctx,`select 
-				count(id) OVER(),
-				id, 
-				name,
-				brand,
-				model,
-				hourse_power,
-				colour,
-				engine_cap,
-				--created_at::date,
-				updated_at,
-				year
-	  FROM cars WHERE deleted_at = 0 ` + filter + ``)
+	countQuery, countArgs := builder.CountQuery(`SELECT COUNT(*) FROM cars c`)
+	if err := c.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`select 
+				` + builder.KeySelect() + `,
+				c.id, 
+				c.name,
+				c.brand,
+				c.model,
+				c.hourse_power,
+				c.colour,
+				c.engine_cap,
+				c.daily_rate,
+				COALESCE(c.weekend_rate, 0),
+				c.category,
+				c.created_at::text,
+				c.updated_at::text,
+				c.year
+	  FROM cars c`)
+	rows, err := c.db.Query(ctx, query, args...)
 	if err != nil {
 		return resp, err
 	}
+	defer rows.Close()
+
+	keys := []string{}
 	for rows.Next() {
 		var (
 			car      = models.Car{}
+			key          string
+			createdAt    sql.NullString
 			updateAt     sql.NullString
 		)
 
 		if err := rows.Scan(
-			&resp.Count,
+			&key,
 			&car.Id,
 			&car.Name,
 			&car.Brand,
 			&car.Model,
-			// &car.Year,
 			&car.HoursePower,
 			&car.Colour,
 			&car.EngineCap,
-			// &car.CreatedAt,
+			&car.DailyRate,
+			&car.WeekendRate,
+			&car.Category,
+			&createdAt,
 			&updateAt,
 			&car.Year); err != nil {
 			return resp, err
 		}
          
+		car.CreatedAt = pkg.NullStringToString(createdAt)
 		car.UpdatedAt = pkg.NullStringToString(updateAt)
 		resp.Cars = append(resp.Cars, car)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
 	}
-	return resp, nil
-}
 
-func (c *carRepo) GetAvaibleCars(ctx context.Context,req models.GetAllCarsRequest) (models.GetAllCarsResponse, error) {
-	var (
-		resp   = models.GetAllCarsResponse{}
-		filter = ""
-	)
-	offset := (req.Page - 1) * req.Limit
+	resp.Cars, resp.NextCursor, resp.PrevCursor, err = pageRows(builder, resp.Cars, keys)
+	return resp, err
+}
 
-	if req.Search != "" {
-		filter += fmt.Sprintf(` and name ILIKE  '%%%v%%' `, req.Search)
+// GetAvaibleCars returns cars without a not canceled order overlapping the [From, To) window.
+func (c *carRepo) GetAvaibleCars(ctx context.Context,req models.GetAvailableCarsRequest) (models.GetAllCarsResponse, error) {
+	resp := models.GetAllCarsResponse{}
+
+	builder := newQueryBuilder().
+		Where("c.deleted_at IS NULL").
+		Where(`NOT EXISTS (
+		SELECT 1 FROM orders o
+		WHERE o.car_id = c.id
+		AND o.status <> ?
+		AND daterange(o.from_date, o.to_date, '[)') && daterange(?::date, ?::date, '[)')
+	)`, config.STATUS_CANCELED, req.From, req.To).
+		Search(req.Search, "c.name")
+
+	if req.Brand != "" {
+		builder.Where("c.brand ILIKE ?", likeEscaper.Replace(req.Brand))
+	}
+	if req.Colour != "" {
+		builder.Where("c.colour ILIKE ?", likeEscaper.Replace(req.Colour))
+	}
+	if req.YearFrom > 0 {
+		builder.Where("c.year >= ?", req.YearFrom)
+	}
+	if req.YearTo > 0 {
+		builder.Where("c.year <= ?", req.YearTo)
+	}
+	if req.HoursePowerFrom > 0 {
+		builder.Where("c.hourse_power >= ?", req.HoursePowerFrom)
+	}
+	if req.HoursePowerTo > 0 {
+		builder.Where("c.hourse_power <= ?", req.HoursePowerTo)
+	}
+	if req.EngineCapFrom > 0 {
+		builder.Where("c.engine_cap >= ?", float64(req.EngineCapFrom))
+	}
+	if req.EngineCapTo > 0 {
+		builder.Where("c.engine_cap <= ?", float64(req.EngineCapTo))
+	}
+	if err := builder.Sort([]models.SortField{{Field: "name"}, {Field: "id"}}, carListColumns); err != nil {
+		return resp, err
 	}
 
-	filter += fmt.Sprintf(" OFFSET %v LIMIT %v", offset, req.Limit)
-	fmt.Println("filter:", filter)
+	if req.UseCursor {
+		if err := builder.Cursor(req.Cursor, req.Limit); err != nil {
+			return resp, err
+		}
+	} else {
+		builder.Page(req.Page, req.Limit)
+	}
 
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
-	rows,err := c.db.Query(ctx,`
-	SELECT name,model 
-	FROM cars
-	WHERE id NOT IN (SELECT car_id FROM orders WHERE car_id IS NOT NULL)`+ filter + ``)
+
+	countQuery, countArgs := builder.CountQuery(`SELECT COUNT(*) FROM cars c`)
+	if err := c.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`
+	SELECT
+		` + builder.KeySelect() + `,
+		c.id,
+		c.name,
+		c.brand,
+		c.model,
+		c.hourse_power,
+		c.colour,
+		c.engine_cap,
+		c.daily_rate,
+		COALESCE(c.weekend_rate, 0),
+		c.category,
+		c.year,
+		c.created_at::text,
+		c.updated_at::text
+	FROM cars c`)
+	rows,err := c.db.Query(ctx, query, args...)
 	if err != nil {
 		return resp,err
 	}
+	defer rows.Close()
+
+	keys := []string{}
 	for rows.Next() {
 	     var (
 			car = models.Car{}
+			key string
+			createdAt sql.NullString
+			updatedAt sql.NullString
 		 )
 
 		if err := rows.Scan(
-
+			&key,
+			&car.Id,
 			&car.Name,
-			&car.Model,);err != nil{
+			&car.Brand,
+			&car.Model,
+			&car.HoursePower,
+			&car.Colour,
+			&car.EngineCap,
+			&car.DailyRate,
+			&car.WeekendRate,
+			&car.Category,
+			&car.Year,
+			&createdAt,
+			&updatedAt);err != nil{
 				return resp,err
 			}
 
+		car.CreatedAt = pkg.NullStringToString(createdAt)
+		car.UpdatedAt = pkg.NullStringToString(updatedAt)
 		resp.Cars = append(resp.Cars, car)	
+		keys = append(keys, key)
 	}
 	if err = rows.Err();err != nil {
 		return resp,err
-	   }
-	   countQuery := `SELECT COUNT(*) AS count_of_available_cars
-	   FROM cars
-	   WHERE id NOT IN (SELECT car_id FROM orders WHERE car_id IS NOT NULL)`
-
-	   err = c.db.QueryRow(ctx,countQuery).Scan(&resp.Count)
-		 if err != nil{
-			return resp,err
-		 }
-	   return resp,nil
+	}
+
+	resp.Cars, resp.NextCursor, resp.PrevCursor, err = pageRows(builder, resp.Cars, keys)
+	return resp,err
 }
 
 func (c *carRepo) GetByID(ctx context.Context,id string) (models.Car, error) {
-	car := models.Car{}
+	var (
+		car       = models.Car{}
+		createdAt sql.NullString
+		updatedAt sql.NullString
+	)
 
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
 
-	if err := c.db.QueryRow(ctx,`select id,name,brand,model,hourse_power,colour,engine_cap from cars where id = $1`, id).Scan(
+	if err := c.db.QueryRow(ctx,`select
+		id,
+		name,
+		brand,
+		model,
+		hourse_power,
+		colour,
+		engine_cap,
+		year,
+		daily_rate,
+		COALESCE(weekend_rate, 0),
+		category,
+		created_at::text,
+		updated_at::text
+		from cars where id = $1 and deleted_at IS NULL`, id).Scan(
 		&car.Id,
 		&car.Name,
 		&car.Brand,
@@ -208,23 +360,241 @@ func (c *carRepo) GetByID(ctx context.Context,id string) (models.Car, error) {
 		&car.HoursePower,
 		&car.Colour,
 		&car.EngineCap,
+		&car.Year,
+		&car.DailyRate,
+		&car.WeekendRate,
+		&car.Category,
+		&createdAt,
+		&updatedAt,
 	); err != nil {
+		return car, dbError(err, "car")
+	}
+	car.CreatedAt = pkg.NullStringToString(createdAt)
+	car.UpdatedAt = pkg.NullStringToString(updatedAt)
+
+	seasonalRates, err := c.GetSeasonalRates(ctx, id)
+	if err != nil {
 		return car, err
 	}
+	car.SeasonalRates = seasonalRates
+
 	return car, nil
 }
 
-func (c *carRepo) Delete(ctx context.Context,id string) error {
+func (c *carRepo) CreateSeasonalRate(ctx context.Context, rate models.SeasonalRate) (string, error) {
+	id := uuid.New()
 
-	query := `delete from cars WHERE id = $1`
+	query := `insert into car_seasonal_rates(
+		id,
+		car_id,
+		name,
+		from_date,
+		to_date,
+		daily_rate)
+		values($1,$2,$3,$4,$5,$6)`
 
-	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
 
-	_, err := c.db.Exec(ctx,query, id)
+	_, err := c.db.Exec(ctx, query, id.String(), rate.CarId, rate.Name, rate.FromDate, rate.ToDate, rate.DailyRate)
+	if err != nil {
+		return "", dbError(err, "seasonal_rate")
+	}
+	return id.String(), nil
+}
+
+func (c *carRepo) GetSeasonalRates(ctx context.Context, carID string) ([]models.SeasonalRate, error) {
+	rates := []models.SeasonalRate{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	rows, err := c.db.Query(ctx, `select
+		id,
+		car_id,
+		name,
+		from_date::text,
+		to_date::text,
+		daily_rate
+		from car_seasonal_rates
+		where car_id = $1
+		order by from_date`, carID)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate := models.SeasonalRate{}
+		if err := rows.Scan(
+			&rate.Id,
+			&rate.CarId,
+			&rate.Name,
+			&rate.FromDate,
+			&rate.ToDate,
+			&rate.DailyRate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (c *carRepo) DeleteSeasonalRate(ctx context.Context, carID, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := c.db.Exec(ctx, `delete from car_seasonal_rates where id = $1 and car_id = $2`, id, carID)
+	if err != nil {
+		return dbError(err, "seasonal_rate")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("seasonal_rate")
+	}
+	return nil
+}
+
+// Delete soft deletes the car, it stays in the orders that reference it until it is purged. The car row is
+// locked like a booking does, and a car with new or in process orders fails with storage.ErrCarHasActiveOrders.
+func (c *carRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var lockedID string
+	if err = tx.QueryRow(ctx, `select id from cars where id = $1 and deleted_at IS NULL for update`, id).Scan(&lockedID); err != nil {
+		return dbError(err, "car")
+	}
+
+	var active bool
+	if err = tx.QueryRow(ctx, `select exists(select 1 from orders where car_id = $1 and status in ($2, $3))`,
+		id, config.STATUS_NEW, config.STATUS_IN_PROCESS).Scan(&active); err != nil {
 		return err
 	}
+	if active {
+		return storage.ErrCarHasActiveOrders
+	}
+
+	if _, err = tx.Exec(ctx, `update cars set deleted_at = NOW() WHERE id = $1`, id); err != nil {
+		return dbError(err, "car")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetDeleted lists the soft deleted cars, the most recently deleted first.
+func (c *carRepo) GetDeleted(ctx context.Context, req models.GetDeletedRequest) (models.GetAllCarsResponse, error) {
+	resp := models.GetAllCarsResponse{Cars: []models.Car{}}
+
+	builder := newQueryBuilder().Where("c.deleted_at IS NOT NULL")
+	err := builder.Sort([]models.SortField{{Field: "deleted_at", Desc: true}, {Field: "id"}}, carListColumns)
+	if err != nil {
+		return resp, err
+	}
+	builder.Page(req.Page, req.Limit)
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`SELECT COUNT(*) FROM cars c`)
+	if err := c.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`select
+		c.id,
+		c.name,
+		c.brand,
+		c.model,
+		c.hourse_power,
+		c.colour,
+		c.engine_cap,
+		c.daily_rate,
+		COALESCE(c.weekend_rate, 0),
+		c.category,
+		c.year,
+		c.created_at::text,
+		c.updated_at::text,
+		c.deleted_at::text
+		FROM cars c`)
+	rows, err := c.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			car       = models.Car{}
+			createdAt sql.NullString
+			updatedAt sql.NullString
+			deletedAt sql.NullString
+		)
+		if err := rows.Scan(
+			&car.Id,
+			&car.Name,
+			&car.Brand,
+			&car.Model,
+			&car.HoursePower,
+			&car.Colour,
+			&car.EngineCap,
+			&car.DailyRate,
+			&car.WeekendRate,
+			&car.Category,
+			&car.Year,
+			&createdAt,
+			&updatedAt,
+			&deletedAt); err != nil {
+			return resp, err
+		}
+		car.CreatedAt = pkg.NullStringToString(createdAt)
+		car.UpdatedAt = pkg.NullStringToString(updatedAt)
+		car.DeletedAt = pkg.NullStringToString(deletedAt)
+		resp.Cars = append(resp.Cars, car)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
 
+// Restore undoes the soft delete of the car.
+func (c *carRepo) Restore(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := c.db.Exec(ctx, `update cars set
+		deleted_at = NULL,
+		updated_at = NOW()
+		where id = $1 and deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return dbError(err, "car")
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotDeleted
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Purge removes the cars deleted more than retention ago for good.
+// Cars that orders still reference are kept, so the order history stays complete.
+func (c *carRepo) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := c.db.Exec(ctx, `delete from cars c
+		where c.deleted_at < NOW() - make_interval(secs => $1)
+		and NOT EXISTS (SELECT 1 FROM orders o WHERE o.car_id = c.id)`, retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -6,7 +6,11 @@ import (
 	"github.com/go-faker/faker/v4"
 	"github.com/stretchr/testify/assert"
 	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/storage"
 	"testing"
+	"time"
 )
 
 func TestCreateCar(t *testing.T) {
@@ -112,9 +116,11 @@ func TestGetAvailableCars(t *testing.T) {
 
 	carRepository := NewCar(db)
 
-	req := models.GetAllCarsRequest{
-		Page:  1,
-		Limit: 10,
+	req := models.GetAvailableCarsRequest{
+		From:   "2030-04-05",
+		To:     "2030-04-10",
+		Page:   1,
+		Limit:  10,
 		Search: "Ms. Darlene Murray",
 	}
 
@@ -123,8 +129,149 @@ func TestGetAvailableCars(t *testing.T) {
 		t.Errorf("Delete failed with error: %v", err)
 	}
 	fmt.Println(resp)
+
+	orders := NewOrder(db)
+	ctx := context.Background()
+
+	// listed tells whether the car of the order is available over the order dates
+	listed := func(t *testing.T, order models.OrderAll) bool {
+		t.Helper()
+
+		car, err := carRepository.GetByID(ctx, order.CarId)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		resp, err := carRepository.GetAvaibleCars(ctx, models.GetAvailableCarsRequest{
+			From:   order.FromDate,
+			To:     order.ToDate,
+			Page:   1,
+			Limit:  100,
+			Search: car.Name,
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		for _, c := range resp.Cars {
+			if c.Id == car.Id {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("overlapping order hides the car", func(t *testing.T) {
+		order, err := orders.GetByID(ctx, createTestOrder(t, 150))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.False(t, listed(t, order))
+	})
+
+	t.Run("canceled order keeps the car listed", func(t *testing.T) {
+		orderID := createTestOrder(t, 150)
+		_, err := orders.Cancel(ctx, models.OrderCancellation{
+			OrderId:    orderID,
+			Policy:     "free_cancellation",
+			Reason:     "plans changed",
+			ActorRole:  config.ADMIN_ROLE,
+			FromStatus: config.STATUS_NEW,
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		order, err := orders.GetByID(ctx, orderID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, listed(t, order))
+	})
 }
 
 
 
 
+func TestSoftDeleteCar(t *testing.T) {
+	repo := NewCar(db)
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, models.CreateCar{
+		Name:      faker.Name(),
+		Year:      2015,
+		Brand:     faker.Word(),
+		DailyRate: 50,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, repo.Delete(ctx, id))
+	assert.Equal(t, errs.KindNotFound, errs.KindOf(repo.Delete(ctx, id)))
+
+	_, err = repo.GetByID(ctx, id)
+	assert.Equal(t, errs.KindNotFound, errs.KindOf(err))
+
+	deleted, err := repo.GetDeleted(ctx, models.GetDeletedRequest{Page: 1, Limit: 100})
+	if assert.NoError(t, err) {
+		found := false
+		for _, car := range deleted.Cars {
+			if car.Id == id {
+				found = true
+				assert.NotEmpty(t, car.DeletedAt)
+			}
+		}
+		assert.True(t, found, "deleted car is not listed")
+	}
+
+	// a fresh delete is within the retention period
+	_, err = repo.Purge(ctx, config.SoftDeleteRetention)
+	assert.NoError(t, err)
+	assert.True(t, carDeleted(t, id), "freshly deleted car was purged")
+
+	assert.NoError(t, repo.Restore(ctx, id))
+	assert.ErrorIs(t, repo.Restore(ctx, id), storage.ErrNotDeleted)
+
+	restored, err := repo.GetByID(ctx, id)
+	if assert.NoError(t, err) {
+		assert.Empty(t, restored.DeletedAt)
+	}
+
+	// a delete past the retention period is purged when no order references the car
+	assert.NoError(t, repo.Delete(ctx, id))
+	_, err = db.Exec(ctx, `update cars set deleted_at = NOW() - make_interval(secs => $1) where id = $2`,
+		(config.SoftDeleteRetention + time.Hour).Seconds(), id)
+	if !assert.NoError(t, err) {
+		return
+	}
+	purged, err := repo.Purge(ctx, config.SoftDeleteRetention)
+	if assert.NoError(t, err) {
+		assert.Positive(t, purged)
+	}
+	assert.False(t, carDeleted(t, id), "old deleted car was not purged")
+}
+
+func TestDeleteCarWithActiveOrder(t *testing.T) {
+	repo := NewCar(db)
+	orders := NewOrder(db)
+	ctx := context.Background()
+
+	orderID := createTestOrder(t, 150)
+	order, err := orders.GetByID(ctx, orderID)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// late fees and pricing still look the car up
+	assert.ErrorIs(t, repo.Delete(ctx, order.CarId), storage.ErrCarHasActiveOrders)
+	_, err = repo.GetByID(ctx, order.CarId)
+	assert.NoError(t, err)
+}
+
+// carDeleted tells whether the car is still kept as soft deleted.
+func carDeleted(t *testing.T, id string) bool {
+	t.Helper()
+
+	var deleted bool
+	err := db.QueryRow(context.Background(), `select exists(select 1 from cars where id = $1 and deleted_at IS NOT NULL)`, id).Scan(&deleted)
+	assert.NoError(t, err)
+	return deleted
+}
@@ -7,14 +7,17 @@ import (
 	"log"
 
 	// "database/sql"
-	"fmt"
 	"rent-car/api/models"
 	"rent-car/config"
+	"rent-car/pkg"
 	"rent-car/pkg/logger"
+	"rent-car/storage"
+	"time"
 
-	// "rent-car/pkg"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -51,63 +54,108 @@ func (c *customerRepo) Create(ctx context.Context, customer models.Customer) (st
 	last_name,
 	gmail,
 	phone,
-	password,
-	is_blocked) 
-    values($1,$2,$3,$4,$5,$6,$7)`
+	password) 
+    values($1,$2,$3,$4,$5,$6)`
 
 	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
 
-	_, err = c.db.Exec(ctx, query, id.String(), customer.FirstName, customer.LastName, customer.Gmail, customer.Phone, hashedpassword, customer.Is_Blocked)
+	_, err = c.db.Exec(ctx, query, id.String(), customer.FirstName, customer.LastName, customer.Gmail, customer.Phone, hashedpassword)
 	if err != nil {
-		return "error:", err
+		return "", dbError(err, "customer")
 	}
 	return id.String(), nil
 }
 
-func (c *customerRepo) UpdateCustomer(ctx context.Context, customer models.Customer) (string, error) {
+func (c *customerRepo) UpdateCustomer(ctx context.Context, customer models.UpdateCustomer) (string, error) {
 	query := `update customers set 
 	first_name=$1,
 	last_name=$2,
 	gmail=$3,
 	phone=$4,
-	is_blocked=$5,
 	updated_at=CURRENT_TIMESTAMP
-	WHERE id = $6 AND deleted_at = 0
+	WHERE id = $5 AND deleted_at IS NULL
 	`
 	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
-	_, err := c.db.Exec(ctx, query,
+	tag, err := c.db.Exec(ctx, query,
 		customer.FirstName,
 		customer.LastName,
 		customer.Gmail,
 		customer.Phone,
-		customer.Is_Blocked,
 		customer.Id)
 	if err != nil {
-		return "", err
+		return "", dbError(err, "customer")
+	}
+	if tag.RowsAffected() == 0 {
+		return "", notFound("customer")
 	}
 	return customer.Id, nil
 }
 
+// blockedExpr is true while a block of the customer cu is in force, a block past blocked_until has ended.
+const blockedExpr = `(cu.is_blocked AND (cu.blocked_until IS NULL OR cu.blocked_until > NOW()))`
+
 // --cu.created_at,
 // --cu.updated_at,
 
+// customerListColumns maps the filterable and sortable fields of the customer list to columns.
+var customerListColumns = map[string]listColumn{
+	"id":         {expr: "cu.id", cast: "uuid"},
+	"order_id":   {expr: "o.id", cast: "uuid"},
+	"first_name": {expr: "cu.first_name", cast: "text"},
+	"last_name":  {expr: "cu.last_name", cast: "text"},
+	"gmail":      {expr: "cu.gmail", cast: "text"},
+	"phone":      {expr: "cu.phone", cast: "text"},
+	"is_blocked": {expr: blockedExpr, cast: "boolean"},
+	"created_at": {expr: "cu.created_at", cast: "timestamp"},
+	"deleted_at": {expr: "cu.deleted_at", cast: "timestamp"},
+}
+
 func (c *customerRepo) GetAllCustomer(ctx context.Context, req models.GetAllCustomersRequest) (models.GetAllCustomersResponse, error) {
-	var (
-		resp   = models.GetAllCustomersResponse{}
-		filter = ""
-	)
+	resp := models.GetAllCustomersResponse{}
+
+	builder := newQueryBuilder().
+		Where("cu.deleted_at IS NULL").
+		Search(req.Search, "cu.first_name")
+
+	if err := builder.Filter(req.Filters, customerListColumns); err != nil {
+		return resp, err
+	}
 
-	offset := (req.Page - 1) * req.Limit
-	if req.Search != "" {
-		filter += fmt.Sprintf(`and first_name ILIKE '%%%v%%'`, req.Search)
+	sorts := req.Sort
+	if len(sorts) == 0 {
+		sorts = []models.SortField{{Field: "first_name"}}
 	}
+	// a customer is listed once per order
+	sorts = append(sorts, models.SortField{Field: "id"}, models.SortField{Field: "order_id"})
+	if err := builder.Sort(sorts, customerListColumns); err != nil {
+		return resp, err
+	}
+
+	if req.UseCursor {
+		if err := builder.Cursor(req.Cursor, req.Limit); err != nil {
+			return resp, err
+		}
+	} else {
+		builder.Page(req.Page, req.Limit)
+	}
+
+	from := `
+	From customers cu 
+	JOIN orders o ON  cu.id = o.customer_id
+	JOIN  cars ca ON ca.id = o.car_id
+	`
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
 
-	filter += fmt.Sprintf(" OFFSET %v LIMIT %v", offset, req.Limit)
-	fmt.Println("filter:", filter)
+	countQuery, countArgs := builder.CountQuery(`Select count(*) ` + from)
+	if err := c.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
 
-	query := `Select 
+	query, args := builder.Query(`Select 
+	` + builder.KeySelect() + `,
 	cu.id as customer_id,
 	cu.first_name as customer_first_name,
 	cu.last_name as customer_last_name,
@@ -115,92 +163,215 @@ func (c *customerRepo) GetAllCustomer(ctx context.Context, req models.GetAllCust
 	cu.phone as customer_phone, 
 	cu.password as customer_password,
 	o.id,
-	--o.from_date,
-	--o.to_date,
 	o.status,
 	o.paid,
-	o.amount
-	From customers cu 
-	JOIN orders o ON  cu.id = o.customer_id
-	JOIN  cars ca ON ca.id = o.car_id
-	`
-	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
-	defer cancel()
+	o.amount` + from)
 
-	rows, err := c.db.Query(ctx, query+filter+``)
+	rows, err := c.db.Query(ctx, query, args...)
 	if err != nil {
 		return resp, err
 	}
 	defer rows.Close()
 
+	keys := []string{}
 	for rows.Next() {
 		var (
 			customer = models.GetAllCustomer{
 				Order: models.Order{},
 			}
-			// updateAt sql.NullString
+			key string
 		)
 		if err := rows.Scan(
+			&key,
 			&customer.Id,
 			&customer.FirstName,
 			&customer.LastName,
 			&customer.Gmail,
 			&customer.Phone,
 			&customer.Password,
-			// &customer.CreatedAt,
-			// &updateAt,
 			&customer.Order.Id,
-			// &customer.Order.FromDate,
-			// &customer.Order.ToDate,
 			&customer.Order.Status,
 			&customer.Order.Paid,
 			&customer.Order.Amount); err != nil {
 			return resp, err
 		}
-		// customer.UpdatedAt = pkg.NullStringToString(updateAt)
 		resp.Customers = append(resp.Customers, customer)
+		keys = append(keys, key)
 	}
 	if err = rows.Err(); err != nil {
 		return resp, err
 	}
-	countQuery := `Select count(*) from customers`
-	err = c.db.QueryRow(ctx, countQuery).Scan(&resp.Count)
-	if err != nil {
-		return resp, err
-	}
-	return resp, nil
+
+	resp.Customers, resp.NextCursor, resp.PrevCursor, err = pageRows(builder, resp.Customers, keys)
+	return resp, err
 }
 
 func (c *customerRepo) GetByID(ctx context.Context, id string) (models.Customer, error) {
-	customer := models.Customer{}
+	var (
+		customer     = models.Customer{}
+		blockReason  sql.NullString
+		blockedUntil sql.NullString
+	)
 
 	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
-	if err := c.db.QueryRow(ctx, `select id,first_name,last_name,gmail,phone,is_blocked from customers where id = $1`, id).Scan(
+	if err := c.db.QueryRow(ctx, `select
+		cu.id,
+		cu.first_name,
+		cu.last_name,
+		cu.gmail,
+		cu.phone,
+		`+blockedExpr+`,
+		cu.blocked_reason,
+		cu.blocked_until::text
+		from customers cu where cu.id = $1 and cu.deleted_at IS NULL`, id).Scan(
 		&customer.Id,
 		&customer.FirstName,
 		&customer.LastName,
 		&customer.Gmail,
 		&customer.Phone,
-		&customer.Password,
-		&customer.Is_Blocked); err != nil {
-		return models.Customer{}, err
+		&customer.Is_Blocked,
+		&blockReason,
+		&blockedUntil); err != nil {
+		return models.Customer{}, dbError(err, "customer")
+	}
+	if customer.Is_Blocked {
+		customer.BlockedReason = pkg.NullStringToString(blockReason)
+		customer.BlockedUntil = pkg.NullStringToString(blockedUntil)
 	}
 	return customer, nil
 }
 
+// Delete soft deletes the customer, the orders of the customer keep rendering until it is purged.
+// Every token of the customer is revoked, like Block does.
 func (c *customerRepo) Delete(ctx context.Context, id string) error {
-	queary := `delete from customers where id = $1`
-
 	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
-	_, err := c.db.Exec(ctx, queary, id)
+
+	tx, err := c.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `update customers set deleted_at = NOW() where id = $1 and deleted_at IS NULL`, id)
+	if err != nil {
+		return dbError(err, "customer")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("customer")
+	}
+
+	if err = revokeUserTokens(ctx, tx, id, "deleted"); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetDeleted lists the soft deleted customers, the most recently deleted first.
+func (c *customerRepo) GetDeleted(ctx context.Context, req models.GetDeletedRequest) (models.GetDeletedCustomersResponse, error) {
+	resp := models.GetDeletedCustomersResponse{Customers: []models.Customer{}}
+
+	builder := newQueryBuilder().Where("cu.deleted_at IS NOT NULL")
+	err := builder.Sort([]models.SortField{{Field: "deleted_at", Desc: true}, {Field: "id"}}, customerListColumns)
+	if err != nil {
+		return resp, err
+	}
+	builder.Page(req.Page, req.Limit)
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`select count(*) from customers cu`)
+	if err := c.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`select
+		cu.id,
+		cu.first_name,
+		cu.last_name,
+		cu.gmail,
+		cu.phone,
+		cu.is_blocked,
+		cu.created_at::text,
+		cu.updated_at::text,
+		cu.deleted_at::text
+		from customers cu`)
+	rows, err := c.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			customer  = models.Customer{}
+			createdAt sql.NullString
+			updatedAt sql.NullString
+			deletedAt sql.NullString
+		)
+		if err := rows.Scan(
+			&customer.Id,
+			&customer.FirstName,
+			&customer.LastName,
+			&customer.Gmail,
+			&customer.Phone,
+			&customer.Is_Blocked,
+			&createdAt,
+			&updatedAt,
+			&deletedAt); err != nil {
+			return resp, err
+		}
+		customer.CreatedAt = pkg.NullStringToString(createdAt)
+		customer.UpdatedAt = pkg.NullStringToString(updatedAt)
+		customer.DeletedAt = pkg.NullStringToString(deletedAt)
+		resp.Customers = append(resp.Customers, customer)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// Restore undoes the soft delete of the customer. It fails with storage.ErrRestoreConflict
+// when the phone number was registered again in the meantime.
+func (c *customerRepo) Restore(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := c.db.Exec(ctx, `update customers set
+		deleted_at = NULL,
+		updated_at = NOW()
+		where id = $1 and deleted_at IS NOT NULL`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return storage.ErrRestoreConflict.Wrap(err)
+		}
+		return dbError(err, "customer")
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotDeleted
+	}
 	return nil
 }
 
+// Purge removes the customers deleted more than retention ago for good.
+// Customers that orders still reference are kept, so the order history stays complete.
+func (c *customerRepo) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := c.db.Exec(ctx, `delete from customers cu
+		where cu.deleted_at < NOW() - make_interval(secs => $1)
+		and NOT EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = cu.id)`, retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 func (c *customerRepo) UpdateCustomerPassword(ctx context.Context, customer models.PasswordOfCustomer) (string, error) {
 	hashedNewPassword, err := bcrypt.GenerateFromPassword([]byte(customer.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -208,11 +379,11 @@ func (c *customerRepo) UpdateCustomerPassword(ctx context.Context, customer mode
 	}
 
 	var Pass_cur string
-	query := `select password from customers where phone = $1`
+	query := `select password from customers where phone = $1 and deleted_at IS NULL`
 
 	err = c.db.QueryRow(ctx, query, customer.Phone).Scan(&Pass_cur)
 	if err != nil {
-		return "error:", err
+		return "", dbError(err, "customer")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(Pass_cur), []byte(hashedNewPassword))
@@ -223,9 +394,9 @@ func (c *customerRepo) UpdateCustomerPassword(ctx context.Context, customer mode
 	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
 
-	_, err = c.db.Exec(ctx, `update customers set password = $1 where phone = $2`, hashedNewPassword, customer.Phone)
+	_, err = c.db.Exec(ctx, `update customers set password = $1 where phone = $2 and deleted_at IS NULL`, hashedNewPassword, customer.Phone)
 	if err != nil {
-		return "error:", err
+		return "", dbError(err, "customer")
 	}
 
 	return "OK", nil
@@ -236,16 +407,12 @@ func (c *customerRepo) GetPasswordforLogin(ctx context.Context, phone string) (s
 
 	query := `SELECT password
 	FROM customers
-	WHERE phone = $1 AND deleted_at = 0`
+	WHERE phone = $1 AND deleted_at IS NULL`
 
 	err := c.db.QueryRow(ctx, query, phone).Scan(&hashedPasswordforLogin)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", errors.New("phone is incorrect here")
-		} else {
-			return "", err
-		}
+		return "", dbError(err, "customer")
 	}
 
 	return hashedPasswordforLogin, nil
@@ -260,6 +427,8 @@ func (c *customerRepo) GetByLogin(ctx context.Context, login string)(models.GetA
 		email     sql.NullString
 		createdat sql.NullString
 		updatedat sql.NullString
+		blockReason  sql.NullString
+		blockedUntil sql.NullString
 	)
 
 	query := `SELECT 
@@ -270,8 +439,11 @@ func (c *customerRepo) GetByLogin(ctx context.Context, login string)(models.GetA
 		gmail,
 		created_at, 
 		updated_at,
-		password
-		FROM customers WHERE phone = $1 AND deleted_at = 0`
+		password,
+		` + blockedExpr + `,
+		blocked_reason,
+		blocked_until::text
+		FROM customers cu WHERE phone = $1 AND deleted_at IS NULL`
 
 	row := c.db.QueryRow(ctx, query, login)
 
@@ -288,10 +460,13 @@ func (c *customerRepo) GetByLogin(ctx context.Context, login string)(models.GetA
 		&createdat,
 		&updatedat,
 		&customer.Password,
+		&customer.Is_Blocked,
+		&blockReason,
+		&blockedUntil,
 	)
 
 	if err != nil {
-		return models.GetAllCustomer{}, err
+		return models.GetAllCustomer{}, dbError(err, "customer")
 	}
 
 	customer.FirstName = firstname.String
@@ -300,6 +475,149 @@ func (c *customerRepo) GetByLogin(ctx context.Context, login string)(models.GetA
 	customer.Gmail = email.String
 	customer.CreatedAt = createdat.String
 	customer.UpdatedAt = updatedat.String
+	if customer.Is_Blocked {
+		customer.BlockedReason = blockReason.String
+		customer.BlockedUntil = blockedUntil.String
+	}
 
 	return customer, nil
 }
+
+// Block blocks the customer, records the audit event and revokes every token of the customer.
+// Blocking a blocked customer replaces the reason and the end of the block.
+func (c *customerRepo) Block(ctx context.Context, event models.CustomerBlockEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `update customers set
+		is_blocked = TRUE,
+		blocked_reason = $1,
+		blocked_until = NULLIF($2, '')::timestamptz::timestamp,
+		blocked_at = NOW(),
+		updated_at = NOW()
+		where id = $3 and deleted_at IS NULL`, event.Reason, event.BlockedUntil, event.CustomerId)
+	if err != nil {
+		return dbError(err, "customer")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("customer")
+	}
+
+	if err = insertBlockEvent(ctx, tx, event); err != nil {
+		return err
+	}
+	if err = revokeUserTokens(ctx, tx, event.CustomerId, "blocked"); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Unblock lifts the block of the customer and records the audit event.
+func (c *customerRepo) Unblock(ctx context.Context, event models.CustomerBlockEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `update customers set
+		is_blocked = FALSE,
+		blocked_reason = NULL,
+		blocked_until = NULL,
+		blocked_at = NULL,
+		updated_at = NOW()
+		where id = $1 and deleted_at IS NULL and is_blocked`, event.CustomerId)
+	if err != nil {
+		return dbError(err, "customer")
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrCustomerNotBlocked
+	}
+
+	if err = insertBlockEvent(ctx, tx, event); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertBlockEvent(ctx context.Context, tx pgx.Tx, event models.CustomerBlockEvent) error {
+	_, err := tx.Exec(ctx, `insert into customer_block_events(
+		id,
+		customer_id,
+		action,
+		reason,
+		blocked_until,
+		actor_id,
+		actor_role
+	) values($1,$2,$3,$4,NULLIF($5, '')::timestamptz::timestamp,NULLIF($6, '')::uuid,$7)`,
+		uuid.NewString(),
+		event.CustomerId,
+		event.Action,
+		event.Reason,
+		event.BlockedUntil,
+		event.ActorId,
+		event.ActorRole)
+	return err
+}
+
+// GetBlockEvents is the audit trail of the customer's blocks, the latest first.
+func (c *customerRepo) GetBlockEvents(ctx context.Context, customerID string) ([]models.CustomerBlockEvent, error) {
+	events := []models.CustomerBlockEvent{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	rows, err := c.db.Query(ctx, `select
+		id,
+		customer_id,
+		action,
+		reason,
+		blocked_until::text,
+		actor_id::text,
+		actor_role,
+		created_at::text
+		from customer_block_events
+		where customer_id = $1
+		order by created_at desc, id`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event        = models.CustomerBlockEvent{}
+			blockedUntil sql.NullString
+			actorID      sql.NullString
+			actorRole    sql.NullString
+		)
+		if err := rows.Scan(
+			&event.Id,
+			&event.CustomerId,
+			&event.Action,
+			&event.Reason,
+			&blockedUntil,
+			&actorID,
+			&actorRole,
+			&event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.BlockedUntil = pkg.NullStringToString(blockedUntil)
+		event.ActorId = pkg.NullStringToString(actorID)
+		event.ActorRole = pkg.NullStringToString(actorRole)
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -3,9 +3,14 @@ package postgres
 import (
 	"context"
 	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"time"
+
 	// "rent-car/pkg/logger"
 	"testing"
 
+	"github.com/go-faker/faker/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,13 +36,12 @@ func TestUpdateCustomer(t *testing.T) {
 
 	repo := NewCustomer(db,logg)
 
-	testCustomer := models.Customer{
-		Id:         "e9ca5202-3cc7-486f-8290-1144ccfd15c8",
-		FirstName:  "UpdatedFirstName",
-		LastName:   "UpdatedLastName",
-		Gmail:      "updatedemail@example.com",
-		Phone:      "9876543210",
-		Is_Blocked: false,
+	testCustomer := models.UpdateCustomer{
+		Id:        "e9ca5202-3cc7-486f-8290-1144ccfd15c8",
+		FirstName: "UpdatedFirstName",
+		LastName:  "UpdatedLastName",
+		Gmail:     "updatedemail@example.com",
+		Phone:     "9876543210",
 	}
 
 	id, err := repo.UpdateCustomer(context.Background(), testCustomer)
@@ -105,3 +109,99 @@ func TestDeleteCustomer(t *testing.T) {
 	}
 	
 }
+
+func TestDeleteCustomerRevokesTokens(t *testing.T) {
+	repo := NewCustomer(db, logg)
+	tokens := NewToken(db)
+
+	id, err := repo.Create(context.Background(), models.Customer{
+		FirstName: faker.FirstName(),
+		Gmail:     faker.Email(),
+		Phone:     faker.Phonenumber(),
+		Password:  "Secret#123",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	issuedAt := time.Now().Add(-time.Minute).Unix()
+	if !assert.NoError(t, repo.Delete(context.Background(), id)) {
+		return
+	}
+	revoked, err := tokens.IsUserRevoked(context.Background(), id, issuedAt)
+	if assert.NoError(t, err) {
+		assert.True(t, revoked)
+	}
+}
+
+func TestBlockCustomer(t *testing.T) {
+	repo := NewCustomer(db, logg)
+
+	id, err := repo.Create(context.Background(), models.Customer{
+		FirstName: faker.FirstName(),
+		Gmail:     faker.Email(),
+		Phone:     faker.Phonenumber(),
+		Password:  "Secret#123",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = repo.Block(context.Background(), models.CustomerBlockEvent{
+		CustomerId: id,
+		Action:     config.BLOCK_ACTION_BLOCKED,
+		Reason:     "unpaid damages",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	customer, err := repo.GetByID(context.Background(), id)
+	if assert.NoError(t, err) {
+		assert.True(t, customer.Is_Blocked)
+		assert.Equal(t, "unpaid damages", customer.BlockedReason)
+	}
+
+	err = repo.Unblock(context.Background(), models.CustomerBlockEvent{
+		CustomerId: id,
+		Action:     config.BLOCK_ACTION_UNBLOCKED,
+		Reason:     "paid",
+	})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, repo.Unblock(context.Background(), models.CustomerBlockEvent{CustomerId: id, Action: config.BLOCK_ACTION_UNBLOCKED, Reason: "again"}), storage.ErrCustomerNotBlocked)
+
+	events, err := repo.GetBlockEvents(context.Background(), id)
+	if assert.NoError(t, err) && assert.Len(t, events, 2) {
+		assert.Equal(t, config.BLOCK_ACTION_UNBLOCKED, events[0].Action)
+		assert.Equal(t, config.BLOCK_ACTION_BLOCKED, events[1].Action)
+	}
+}
+
+func TestExpiredBlockIsLifted(t *testing.T) {
+	repo := NewCustomer(db, logg)
+
+	id, err := repo.Create(context.Background(), models.Customer{
+		FirstName: faker.FirstName(),
+		Gmail:     faker.Email(),
+		Phone:     faker.Phonenumber(),
+		Password:  "Secret#123",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = repo.Block(context.Background(), models.CustomerBlockEvent{
+		CustomerId:   id,
+		Action:       config.BLOCK_ACTION_BLOCKED,
+		Reason:       "late return",
+		BlockedUntil: time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	customer, err := repo.GetByID(context.Background(), id)
+	if assert.NoError(t, err) {
+		assert.False(t, customer.Is_Blocked)
+	}
+}
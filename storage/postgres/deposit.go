@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type depositRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDeposit(db *pgxpool.Pool) depositRepo {
+	return depositRepo{
+		db: db,
+	}
+}
+
+func insertDeposit(ctx context.Context, tx pgx.Tx, orderID string, amount float32) error {
+	_, err := tx.Exec(ctx, `insert into order_deposits(
+		order_id,
+		amount
+	) values($1,$2)`, orderID, amount)
+	return err
+}
+
+func (d *depositRepo) GetByOrderID(ctx context.Context, orderID string) (models.OrderDeposit, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	return getOrderDeposit(ctx, d.db, orderID)
+}
+
+// getOrderDeposit reads the deposit with the money held in it and the method it was last paid with.
+func getOrderDeposit(ctx context.Context, db rowQuerier, orderID string) (models.OrderDeposit, error) {
+	var (
+		deposit   = models.OrderDeposit{}
+		method    sql.NullString
+		settledBy sql.NullString
+		settledAt sql.NullString
+		createdAt sql.NullString
+	)
+
+	err := db.QueryRow(ctx, `select
+		d.order_id,
+		d.amount,
+		d.status,
+		d.captured,
+		d.released,
+		d.settled_by::text,
+		d.settled_at::text,
+		d.created_at::text,
+		(select method from payments
+			where order_id = d.order_id and kind = $2 and status = $3
+			order by created_at desc limit 1)
+		from order_deposits d
+		where d.order_id = $1`, orderID, config.PAYMENT_KIND_DEPOSIT, config.PAYMENT_CONFIRMED).Scan(
+		&deposit.OrderId,
+		&deposit.Amount,
+		&deposit.Status,
+		&deposit.Captured,
+		&deposit.Released,
+		&settledBy,
+		&settledAt,
+		&createdAt,
+		&method)
+	if err != nil {
+		return models.OrderDeposit{}, dbError(err, "deposit")
+	}
+	deposit.Method = pkg.NullStringToString(method)
+	deposit.SettledBy = pkg.NullStringToString(settledBy)
+	deposit.SettledAt = pkg.NullStringToString(settledAt)
+	deposit.CreatedAt = pkg.NullStringToString(createdAt)
+
+	balance, err := getOrderBalance(ctx, db, orderID)
+	if err != nil {
+		return models.OrderDeposit{}, err
+	}
+	deposit.Held = balance.DepositHeld
+	if deposit.Status == config.DEPOSIT_OPEN && deposit.Held > 0 {
+		deposit.Status = config.DEPOSIT_HELD
+	}
+	return deposit, nil
+}
+
+// Settle adds the charges to the order and records the capture and release of the held deposit.
+// The order row is locked and the split is checked against the ledger, a deposit that was settled
+// already fails with storage.ErrDepositSettled and a split that no longer matches with storage.ErrLedgerChanged.
+// A card release is written pending and its id returned, the deposit counts as settled until the release fails.
+func (d *depositRepo) Settle(ctx context.Context, req models.SettleDeposit) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var lockedID string
+	if err = tx.QueryRow(ctx, `select id from orders where id = $1 for update`, req.OrderId).Scan(&lockedID); err != nil {
+		return "", dbError(err, "order")
+	}
+
+	// a deposit reopened by a declined release keeps what was captured and released before
+	var (
+		status   string
+		captured float32
+		released float32
+	)
+	if err = tx.QueryRow(ctx, `select status, captured, released from order_deposits where order_id = $1`,
+		req.OrderId).Scan(&status, &captured, &released); err != nil {
+		return "", dbError(err, "deposit")
+	}
+	if status != config.DEPOSIT_OPEN {
+		return "", storage.ErrDepositSettled
+	}
+
+	var charged float32
+	items := make([]models.OrderLineItem, 0, len(req.Charges))
+	for _, charge := range req.Charges {
+		items = append(items, models.OrderLineItem{
+			Kind:        charge.Kind,
+			Description: charge.Description,
+			Quantity:    1,
+			UnitPrice:   charge.Amount,
+			Amount:      charge.Amount,
+		})
+		charged += charge.Amount
+	}
+	if err = insertLineItems(ctx, tx, req.OrderId, items); err != nil {
+		return "", err
+	}
+	if _, err = tx.Exec(ctx, `update orders set
+		amount = COALESCE(amount, 0) + $1,
+		updated_at = CURRENT_TIMESTAMP
+		where id = $2`, charged, req.OrderId); err != nil {
+		return "", err
+	}
+
+	balance, err := getOrderBalance(ctx, tx, req.OrderId)
+	if err != nil {
+		return "", err
+	}
+	if cents(req.Capture)+cents(req.Release) != cents(balance.DepositHeld) || cents(req.Capture) > cents(balance.Outstanding) {
+		return "", storage.ErrLedgerChanged
+	}
+
+	// card money is given back by the payment provider once the deposit is claimed
+	releaseStatus := config.PAYMENT_CONFIRMED
+	if req.Method == config.PAYMENT_METHOD_CARD {
+		releaseStatus = config.PAYMENT_PENDING
+	}
+	entries := []models.RecordPayment{
+		{Kind: config.PAYMENT_KIND_DEPOSIT_CAPTURE, Amount: req.Capture},
+		{Kind: config.PAYMENT_KIND_DEPOSIT_RELEASE, Amount: req.Release, Status: releaseStatus},
+	}
+	var pendingID string
+	for _, entry := range entries {
+		if entry.Amount <= 0 {
+			continue
+		}
+		entry.OrderId = req.OrderId
+		entry.Method = req.Method
+		entry.ActorId = req.ActorId
+		entry.ActorRole = req.ActorRole
+		id, err := insertPayment(ctx, tx, entry)
+		if err != nil {
+			return "", err
+		}
+		if entry.Status == config.PAYMENT_PENDING {
+			pendingID = id
+		}
+	}
+
+	captured += req.Capture
+	released += req.Release
+	status = config.DEPOSIT_RELEASED
+	if captured > 0 && released > 0 {
+		status = config.DEPOSIT_PARTIALLY_CAPTURED
+	} else if captured > 0 {
+		status = config.DEPOSIT_CAPTURED
+	}
+	if _, err = tx.Exec(ctx, `update order_deposits set
+		status = $1,
+		captured = $2,
+		released = $3,
+		settled_by = NULLIF($4, '')::uuid,
+		settled_at = NOW()
+		where order_id = $5`, status, captured, released, req.ActorId, req.OrderId); err != nil {
+		return "", err
+	}
+
+	if err = syncOrderPaid(ctx, tx, req.OrderId); err != nil {
+		return "", err
+	}
+
+	// a pending release announces the settlement when it is confirmed
+	if pendingID == "" {
+		if err = insertOutboxEvent(ctx, tx, config.EVENT_DEPOSIT_SETTLED, req.OrderId); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return pendingID, nil
+}
+
+// reopenDeposit puts a release the payment provider declined back into the held deposit,
+// so the deposit is settled again for the money that is still held.
+func reopenDeposit(ctx context.Context, tx pgx.Tx, orderID string, amount float32) error {
+	_, err := tx.Exec(ctx, `update order_deposits set
+		status = $1,
+		released = released - $2,
+		settled_by = NULL,
+		settled_at = NULL
+		where order_id = $3`, config.DEPOSIT_OPEN, amount, orderID)
+	return err
+}
+
+// cents compares money read back from numeric columns without float noise.
+func cents(amount float32) int64 {
+	return int64(math.Round(float64(amount) * 100))
+}
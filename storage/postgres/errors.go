@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"errors"
+	"rent-car/pkg/errs"
+	"rent-car/storage"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// postgres error codes translated into domain errors
+const (
+	uniqueViolation           = "23505"
+	foreignKeyViolation       = "23503"
+	checkViolation            = "23514"
+	notNullViolation          = "23502"
+	exclusionViolation        = "23P01"
+	invalidTextRepresentation = "22P02"
+	invalidDatetimeFormat     = "22007"
+	datetimeFieldOverflow     = "22008"
+)
+
+// constraintErrors are the domain errors of the constraints a client request can violate,
+// keyed by constraint name.
+var constraintErrors = map[string]*errs.Error{
+	"index_phone": errs.Conflict("phone_taken", "phone number is already registered").
+		WithFields(errs.FieldError{Field: "phone", Message: "is already registered"}),
+	"staff_login_key": errs.Conflict("login_taken", "login is already taken").
+		WithFields(errs.FieldError{Field: "login", Message: "is already taken"}),
+	"orders_car_id_fkey": errs.Validation("car_not_found", "car does not exist",
+		errs.FieldError{Field: "car_id", Message: "does not exist"}),
+	"car_seasonal_rates_car_id_fkey": errs.NotFound("car_not_found", "car not found"),
+	"car_seasonal_rates_check": errs.Validation("invalid_seasonal_rate", "to_date must be after from_date",
+		errs.FieldError{Field: "to_date", Message: "must be after from_date"}),
+	"order_extensions_one_pending": errs.Conflict("extension_pending", "order already has an extension waiting for review"),
+	"promo_codes_code_key": errs.Conflict("promo_code_taken", "promo code already exists").
+		WithFields(errs.FieldError{Field: "code", Message: "already exists"}),
+	"promo_redemptions_promo_code_id_fkey": errs.Conflict("promo_code_redeemed", "promo code was redeemed, deactivate it instead"),
+	"orders_customer_id_fkey": errs.Validation("customer_not_found", "customer does not exist",
+		errs.FieldError{Field: "customer_id", Message: "does not exist"}),
+	"payments_order_id_fkey":          storage.ErrOrderNotDeletable,
+	"order_deposits_order_id_fkey":    storage.ErrOrderNotDeletable,
+	"promo_redemptions_order_id_fkey": storage.ErrOrderNotDeletable,
+}
+
+// notFound is the error of a missing resource, e.g. car_not_found.
+func notFound(resource string) *errs.Error {
+	return errs.NotFound(resource+"_not_found", resource+" not found")
+}
+
+// dbError translates pgx and postgres errors into domain errors, resource names the record
+// the query works on. Other errors are returned as they are and end up as internal errors.
+func dbError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notFound(resource).Wrap(err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	if known, ok := constraintErrors[pgErr.ConstraintName]; ok {
+		return known.Wrap(err)
+	}
+
+	switch pgErr.Code {
+	case uniqueViolation, exclusionViolation:
+		return errs.Conflict(resource+"_exists", resource+" conflicts with an existing record").Wrap(err)
+	case foreignKeyViolation:
+		return errs.Conflict(resource+"_referenced", resource+" references or is referenced by another record").Wrap(err)
+	case checkViolation, notNullViolation:
+		invalid := errs.Validation("invalid_"+resource, resource+" has an invalid or missing value")
+		if pgErr.ColumnName != "" {
+			invalid = invalid.WithFields(errs.FieldError{Field: pgErr.ColumnName, Message: "is invalid or missing"})
+		}
+		return invalid.Wrap(err)
+	case invalidTextRepresentation, invalidDatetimeFormat, datetimeFieldOverflow:
+		return errs.Validation("invalid_"+resource, resource+" has a malformed value").Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"errors"
+	"rent-car/pkg/errs"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBErrorTranslatesPostgresErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind errs.Kind
+		code string
+	}{
+		{"no rows", pgx.ErrNoRows, errs.KindNotFound, "car_not_found"},
+		{"duplicate phone", &pgconn.PgError{Code: uniqueViolation, ConstraintName: "index_phone"}, errs.KindConflict, "phone_taken"},
+		{"other unique", &pgconn.PgError{Code: uniqueViolation, ConstraintName: "cars_pkey"}, errs.KindConflict, "car_exists"},
+		{"missing customer", &pgconn.PgError{Code: foreignKeyViolation, ConstraintName: "orders_customer_id_fkey"}, errs.KindValidation, "customer_not_found"},
+		{"check", &pgconn.PgError{Code: checkViolation, ColumnName: "year"}, errs.KindValidation, "invalid_car"},
+		{"bad uuid", &pgconn.PgError{Code: invalidTextRepresentation}, errs.KindValidation, "invalid_car"},
+		{"other postgres error", &pgconn.PgError{Code: "57014"}, errs.KindInternal, "internal_error"},
+		{"not a postgres error", errors.New("conn closed"), errs.KindInternal, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dbError(tt.err, "car")
+
+			e := errs.From(err)
+			assert.Equal(t, tt.kind, e.Kind)
+			assert.Equal(t, tt.code, e.Code)
+			assert.ErrorIs(t, err, tt.err)
+			// postgres text never becomes the client message
+			if tt.kind != errs.KindInternal {
+				assert.NotContains(t, err.Error(), "ERROR")
+			}
+		})
+	}
+
+	assert.NoError(t, dbError(nil, "car"))
+}
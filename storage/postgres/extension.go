@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type extensionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExtension(db *pgxpool.Pool) extensionRepo {
+	return extensionRepo{
+		db: db,
+	}
+}
+
+// Create records the extension with its price breakdown, an approved extension is applied to the order
+// right away. A pending one only checks the car is free, the days are booked when it is approved.
+func (e *extensionRepo) Create(ctx context.Context, ext models.OrderExtension) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	carID, err := lockExtendableOrder(ctx, tx, ext)
+	if err != nil {
+		return "", err
+	}
+
+	ext.Id = uuid.NewString()
+	_, err = tx.Exec(ctx, `insert into order_extensions(
+		id,
+		order_id,
+		previous_to_date,
+		to_date,
+		days,
+		amount,
+		status,
+		note,
+		requested_by,
+		requested_role
+	) values($1,$2,$3,$4,$5,$6,$7,NULLIF($8, ''),NULLIF($9, '')::uuid,NULLIF($10, ''))`,
+		ext.Id,
+		ext.OrderId,
+		ext.PreviousToDate,
+		ext.ToDate,
+		ext.Days,
+		ext.Amount,
+		ext.Status,
+		ext.Note,
+		ext.RequestedBy,
+		ext.RequestedRole)
+	if err != nil {
+		return "", dbError(err, "extension")
+	}
+
+	for _, item := range ext.LineItems {
+		_, err = tx.Exec(ctx, `insert into order_extension_items(
+			id,
+			extension_id,
+			kind,
+			description,
+			quantity,
+			unit_price,
+			amount
+		) values($1,$2,$3,$4,$5,$6,$7)`, uuid.NewString(), ext.Id, item.Kind, item.Description, item.Quantity, item.UnitPrice, item.Amount)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if ext.Status == config.EXTENSION_APPROVED {
+		if err = applyExtension(ctx, tx, carID, ext); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return ext.Id, nil
+}
+
+// Review approves or rejects a pending extension of the order, an approved one is applied to the order.
+func (e *extensionRepo) Review(ctx context.Context, review models.ReviewExtension) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ext := models.OrderExtension{}
+	err = tx.QueryRow(ctx, `select
+		id,
+		order_id,
+		previous_to_date::text,
+		to_date::text,
+		amount,
+		status
+		from order_extensions
+		where id = $1 and order_id = $2
+		for update`, review.ExtensionId, review.OrderId).Scan(
+		&ext.Id,
+		&ext.OrderId,
+		&ext.PreviousToDate,
+		&ext.ToDate,
+		&ext.Amount,
+		&ext.Status)
+	if err != nil {
+		return dbError(err, "extension")
+	}
+	if ext.Status != config.EXTENSION_PENDING {
+		return storage.ErrExtensionNotPending
+	}
+
+	if review.Status == config.EXTENSION_APPROVED {
+		carID, err := lockExtendableOrder(ctx, tx, ext)
+		if err != nil {
+			return err
+		}
+		if err = applyExtension(ctx, tx, carID, ext); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `update order_extensions set
+		status = $1,
+		reviewed_by = NULLIF($2, '')::uuid,
+		review_reason = NULLIF($3, ''),
+		reviewed_at = NOW()
+		where id = $4`, review.Status, review.ReviewerId, review.Reason, ext.Id)
+	if err != nil {
+		return dbError(err, "extension")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockExtendableOrder locks the order, which must still be new or in-process, not overdue and end on
+// ext.PreviousToDate, and checks no other booking of the car overlaps the extra days. It returns the car of the order.
+func lockExtendableOrder(ctx context.Context, tx pgx.Tx, ext models.OrderExtension) (string, error) {
+	var (
+		carID, status, toDate string
+		lateDays              int
+	)
+	if err := tx.QueryRow(ctx, `select car_id, status, to_date::text, late_days from orders where id = $1 for update`,
+		ext.OrderId).Scan(&carID, &status, &toDate, &lateDays); err != nil {
+		return "", dbError(err, "order")
+	}
+	if (status != config.STATUS_NEW && status != config.STATUS_IN_PROCESS) || toDate != ext.PreviousToDate || lateDays > 0 {
+		return "", storage.ErrOrderStatusChanged
+	}
+
+	return carID, checkBookingConflict(ctx, tx, ext.OrderId, carID, ext.PreviousToDate, ext.ToDate)
+}
+
+// applyExtension moves the to_date of the order, adds the extension items to its breakdown and queues the extended event.
+func applyExtension(ctx context.Context, tx pgx.Tx, carID string, ext models.OrderExtension) error {
+	_, err := tx.Exec(ctx, `update orders set
+		to_date = $1,
+		amount = COALESCE(amount, 0) + $2,
+		updated_at = CURRENT_TIMESTAMP
+		where id = $3`, ext.ToDate, ext.Amount, ext.OrderId)
+	if err != nil {
+		return dbError(bookingError(err, carID, ext.PreviousToDate, ext.ToDate), "order")
+	}
+
+	_, err = tx.Exec(ctx, `insert into order_line_items(
+		id,
+		order_id,
+		kind,
+		description,
+		quantity,
+		unit_price,
+		amount
+	) select gen_random_uuid(), $1, kind, left('Extension: ' || description, 100), quantity, unit_price, amount
+		from order_extension_items
+		where extension_id = $2`, ext.OrderId, ext.Id)
+	if err != nil {
+		return err
+	}
+
+	// the extra days leave something outstanding on a paid order
+	if err = syncOrderPaid(ctx, tx, ext.OrderId); err != nil {
+		return err
+	}
+	return insertOutboxEvent(ctx, tx, config.EVENT_ORDER_EXTENDED, ext.OrderId)
+}
+
+func (e *extensionRepo) GetByID(ctx context.Context, orderID, id string) (models.OrderExtension, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	extensions, err := e.getExtensions(ctx, `where order_id = $1 and id = $2`, orderID, id)
+	if err != nil {
+		return models.OrderExtension{}, err
+	}
+	if len(extensions) == 0 {
+		return models.OrderExtension{}, notFound("extension")
+	}
+	return extensions[0], nil
+}
+
+func (e *extensionRepo) GetByOrderID(ctx context.Context, orderID string) ([]models.OrderExtension, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	return e.getExtensions(ctx, `where order_id = $1`, orderID)
+}
+
+// getExtensions reads the extensions matching where, the oldest first, with their price breakdown.
+func (e *extensionRepo) getExtensions(ctx context.Context, where string, args ...any) ([]models.OrderExtension, error) {
+	extensions := []models.OrderExtension{}
+
+	rows, err := e.db.Query(ctx, `select
+		id,
+		order_id,
+		previous_to_date::text,
+		to_date::text,
+		days,
+		amount,
+		status,
+		note,
+		requested_by::text,
+		requested_role,
+		reviewed_by::text,
+		review_reason,
+		reviewed_at::text,
+		created_at::text
+		from order_extensions `+where+`
+		order by created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			ext           = models.OrderExtension{LineItems: []models.OrderLineItem{}}
+			note          sql.NullString
+			requestedBy   sql.NullString
+			requestedRole sql.NullString
+			reviewedBy    sql.NullString
+			reviewReason  sql.NullString
+			reviewedAt    sql.NullString
+			createdAt     sql.NullString
+		)
+		if err := rows.Scan(
+			&ext.Id,
+			&ext.OrderId,
+			&ext.PreviousToDate,
+			&ext.ToDate,
+			&ext.Days,
+			&ext.Amount,
+			&ext.Status,
+			&note,
+			&requestedBy,
+			&requestedRole,
+			&reviewedBy,
+			&reviewReason,
+			&reviewedAt,
+			&createdAt); err != nil {
+			return nil, err
+		}
+		ext.Note = pkg.NullStringToString(note)
+		ext.RequestedBy = pkg.NullStringToString(requestedBy)
+		ext.RequestedRole = pkg.NullStringToString(requestedRole)
+		ext.ReviewedBy = pkg.NullStringToString(reviewedBy)
+		ext.ReviewReason = pkg.NullStringToString(reviewReason)
+		ext.ReviewedAt = pkg.NullStringToString(reviewedAt)
+		ext.CreatedAt = pkg.NullStringToString(createdAt)
+
+		index[ext.Id] = len(extensions)
+		extensions = append(extensions, ext)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return extensions, nil
+	}
+
+	ids := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ids = append(ids, ext.Id)
+	}
+
+	items, err := e.db.Query(ctx, `select
+		id,
+		extension_id,
+		kind,
+		description,
+		quantity,
+		unit_price,
+		amount
+		from order_extension_items
+		where extension_id = any($1::uuid[])
+		order by amount < 0, created_at, kind`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer items.Close()
+
+	for items.Next() {
+		var (
+			item        = models.OrderLineItem{}
+			extensionID string
+		)
+		if err := items.Scan(
+			&item.Id,
+			&extensionID,
+			&item.Kind,
+			&item.Description,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Amount); err != nil {
+			return nil, err
+		}
+		i := index[extensionID]
+		extensions[i].LineItems = append(extensions[i].LineItems, item)
+	}
+	return extensions, items.Err()
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderExtension(t *testing.T) {
+	orders := NewOrder(db)
+	repo := NewExtension(db)
+	orderID := createTestOrder(t, 150)
+
+	order, err := orders.GetByID(context.Background(), orderID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	to, err := time.Parse(time.DateOnly, order.ToDate)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	extension := models.OrderExtension{
+		OrderId:        orderID,
+		PreviousToDate: order.ToDate,
+		ToDate:         to.AddDate(0, 0, 2).Format(time.DateOnly),
+		Days:           2,
+		Amount:         100,
+		LineItems: []models.OrderLineItem{
+			{Kind: config.LINE_ITEM_BASE, Description: "Daily rate", Quantity: 2, UnitPrice: 50, Amount: 100},
+		},
+		Status:        config.EXTENSION_PENDING,
+		RequestedRole: config.CUSTOMER_ROLE,
+	}
+	id, err := repo.Create(context.Background(), extension)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// one extension waits for review at a time
+	_, err = repo.Create(context.Background(), extension)
+	assert.Error(t, err)
+
+	// a pending extension leaves the order as it was
+	order, err = orders.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, extension.PreviousToDate, order.ToDate)
+		assert.Equal(t, float32(150), order.Amount)
+		assert.Len(t, order.Extensions, 1)
+	}
+
+	review := models.ReviewExtension{OrderId: orderID, ExtensionId: id, Status: config.EXTENSION_APPROVED}
+	if !assert.NoError(t, repo.Review(context.Background(), review)) {
+		return
+	}
+	assert.ErrorIs(t, repo.Review(context.Background(), review), storage.ErrExtensionNotPending)
+
+	order, err = orders.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, extension.ToDate, order.ToDate)
+		assert.Equal(t, float32(250), order.Amount)
+		if assert.Len(t, order.Extensions, 1) {
+			assert.Equal(t, config.EXTENSION_APPROVED, order.Extensions[0].Status)
+			assert.Len(t, order.Extensions[0].LineItems, 1)
+		}
+	}
+
+	// the order no longer ends on the previous to_date
+	_, err = repo.Create(context.Background(), extension)
+	assert.ErrorIs(t, err, storage.ErrOrderStatusChanged)
+}
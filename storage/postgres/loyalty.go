@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type loyaltyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewLoyalty(db *pgxpool.Pool) loyaltyRepo {
+	return loyaltyRepo{
+		db: db,
+	}
+}
+
+func (l *loyaltyRepo) GetBalance(ctx context.Context, customerID string, tierSince time.Time) (models.LoyaltyBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	return getLoyaltyBalance(ctx, l.db, customerID, tierSince)
+}
+
+// getLoyaltyBalance adds up the ledger of the customer. Debits use up the oldest credits first, so the
+// credits that expired and were not used up by the debits so far are the points still to expire.
+func getLoyaltyBalance(ctx context.Context, db rowQuerier, customerID string, tierSince time.Time) (models.LoyaltyBalance, error) {
+	var (
+		balance        = models.LoyaltyBalance{}
+		total          int
+		expiredCredits int
+		debits         int
+	)
+	err := db.QueryRow(ctx, `select
+		COALESCE(sum(points), 0),
+		COALESCE(sum(points) filter (where points > 0 and expires_at <= NOW()), 0),
+		COALESCE(-sum(points) filter (where points < 0), 0),
+		COALESCE(sum(points) filter (where kind = $2 and created_at >= $3), 0)
+		from loyalty_points
+		where customer_id = $1`, customerID, config.LOYALTY_EARN, tierSince).Scan(
+		&total,
+		&expiredCredits,
+		&debits,
+		&balance.TierPoints)
+	if err != nil {
+		return models.LoyaltyBalance{}, err
+	}
+	balance.Expired = max(expiredCredits-debits, 0)
+	balance.Points = total - balance.Expired
+	return balance, nil
+}
+
+// GetHistory returns a page of the ledger of the customer, the newest entries first.
+func (l *loyaltyRepo) GetHistory(ctx context.Context, req models.GetLoyaltyRequest) (models.LoyaltyHistory, error) {
+	history := models.LoyaltyHistory{Entries: []models.LoyaltyEntry{}}
+
+	builder := newQueryBuilder().Where("customer_id = ?", req.CustomerId)
+	err := builder.Sort([]models.SortField{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}, loyaltyListColumns)
+	if err != nil {
+		return history, err
+	}
+	builder.Page(req.Page, req.Limit)
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`select count(*) from loyalty_points`)
+	if err := l.db.QueryRow(ctx, countQuery, countArgs...).Scan(&history.Count); err != nil {
+		return history, err
+	}
+
+	query, args := builder.Query(`select
+		id,
+		customer_id,
+		order_id::text,
+		kind,
+		points,
+		expires_at::text,
+		created_at::text
+		from loyalty_points`)
+	rows, err := l.db.Query(ctx, query, args...)
+	if err != nil {
+		return history, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			entry     = models.LoyaltyEntry{}
+			orderID   sql.NullString
+			expiresAt sql.NullString
+		)
+		if err := rows.Scan(
+			&entry.Id,
+			&entry.CustomerId,
+			&orderID,
+			&entry.Kind,
+			&entry.Points,
+			&expiresAt,
+			&entry.CreatedAt); err != nil {
+			return history, err
+		}
+		entry.OrderId = pkg.NullStringToString(orderID)
+		entry.ExpiresAt = pkg.NullStringToString(expiresAt)
+		history.Entries = append(history.Entries, entry)
+	}
+	return history, rows.Err()
+}
+
+var loyaltyListColumns = map[string]listColumn{
+	"id":         {expr: "id", cast: "uuid"},
+	"created_at": {expr: "created_at", cast: "timestamp"},
+}
+
+// GetExpired returns customers with expired points that are not recorded as expired yet.
+func (l *loyaltyRepo) GetExpired(ctx context.Context, limit int) ([]string, error) {
+	customers := []string{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	rows, err := l.db.Query(ctx, `select customer_id
+		from loyalty_points
+		group by customer_id
+		having COALESCE(sum(points) filter (where points > 0 and expires_at <= NOW()), 0) +
+			COALESCE(sum(points) filter (where points < 0), 0) > 0
+		limit $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var customerID string
+		if err := rows.Scan(&customerID); err != nil {
+			return nil, err
+		}
+		customers = append(customers, customerID)
+	}
+	return customers, rows.Err()
+}
+
+// Expire records the points of the customer that expired and returns how many.
+func (l *loyaltyRepo) Expire(ctx context.Context, customerID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := l.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = lockLoyaltyCustomer(ctx, tx, customerID); err != nil {
+		return 0, err
+	}
+	balance, err := expireLoyaltyPoints(ctx, tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return balance.Expired, nil
+}
+
+// lockLoyaltyCustomer serializes the changes to the points of one customer.
+func lockLoyaltyCustomer(ctx context.Context, tx pgx.Tx, customerID string) error {
+	var lockedID string
+	if err := tx.QueryRow(ctx, `select id from customers where id = $1 for update`, customerID).Scan(&lockedID); err != nil {
+		return dbError(err, "customer")
+	}
+	return nil
+}
+
+// expireLoyaltyPoints records the expired points of the locked customer and returns the balance before.
+func expireLoyaltyPoints(ctx context.Context, tx pgx.Tx, customerID string) (models.LoyaltyBalance, error) {
+	balance, err := getLoyaltyBalance(ctx, tx, customerID, time.Now())
+	if err != nil {
+		return balance, err
+	}
+	if balance.Expired > 0 {
+		err = insertLoyaltyEntry(ctx, tx, models.LoyaltyEntry{
+			CustomerId: customerID,
+			Kind:       config.LOYALTY_EXPIRE,
+			Points:     -balance.Expired,
+		})
+	}
+	return balance, err
+}
+
+// redeemLoyaltyPoints spends the points of entry on its order, the locked customer must have them.
+func redeemLoyaltyPoints(ctx context.Context, tx pgx.Tx, entry models.LoyaltyEntry) error {
+	if err := lockLoyaltyCustomer(ctx, tx, entry.CustomerId); err != nil {
+		return err
+	}
+	balance, err := expireLoyaltyPoints(ctx, tx, entry.CustomerId)
+	if err != nil {
+		return err
+	}
+	if balance.Points < -entry.Points {
+		return storage.ErrNotEnoughPoints
+	}
+	return insertLoyaltyEntry(ctx, tx, entry)
+}
+
+func insertLoyaltyEntry(ctx context.Context, tx pgx.Tx, entry models.LoyaltyEntry) error {
+	_, err := tx.Exec(ctx, `insert into loyalty_points(
+		id,
+		customer_id,
+		order_id,
+		kind,
+		points,
+		expires_at
+	) values($1,$2,NULLIF($3, '')::uuid,$4,$5,NULLIF($6, '')::timestamptz)`,
+		uuid.NewString(),
+		entry.CustomerId,
+		entry.OrderId,
+		entry.Kind,
+		entry.Points,
+		entry.ExpiresAt)
+	return dbError(err, "loyalty_points")
+}
+
+// refundLoyaltyPoints gives the points spent on the canceled order back, they expire at expiresAt.
+func refundLoyaltyPoints(ctx context.Context, tx pgx.Tx, orderID, expiresAt string) error {
+	_, err := tx.Exec(ctx, `insert into loyalty_points(
+		id,
+		customer_id,
+		order_id,
+		kind,
+		points,
+		expires_at
+	) select gen_random_uuid(), customer_id, order_id, $2, -points, NULLIF($3, '')::timestamptz
+		from loyalty_points
+		where order_id = $1 and kind = $4`, orderID, config.LOYALTY_REFUND, expiresAt, config.LOYALTY_REDEEM)
+	return err
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoyaltyLedger(t *testing.T) {
+	orders := NewOrder(db)
+	orderID := createTestOrder(t, 100)
+
+	order, err := orders.GetByID(context.Background(), orderID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	customerID := order.CustomerId
+
+	tx, err := db.Begin(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tx.Rollback(context.Background())
+
+	// an old lot that expired and a fresh one
+	for _, entry := range []models.LoyaltyEntry{
+		{CustomerId: customerID, Kind: config.LOYALTY_EARN, Points: 40, ExpiresAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+		{CustomerId: customerID, OrderId: orderID, Kind: config.LOYALTY_EARN, Points: 100, ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+	} {
+		if !assert.NoError(t, insertLoyaltyEntry(context.Background(), tx, entry)) {
+			return
+		}
+	}
+
+	balance, err := getLoyaltyBalance(context.Background(), tx, customerID, time.Now().Add(-24*time.Hour))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100, balance.Points)
+		assert.Equal(t, 40, balance.Expired)
+		assert.Equal(t, 140, balance.TierPoints)
+	}
+
+	redeem := models.LoyaltyEntry{CustomerId: customerID, Kind: config.LOYALTY_REDEEM, Points: -101}
+	assert.ErrorIs(t, redeemLoyaltyPoints(context.Background(), tx, redeem), storage.ErrNotEnoughPoints)
+
+	redeem.Points = -60
+	if !assert.NoError(t, redeemLoyaltyPoints(context.Background(), tx, redeem)) {
+		return
+	}
+
+	// the expiry is recorded once, the rest of the fresh lot is left
+	balance, err = getLoyaltyBalance(context.Background(), tx, customerID, time.Now())
+	if assert.NoError(t, err) {
+		assert.Equal(t, 40, balance.Points)
+		assert.Equal(t, 0, balance.Expired)
+	}
+
+	// the ledger is immutable
+	_, err = tx.Exec(context.Background(), `update loyalty_points set points = 1000 where customer_id = $1`, customerID)
+	assert.Error(t, err)
+}
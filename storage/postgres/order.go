@@ -3,13 +3,16 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"rent-car/api/models"
 	"rent-car/config"
 	"rent-car/pkg"
-	"rent-car/tgbot"
+	"rent-car/storage"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -34,13 +37,68 @@ func (o *orderRepo) Create(ctx context.Context, or models.CreateOrder) (string,
 		to_date,
 		status,
 		paid,
-		amount
-	) values($1,$2,$3,$4,$5,$6,$7,$8)`
+		amount,
+		discount
+	) values($1,$2,$3,$4,$5,$6,false,$7,$8)`
 
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
-	_, err := o.db.Exec(ctx, query, id.String(), or.CarId, or.CustomerId, or.FromDate, or.ToDate, or.Status, or.Paid, or.Amount)
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = checkBookingConflict(ctx, tx, id.String(), or.CarId, or.FromDate, or.ToDate); err != nil {
+		return "", err
+	}
+
+	discount := float32(0)
+	if or.Redemption != nil {
+		discount = or.Redemption.Discount
+	}
+	_, err = tx.Exec(ctx, query, id.String(), or.CarId, or.CustomerId, or.FromDate, or.ToDate, or.Status, or.Amount, discount)
 	if err != nil {
+		return "", dbError(bookingError(err, or.CarId, or.FromDate, or.ToDate), "order")
+	}
+
+	if or.Redemption != nil {
+		if err = redeemPromoCode(ctx, tx, id.String(), *or.Redemption); err != nil {
+			return "", err
+		}
+	}
+
+	if or.LoyaltyRedemption != nil {
+		redemption := *or.LoyaltyRedemption
+		redemption.OrderId = id.String()
+		if err = redeemLoyaltyPoints(ctx, tx, redemption); err != nil {
+			return "", err
+		}
+	}
+
+	if err = insertLineItems(ctx, tx, id.String(), or.LineItems); err != nil {
+		return "", err
+	}
+
+	if err = insertDeposit(ctx, tx, id.String(), or.Deposit); err != nil {
+		return "", err
+	}
+
+	if err = insertStatusHistory(ctx, tx, models.OrderStatusChange{
+		OrderId:   id.String(),
+		ToStatus:  or.Status,
+		ActorId:   or.ActorId,
+		ActorRole: or.ActorRole,
+	}); err != nil {
+		return "", err
+	}
+
+	if err = insertOutboxEvent(ctx, tx, config.EVENT_ORDER_CREATED, id.String()); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
 		return "", err
 	}
 	return id.String(), nil
@@ -50,42 +108,224 @@ func (o *orderRepo) Update(ctx context.Context, or models.UpdateOrder) (string,
 	query := `update orders set
 	   from_date=$1,
 	   to_date=$2,
-	   status=$3,
-	   paid=$4,
-	   amount=$5,
+	   amount=$3,
+	   discount=$4,
        updated_at=CURRENT_TIMESTAMP
-	   WHERE id=$6
+	   WHERE id=$5
 	`
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
-	_, err := o.db.Exec(ctx, query, or.FromDate, or.ToDate, or.Status, or.Paid, or.Amount, or.Id)
+
+	tx, err := o.db.Begin(ctx)
 	if err != nil {
 		return "", err
 	}
+	defer tx.Rollback(ctx)
+
+	var carID, status string
+	if err = tx.QueryRow(ctx, `select car_id, status from orders where id = $1 for update`, or.Id).Scan(&carID, &status); err != nil {
+		return "", dbError(err, "order")
+	}
+
+	if status != config.STATUS_CANCELED {
+		if err = checkBookingConflict(ctx, tx, or.Id, carID, or.FromDate, or.ToDate); err != nil {
+			return "", err
+		}
+	}
+
+	_, err = tx.Exec(ctx, query, or.FromDate, or.ToDate, or.Amount, or.Discount, or.Id)
+	if err != nil {
+		return "", dbError(bookingError(err, carID, or.FromDate, or.ToDate), "order")
+	}
+
+	// the promo code is repriced with the order
+	if _, err = tx.Exec(ctx, `update promo_redemptions set discount = $1 where order_id = $2`, or.Discount, or.Id); err != nil {
+		return "", err
+	}
+
+	if _, err = tx.Exec(ctx, `delete from order_line_items where order_id = $1`, or.Id); err != nil {
+		return "", err
+	}
+
+	if err = insertLineItems(ctx, tx, or.Id, or.LineItems); err != nil {
+		return "", err
+	}
+
+	// the new amount may settle the order or leave something outstanding
+	if err = syncOrderPaid(ctx, tx, or.Id); err != nil {
+		return "", err
+	}
+
+	if err = insertOutboxEvent(ctx, tx, config.EVENT_ORDER_UPDATED, or.Id); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
+	}
 	return or.Id, nil
 }
 
+func insertLineItems(ctx context.Context, tx pgx.Tx, orderID string, items []models.OrderLineItem) error {
+	query := `insert into order_line_items(
+		id,
+		order_id,
+		kind,
+		description,
+		quantity,
+		unit_price,
+		amount
+	) values($1,$2,$3,$4,$5,$6,$7)`
+
+	for _, item := range items {
+		_, err := tx.Exec(ctx, query, uuid.NewString(), orderID, item.Kind, item.Description, item.Quantity, item.UnitPrice, item.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *orderRepo) GetLineItems(ctx context.Context, orderID string) ([]models.OrderLineItem, error) {
+	items := []models.OrderLineItem{}
+
+	rows, err := o.db.Query(ctx, `select
+		id,
+		kind,
+		description,
+		quantity,
+		unit_price,
+		amount
+		from order_line_items
+		where order_id = $1
+		order by amount < 0, created_at, kind`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := models.OrderLineItem{}
+		if err := rows.Scan(
+			&item.Id,
+			&item.Kind,
+			&item.Description,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// checkBookingConflict locks the car row, so bookings and deletes of one car are serialized,
+// and fails when [fromDate, toDate) overlaps another not canceled order of the car.
+func checkBookingConflict(ctx context.Context, tx pgx.Tx, orderID, carID, fromDate, toDate string) error {
+	var lockedID string
+	if err := tx.QueryRow(ctx, `select id from cars where id = $1 and deleted_at IS NULL for update`, carID).Scan(&lockedID); err != nil {
+		return dbError(err, "car")
+	}
+
+	var conflictID string
+	err := tx.QueryRow(ctx, `select id from orders
+		where car_id = $1 and status <> $2 and id <> $3
+		and daterange(from_date, to_date, '[)') && daterange($4::date, $5::date, '[)')
+		limit 1`, carID, config.STATUS_CANCELED, orderID, fromDate, toDate).Scan(&conflictID)
+	if err == nil {
+		return &storage.BookingConflictError{
+			CarID:              carID,
+			FromDate:           fromDate,
+			ToDate:             toDate,
+			ConflictingOrderID: conflictID,
+		}
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// bookingError turns a violation of the orders_car_no_overlap exclusion constraint into a BookingConflictError.
+func bookingError(err error, carID, fromDate, toDate string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+		return &storage.BookingConflictError{
+			CarID:    carID,
+			FromDate: fromDate,
+			ToDate:   toDate,
+		}
+	}
+	return err
+}
+
+// orderListColumns maps the filterable and sortable fields of the order list to columns.
+var orderListColumns = map[string]listColumn{
+	"id":          {expr: "o.id", cast: "uuid"},
+	"status":      {expr: "o.status", cast: "text"},
+	"paid":        {expr: "o.paid", cast: "boolean"},
+	"amount":      {expr: "o.amount", cast: "numeric"},
+	"from_date":   {expr: "o.from_date", cast: "date"},
+	"to_date":     {expr: "o.to_date", cast: "date"},
+	"created_at":  {expr: "o.created_at", cast: "timestamp"},
+	"car_id":      {expr: "o.car_id::text", cast: "text"},
+	"customer_id": {expr: "o.customer_id::text", cast: "text"},
+	"car_brand":   {expr: "c.brand", cast: "text"},
+	"overdue":     {expr: "(o.overdue_since IS NOT NULL)", cast: "boolean"},
+}
+
 func (o *orderRepo) GetAll(ctx context.Context, req models.GetAllOrdersRequest) (models.GetAllOrdersResponse, error) {
-	var (
-		resp   = models.GetAllOrdersResponse{}
-		filter = ""
-	)
-	offset := (req.Page - 1) * req.Limit
-	if req.Search != "" {
-		filter += fmt.Sprintf(`and  status ILIKE '%%%v%%'`, req.Search)
+	resp := models.GetAllOrdersResponse{}
+
+	builder := newQueryBuilder().
+		Search(req.Search, "o.status")
+
+	if err := builder.Filter(req.Filters, orderListColumns); err != nil {
+		return resp, err
+	}
+
+	sorts := req.Sort
+	if len(sorts) == 0 {
+		sorts = []models.SortField{{Field: "created_at", Desc: true}}
+	}
+	if err := builder.Sort(append(sorts, models.SortField{Field: "id"}), orderListColumns); err != nil {
+		return resp, err
 	}
-	filter += fmt.Sprintf(" OFFSET %v LIMIT %v", offset, req.Limit)
-	fmt.Println("filter:", filter)
 
-	query := `Select 
+	if req.UseCursor {
+		if err := builder.Cursor(req.Cursor, req.Limit); err != nil {
+			return resp, err
+		}
+	} else {
+		builder.Page(req.Page, req.Limit)
+	}
+
+	from := `
+	From orders o JOIN cars c ON o.car_id = c.id
+	JOIN customers cu ON o.customer_id = cu.id 	`
+
+	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`SELECT COUNT(*) ` + from)
+	if err := o.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`Select 
+	` + builder.KeySelect() + `,
 	o.id,
-	--o.from_date,
-	--o.to_date,
+	o.from_date::text,
+	o.to_date::text,
 	o.status,
 	o.paid,
 	o.amount,
-	--o.created_at,
-	--o.updated_at,
+	o.created_at::text,
+	o.updated_at::text,
 	c.name as car_name,
 	c.brand as car_brand,
 	c.engine_cap as car_engine_cap,
@@ -93,36 +333,37 @@ func (o *orderRepo) GetAll(ctx context.Context, req models.GetAllOrdersRequest)
 	cu.first_name as customer_first_name,
 	cu.last_name as customer_last_name,
 	cu.gmail as customer_gmail,
-	cu.phone as customer_phone,
-	cu.password as customer_password,
-	From orders o JOIN cars c ON o.car_id = c.id
-	JOIN customers cu ON o.customer_id = cu.id 	`
-	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
-	defer cancel()
-	rows, err := o.db.Query(ctx, query+filter+``)
+	cu.phone as customer_phone` + from)
+
+	rows, err := o.db.Query(ctx, query, args...)
 	if err != nil {
 		return resp, err
 	}
 	defer rows.Close()
 
+	keys := []string{}
 	for rows.Next() {
 		var (
 			order = models.GetOrder{
 				Car:      models.Car{},
 				Customer: models.Customer{},
 			}
-			updateAt sql.NullString
+			key       string
+			amount    sql.NullFloat64
+			createdAt sql.NullString
+			updateAt  sql.NullString
 		)
 
 		err := rows.Scan(
+			&key,
 			&order.Id,
-			// &order.FromDate,
-			// &order.ToDate,
+			&order.FromDate,
+			&order.ToDate,
 			&order.Status,
 			&order.Paid,
-			&order.Amount,
-			// &order.CreatedAt,
-			// &updateAt,
+			&amount,
+			&createdAt,
+			&updateAt,
 			&order.Car.Name,
 			&order.Car.Brand,
 			&order.Car.EngineCap,
@@ -133,23 +374,30 @@ func (o *orderRepo) GetAll(ctx context.Context, req models.GetAllOrdersRequest)
 		if err != nil {
 			return resp, err
 		}
+		order.Amount = float32(pkg.NullFloatToFloat(amount))
+		order.CreatedAt = pkg.NullStringToString(createdAt)
 		order.UpdatedAt = pkg.NullStringToString(updateAt)
 		resp.Orders = append(resp.Orders, order)
+		keys = append(keys, key)
 	}
 	if err = rows.Err(); err != nil {
 		return resp, err
 	}
-	countQuery := `SELECT COUNT(*) FROM orders`
 
-	err = o.db.QueryRow(ctx, countQuery).Scan(&resp.Count)
-	if err != nil {
-		return resp, err
-	}
-	return resp, nil
+	resp.Orders, resp.NextCursor, resp.PrevCursor, err = pageRows(builder, resp.Orders, keys)
+	return resp, err
 }
 
 func (o *orderRepo) GetByID(ctx context.Context, id string) (models.OrderAll, error) {
-	order := models.OrderAll{}
+	var (
+		order          = models.OrderAll{}
+		amount         sql.NullFloat64
+		createdAt      sql.NullString
+		updatedAt      sql.NullString
+		overdueSince   sql.NullString
+		actualReturnAt sql.NullString
+		promoCode      sql.NullString
+	)
 
 	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
 	defer cancel()
@@ -158,88 +406,494 @@ func (o *orderRepo) GetByID(ctx context.Context, id string) (models.OrderAll, er
 	 	o.id as order_id,
 		o.car_id,
 		o.customer_id,
-	 	--o.from_date,
-	 	--o.to_date,
+	 	o.from_date::text,
+	 	o.to_date::text,
 	 	o.status,
-	 	o.paid
-	 	--o.created_at,
-	 	--o.updated_at 
+	 	o.paid,
+		o.amount,
+		o.discount,
+		p.code,
+	 	o.created_at::text,
+	 	o.updated_at::text,
+		o.overdue_since::text,
+		o.late_days,
+		o.actual_return_at::text
 		from orders o
-	 	where id = $1`, id).Scan(
+		left join promo_redemptions r on r.order_id = o.id
+		left join promo_codes p on p.id = r.promo_code_id
+	 	where o.id = $1`, id).Scan(
 		&order.Id,
 		&order.CarId,
 		&order.CustomerId,
-		// &order.FromDate,
-		// &order.ToDate,
+		&order.FromDate,
+		&order.ToDate,
 		&order.Status,
 		&order.Paid,
-	// &order.CreatedAt,
-	//&order.UpdatedAt
+		&amount,
+		&order.Discount,
+		&promoCode,
+		&createdAt,
+		&updatedAt,
+		&overdueSince,
+		&order.LateDays,
+		&actualReturnAt,
 	); err != nil {
+		return models.OrderAll{}, dbError(err, "order")
+	}
+	order.Amount = float32(pkg.NullFloatToFloat(amount))
+	order.CreatedAt = pkg.NullStringToString(createdAt)
+	order.UpdatedAt = pkg.NullStringToString(updatedAt)
+	order.OverdueSince = pkg.NullStringToString(overdueSince)
+	order.ActualReturnAt = pkg.NullStringToString(actualReturnAt)
+	order.PromoCode = pkg.NullStringToString(promoCode)
+
+	lineItems, err := o.GetLineItems(ctx, id)
+	if err != nil {
+		return models.OrderAll{}, err
+	}
+	order.LineItems = lineItems
+
+	history, err := o.GetStatusHistory(ctx, id)
+	if err != nil {
+		return models.OrderAll{}, err
+	}
+	order.StatusHistory = history
+
+	balance, err := getOrderBalance(ctx, o.db, id)
+	if err != nil {
+		return models.OrderAll{}, err
+	}
+	order.Balance = balance
+
+	deposit, err := getOrderDeposit(ctx, o.db, id)
+	if err != nil {
+		return models.OrderAll{}, err
+	}
+	order.Deposit = deposit
+
+	cancellation, err := getCancellation(ctx, o.db, id)
+	if err != nil {
 		return models.OrderAll{}, err
 	}
+	order.Cancellation = cancellation
+
+	extensions := NewExtension(o.db)
+	order.Extensions, err = extensions.getExtensions(ctx, `where order_id = $1`, id)
+	if err != nil {
+		return models.OrderAll{}, err
+	}
+
 	return order, nil
 }
 
 
+// Delete removes a new order that has nothing in the ledger. Payments, promo redemptions and loyalty points
+// outlive their order, an order with any of them or past new fails with storage.ErrOrderNotDeletable.
 func (o *orderRepo) Delete(ctx context.Context, id string) error {
-    query := `DELETE FROM orders WHERE id = $1`
-
-	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
 	defer cancel()
-    _, err := o.db.Exec(ctx, query, id)
-    if err != nil {
-        return err
-    }
-    return nil
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	if err = tx.QueryRow(ctx, `select status from orders where id = $1 for update`, id).Scan(&status); err != nil {
+		return dbError(err, "order")
+	}
+
+	var inLedger bool
+	if err = tx.QueryRow(ctx, `select
+		exists(select 1 from payments where order_id = $1)
+		or exists(select 1 from promo_redemptions where order_id = $1)
+		or exists(select 1 from loyalty_points where order_id = $1)`, id).Scan(&inLedger); err != nil {
+		return err
+	}
+	if status != config.STATUS_NEW || inLedger {
+		return storage.ErrOrderNotDeletable
+	}
+
+	// nothing was paid into the deposit
+	if _, err = tx.Exec(ctx, `delete from order_deposits where order_id = $1`, id); err != nil {
+		return dbError(err, "deposit")
+	}
+	if _, err = tx.Exec(ctx, `delete from orders where id = $1`, id); err != nil {
+		return dbError(err, "order")
+	}
+
+	return tx.Commit(ctx)
 }
 
-func (o *orderRepo) UpdateOrderStatus(ctx context.Context,order models.GetOrder) (string, error) {
+// UpdateOrderStatus moves the order to change.ToStatus only while it is still in change.FromStatus
+// and records the transition in the status history. A finished order keeps its return time, is
+// charged change.LateFee and earns the customer change.Loyalty.
+func (o *orderRepo) UpdateOrderStatus(ctx context.Context,change models.OrderStatusChange) (string, error) {
 	query := `update orders set 
         status = $1,
         updated_at = CURRENT_TIMESTAMP
-        where id = $2`
+        where id = $2 and status = $3`
 
-	_, err := o.db.Exec(ctx,query,
-		order.Status, 
-		order.Id)
+	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
+	defer cancel()
 
+	tx, err := o.db.Begin(ctx)
 	if err != nil {
 		return "", err
 	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,query,
+		change.ToStatus, 
+		change.OrderId,
+		change.FromStatus)
+	if err != nil {
+		return "", dbError(err, "order")
+	}
+	if tag.RowsAffected() == 0 {
+		return "", storage.ErrOrderStatusChanged
+	}
+
+	if change.ToStatus == config.STATUS_FINISHED {
+		if _, err = tx.Exec(ctx, `update orders set actual_return_at = COALESCE(NULLIF($1, '')::timestamptz, NOW()) where id = $2`,
+			change.ActualReturnAt, change.OrderId); err != nil {
+			return "", err
+		}
+		if err = chargeLateFee(ctx, tx, change.LateFee, config.STATUS_FINISHED); err != nil {
+			return "", err
+		}
+		if change.Loyalty.Points > 0 {
+			if err = insertLoyaltyEntry(ctx, tx, change.Loyalty); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err = insertStatusHistory(ctx, tx, change); err != nil {
+		return "", err
+	}
+
+	if err = insertOutboxEvent(ctx, tx, config.EVENT_ORDER_STATUS, change.OrderId); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return change.OrderId, nil
+}
+
+// GetOverdue returns in-process orders whose to_date and grace period have passed, the oldest first.
+func (o *orderRepo) GetOverdue(ctx context.Context, grace time.Duration, limit int) ([]models.OverdueOrder, error) {
+	orders := []models.OverdueOrder{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	rows, err := o.db.Query(ctx, `select
+		id,
+		car_id,
+		to_date::text,
+		late_days
+		from orders
+		where status = $1 and to_date + make_interval(secs => $2) < NOW()
+		order by to_date, id
+		limit $3`, config.STATUS_IN_PROCESS, grace.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order := models.OverdueOrder{}
+		if err := rows.Scan(
+			&order.Id,
+			&order.CarId,
+			&order.ToDate,
+			&order.LateDays); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ChargeLateFee flags the in-process order as overdue, charges the fee and queues the overdue event.
+func (o *orderRepo) ChargeLateFee(ctx context.Context, fee models.LateFee) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = chargeLateFee(ctx, tx, fee, config.STATUS_IN_PROCESS); err != nil {
+		return err
+	}
+
+	if err = insertOutboxEvent(ctx, tx, config.EVENT_ORDER_OVERDUE, fee.OrderId); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// chargeLateFee adds the fee items to the order in status, the late days already charged must still be
+// fee.ChargedDays, so a day is never charged twice.
+func chargeLateFee(ctx context.Context, tx pgx.Tx, fee models.LateFee, status string) error {
+	if fee.LateDays <= fee.ChargedDays {
+		return nil
+	}
+
+	var charged float32
+	for _, item := range fee.Items {
+		charged += item.Amount
+	}
+
+	tag, err := tx.Exec(ctx, `update orders set
+		late_days = $1,
+		overdue_since = COALESCE(overdue_since, NOW()),
+		amount = COALESCE(amount, 0) + $2,
+		updated_at = CURRENT_TIMESTAMP
+		where id = $3 and late_days = $4 and status = $5`,
+		fee.LateDays, charged, fee.OrderId, fee.ChargedDays, status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrOrderStatusChanged
+	}
 
-   MSGinfo,err:=o.GetMSGINFO(context.Background(),order.Id)
-if err != nil {
-	return "", err
+	if err = insertLineItems(ctx, tx, fee.OrderId, fee.Items); err != nil {
+		return err
+	}
+	return syncOrderPaid(ctx, tx, fee.OrderId)
 }
 
-err=tgbot.SendMessageTG(MSGinfo)
-if err != nil {
-	return "", err
+// Cancel moves the order from cancellation.FromStatus to canceled, records the refund in the ledger,
+// gives back the loyalty points spent on the order and keeps the applied policy. The refund must not exceed the paid amount, see storage.ErrLedgerChanged.
+// It returns the ledger ids of the refunds, card refunds are written pending and moved by the payment provider afterwards.
+func (o *orderRepo) Cancel(ctx context.Context, cancellation models.OrderCancellation) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `update orders set
+		status = $1,
+		updated_at = CURRENT_TIMESTAMP
+		where id = $2 and status = $3`, config.STATUS_CANCELED, cancellation.OrderId, cancellation.FromStatus)
+	if err != nil {
+		return nil, dbError(err, "order")
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, storage.ErrOrderStatusChanged
+	}
+
+	balance, err := getOrderBalance(ctx, tx, cancellation.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if cents(cancellation.RefundAmount) > cents(balance.PaidAmount) {
+		return nil, storage.ErrLedgerChanged
+	}
+	refundIDs := make([]string, 0, len(cancellation.Refunds))
+	for _, refund := range cancellation.Refunds {
+		id, err := insertPayment(ctx, tx, refund)
+		if err != nil {
+			return nil, err
+		}
+		refundIDs = append(refundIDs, id)
+	}
+
+	if err = refundLoyaltyPoints(ctx, tx, cancellation.OrderId, cancellation.PointsExpireAt); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `insert into order_cancellations(
+		order_id,
+		policy,
+		refund_percent,
+		refund_amount,
+		reason,
+		actor_id,
+		actor_role
+	) values($1,$2,$3,$4,NULLIF($5, ''),NULLIF($6, '')::uuid,NULLIF($7, ''))`,
+		cancellation.OrderId,
+		cancellation.Policy,
+		cancellation.RefundPercent,
+		cancellation.RefundAmount,
+		cancellation.Reason,
+		cancellation.ActorId,
+		cancellation.ActorRole)
+	if err != nil {
+		return nil, dbError(err, "cancellation")
+	}
+
+	if err = insertStatusHistory(ctx, tx, models.OrderStatusChange{
+		OrderId:    cancellation.OrderId,
+		FromStatus: cancellation.FromStatus,
+		ToStatus:   config.STATUS_CANCELED,
+		ActorId:    cancellation.ActorId,
+		ActorRole:  cancellation.ActorRole,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = syncOrderPaid(ctx, tx, cancellation.OrderId); err != nil {
+		return nil, err
+	}
+
+	if err = insertOutboxEvent(ctx, tx, config.EVENT_ORDER_STATUS, cancellation.OrderId); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return refundIDs, nil
+}
+
+// getCancellation returns nil for an order that was not canceled with a policy.
+func getCancellation(ctx context.Context, db rowQuerier, orderID string) (*models.OrderCancellation, error) {
+	var (
+		cancellation = models.OrderCancellation{}
+		reason       sql.NullString
+		actorID      sql.NullString
+		actorRole    sql.NullString
+		createdAt    sql.NullString
+	)
+
+	err := db.QueryRow(ctx, `select
+		order_id,
+		policy,
+		refund_percent,
+		refund_amount,
+		reason,
+		actor_id::text,
+		actor_role,
+		created_at::text
+		from order_cancellations
+		where order_id = $1`, orderID).Scan(
+		&cancellation.OrderId,
+		&cancellation.Policy,
+		&cancellation.RefundPercent,
+		&cancellation.RefundAmount,
+		&reason,
+		&actorID,
+		&actorRole,
+		&createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cancellation.Reason = pkg.NullStringToString(reason)
+	cancellation.ActorId = pkg.NullStringToString(actorID)
+	cancellation.ActorRole = pkg.NullStringToString(actorRole)
+	cancellation.CreatedAt = pkg.NullStringToString(createdAt)
+	return &cancellation, nil
 }
-	return order.Id, nil
+
+func insertStatusHistory(ctx context.Context, tx pgx.Tx, change models.OrderStatusChange) error {
+	query := `insert into order_status_history(
+		id,
+		order_id,
+		from_status,
+		to_status,
+		actor_id,
+		actor_role
+	) values($1,$2,NULLIF($3, ''),$4,NULLIF($5, '')::uuid,NULLIF($6, ''))`
+
+	_, err := tx.Exec(ctx, query, uuid.NewString(), change.OrderId, change.FromStatus, change.ToStatus, change.ActorId, change.ActorRole)
+	return err
+}
+
+func (o *orderRepo) GetStatusHistory(ctx context.Context, orderID string) ([]models.OrderStatusHistory, error) {
+	history := []models.OrderStatusHistory{}
+
+	rows, err := o.db.Query(ctx, `select
+		id,
+		COALESCE(from_status, ''),
+		to_status,
+		COALESCE(actor_id::text, ''),
+		COALESCE(actor_role, ''),
+		created_at::text
+		from order_status_history
+		where order_id = $1
+		order by created_at`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			entry     = models.OrderStatusHistory{}
+			createdAt sql.NullString
+		)
+		if err := rows.Scan(
+			&entry.Id,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.ActorId,
+			&entry.ActorRole,
+			&createdAt); err != nil {
+			return nil, err
+		}
+		entry.CreatedAt = pkg.NullStringToString(createdAt)
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
 }
 
 
 
 func (o *orderRepo) GetMSGINFO(ctx context.Context,orderID string) (models.SendMessage, error) {
+	ctx,cancel:= context.WithTimeout(ctx,config.TimewithContex)
+	defer cancel()
+
+	return getMSGInfo(ctx, o.db, orderID)
+}
+
+// rowQuerier is implemented by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func getMSGInfo(ctx context.Context, db rowQuerier, orderID string) (models.SendMessage, error) {
 	order :=models.SendMessage{}
 
 	query := `SELECT
 		o.id,
 		c.name AS car_name,
 		cu.first_name AS customer_first_name,
-		o.from_date,
-		o.to_date,
+		o.from_date::text,
+		o.to_date::text,
 		o.status,
 		o.paid,
-		cu.phone
+		cu.phone,
+		o.late_days
 		FROM orders o
 		JOIN cars c ON o.car_id = c.id
 		JOIN customers cu ON o.customer_id = cu.id
 		WHERE o.id = $1`
 
-	row := o.db.QueryRow(ctx,query, orderID)
+	row := db.QueryRow(ctx,query, orderID)
 	err := row.Scan(
 		&order.Id,
 		&order.CarName,
@@ -249,6 +903,7 @@ func (o *orderRepo) GetMSGINFO(ctx context.Context,orderID string) (models.SendM
 		&order.Status,
 		&order.Paid,
 		&order.Phone,
+		&order.LateDays,
 	)
 	if err != nil {
 		return models.SendMessage{}, err
@@ -2,9 +2,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
 	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateOrder(t *testing.T) {
@@ -17,7 +24,6 @@ func TestCreateOrder(t *testing.T) {
 		FromDate:   "2024-04-05",
 		ToDate:     "2024-04-10",
 		Status:     "canceled",
-		Paid:       true,
 		Amount:     100.00,
 	}
 
@@ -42,7 +48,6 @@ func TestUpdateOrder(t *testing.T) {
 		FromDate: "2024-04-06",   
 		ToDate:   "2024-04-12",   
 		Status:   "in process",    
-		Paid:     true,           
 		Amount:   100.00,         
 	}
 
@@ -112,3 +117,182 @@ func TestDeleteOrder(t *testing.T) {
 	}
 }
 
+func TestDeleteOrderKeepsLedger(t *testing.T) {
+	repo := NewOrder(db)
+	payments := NewPayment(db)
+
+	orderID := createTestOrder(t, 200)
+	_, err := payments.Record(context.Background(), models.RecordPayment{
+		OrderId: orderID,
+		Kind:    config.PAYMENT_KIND_PAYMENT,
+		Method:  config.PAYMENT_METHOD_CASH,
+		Amount:  100,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.ErrorIs(t, repo.Delete(context.Background(), orderID), storage.ErrOrderNotDeletable)
+	_, err = repo.GetByID(context.Background(), orderID)
+	assert.NoError(t, err)
+
+	// an order nobody paid for goes with its empty deposit
+	orderID = createTestOrder(t, 200)
+	assert.NoError(t, repo.Delete(context.Background(), orderID))
+	_, err = repo.GetByID(context.Background(), orderID)
+	assert.Error(t, err)
+}
+
+func TestCreateOrderOverlap(t *testing.T) {
+	carRepo := NewCar(db)
+	customerRepo := NewCustomer(db, logg)
+	repo := NewOrder(db)
+
+	carID, err := carRepo.Create(context.Background(), models.CreateCar{
+		Name:  faker.Name(),
+		Year:  2020,
+		Brand: faker.Word(),
+		Model: faker.Word(),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	customerID, err := customerRepo.Create(context.Background(), models.Customer{
+		FirstName: faker.FirstName(),
+		Gmail:     faker.Email(),
+		Phone:     faker.Phonenumber(),
+		Password:  "Secret#123",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	order := models.CreateOrder{
+		CarId:      carID,
+		CustomerId: customerID,
+		FromDate:   "2030-04-05",
+		ToDate:     "2030-04-10",
+		Status:     "new",
+	}
+
+	_, err = repo.Create(context.Background(), order)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	order.FromDate, order.ToDate = "2030-04-08", "2030-04-12"
+
+	var conflict *storage.BookingConflictError
+	_, err = repo.Create(context.Background(), order)
+	assert.True(t, errors.As(err, &conflict))
+
+	// the car is free again from the return day on
+	order.FromDate, order.ToDate = "2030-04-10", "2030-04-12"
+	_, err = repo.Create(context.Background(), order)
+	assert.NoError(t, err)
+}
+
+func TestChargeLateFee(t *testing.T) {
+	repo := NewOrder(db)
+	orderID := createTestOrder(t, 300)
+
+	_, err := repo.UpdateOrderStatus(context.Background(), models.OrderStatusChange{
+		OrderId:    orderID,
+		FromStatus: config.STATUS_NEW,
+		ToStatus:   config.STATUS_IN_PROCESS,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	fee := models.LateFee{
+		OrderId:     orderID,
+		ChargedDays: 0,
+		LateDays:    2,
+		Items: []models.OrderLineItem{
+			{Kind: config.LINE_ITEM_LATE_FEE, Description: "Late return", Quantity: 2, UnitPrice: 75, Amount: 150},
+		},
+	}
+	if !assert.NoError(t, repo.ChargeLateFee(context.Background(), fee)) {
+		return
+	}
+	// the same days are never charged twice
+	assert.ErrorIs(t, repo.ChargeLateFee(context.Background(), fee), storage.ErrOrderStatusChanged)
+
+	order, err := repo.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float32(450), order.Amount)
+		assert.Equal(t, 2, order.LateDays)
+		assert.NotEmpty(t, order.OverdueSince)
+	}
+
+	_, err = repo.UpdateOrderStatus(context.Background(), models.OrderStatusChange{
+		OrderId:        orderID,
+		FromStatus:     config.STATUS_IN_PROCESS,
+		ToStatus:       config.STATUS_FINISHED,
+		ActualReturnAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+		LateFee: models.LateFee{
+			OrderId:     orderID,
+			ChargedDays: 2,
+			LateDays:    3,
+			Items:       []models.OrderLineItem{{Kind: config.LINE_ITEM_LATE_FEE, Description: "Late return", Quantity: 1, UnitPrice: 75, Amount: 75}},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	order, err = repo.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float32(525), order.Amount)
+		assert.Equal(t, 3, order.LateDays)
+		assert.NotEmpty(t, order.ActualReturnAt)
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	repo := NewOrder(db)
+	payments := NewPayment(db)
+	orderID := createTestOrder(t, 200)
+
+	_, err := payments.Record(context.Background(), models.RecordPayment{
+		OrderId: orderID,
+		Kind:    config.PAYMENT_KIND_PAYMENT,
+		Method:  config.PAYMENT_METHOD_CASH,
+		Amount:  200,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cancellation := models.OrderCancellation{
+		OrderId:       orderID,
+		Policy:        "late_cancellation",
+		RefundPercent: 50,
+		RefundAmount:  100,
+		Reason:        "plans changed",
+		ActorRole:     config.ADMIN_ROLE,
+		FromStatus:    config.STATUS_NEW,
+		Refunds: []models.RecordPayment{
+			{OrderId: orderID, Kind: config.PAYMENT_KIND_REFUND, Method: config.PAYMENT_METHOD_CASH, Amount: 100},
+		},
+	}
+	refundIDs, err := repo.Cancel(context.Background(), cancellation)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, refundIDs, 1)
+	// an order is canceled only once
+	_, err = repo.Cancel(context.Background(), cancellation)
+	assert.ErrorIs(t, err, storage.ErrOrderStatusChanged)
+
+	order, err := repo.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.STATUS_CANCELED, order.Status)
+		assert.Equal(t, float32(100), order.Balance.PaidAmount)
+		if assert.NotNil(t, order.Cancellation) {
+			assert.Equal(t, "late_cancellation", order.Cancellation.Policy)
+			assert.Equal(t, float32(100), order.Cancellation.RefundAmount)
+		}
+	}
+}
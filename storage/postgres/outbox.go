@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type outboxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOutbox(db *pgxpool.Pool) outboxRepo {
+	return outboxRepo{
+		db: db,
+	}
+}
+
+// insertOutboxEvent snapshots the order inside tx and queues the event, so the event is stored
+// if and only if the order change is committed.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType, orderID string) error {
+	info, err := getMSGInfo(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(models.OrderEvent{
+		Type:    eventType,
+		OrderId: orderID,
+		Order:   info,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `insert into outbox_events(
+		id,
+		event_type,
+		order_id,
+		payload
+	) values($1,$2,$3,$4)`, uuid.NewString(), eventType, orderID, payload)
+	return err
+}
+
+// ClaimPending leases up to limit due events, rows leased by another dispatcher are skipped.
+func (o *outboxRepo) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	events := []models.OutboxEvent{}
+
+	query := `with claimed as (
+		select id from outbox_events
+		where status = $1 and next_attempt_at <= NOW()
+		and (locked_until is null or locked_until < NOW())
+		order by next_attempt_at, created_at
+		limit $2
+		for update skip locked
+	)
+	update outbox_events e set
+		locked_until = NOW() + make_interval(secs => $3)
+	from claimed
+	where e.id = claimed.id
+	returning e.id, e.event_type, e.order_id, e.payload, e.attempts, e.delivered_channels`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	rows, err := o.db.Query(ctx, query, config.OUTBOX_PENDING, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event   = models.OutboxEvent{}
+			payload []byte
+		)
+		if err := rows.Scan(
+			&event.Id,
+			&event.EventType,
+			&event.OrderId,
+			&payload,
+			&event.Attempts,
+			&event.DeliveredChannels); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &event.Payload); err != nil {
+			return nil, err
+		}
+		event.Status = config.OUTBOX_PENDING
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (o *outboxRepo) MarkSent(ctx context.Context, id string, delivered []string) error {
+	query := `update outbox_events set
+		status = $1,
+		attempts = attempts + 1,
+		last_error = NULL,
+		delivered_channels = $2,
+		locked_until = NULL,
+		sent_at = NOW()
+		where id = $3`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	if delivered == nil {
+		delivered = []string{}
+	}
+	_, err := o.db.Exec(ctx, query, config.OUTBOX_SENT, delivered, id)
+	return err
+}
+
+// MarkFailed counts the failed attempt, keeps the channels that took the event and either
+// schedules a retry or dead-letters the event.
+func (o *outboxRepo) MarkFailed(ctx context.Context, failure models.OutboxFailure) error {
+	query := `update outbox_events set
+		status = $1,
+		attempts = attempts + 1,
+		last_error = $2,
+		delivered_channels = $5,
+		locked_until = NULL,
+		next_attempt_at = NOW() + make_interval(secs => $3)
+		where id = $4`
+
+	status := config.OUTBOX_PENDING
+	if failure.Dead {
+		status = config.OUTBOX_DEAD
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	delivered := failure.Delivered
+	if delivered == nil {
+		delivered = []string{}
+	}
+	_, err := o.db.Exec(ctx, query, status, failure.Error, failure.RetryIn, failure.Id, delivered)
+	return err
+}
+
+var outboxListColumns = map[string]listColumn{
+	"id":         {expr: "id", cast: "uuid"},
+	"created_at": {expr: "created_at", cast: "timestamp"},
+}
+
+func (o *outboxRepo) GetList(ctx context.Context, req models.GetOutboxEventsRequest) (models.GetOutboxEventsResponse, error) {
+	resp := models.GetOutboxEventsResponse{Events: []models.OutboxEvent{}}
+
+	builder := newQueryBuilder()
+	if req.Status != "" {
+		builder.Where("status = ?", req.Status)
+	}
+	err := builder.Sort([]models.SortField{{Field: "created_at", Desc: true}, {Field: "id"}}, outboxListColumns)
+	if err != nil {
+		return resp, err
+	}
+	builder.Page(req.Page, req.Limit)
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`select count(*) from outbox_events`)
+	if err := o.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`select
+		id,
+		event_type,
+		order_id,
+		payload,
+		status,
+		attempts,
+		last_error,
+		delivered_channels,
+		next_attempt_at::text,
+		created_at::text,
+		sent_at::text
+		from outbox_events`)
+
+	rows, err := o.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event     = models.OutboxEvent{}
+			payload   []byte
+			lastError sql.NullString
+			createdAt sql.NullString
+			sentAt    sql.NullString
+		)
+		if err := rows.Scan(
+			&event.Id,
+			&event.EventType,
+			&event.OrderId,
+			&payload,
+			&event.Status,
+			&event.Attempts,
+			&lastError,
+			&event.DeliveredChannels,
+			&event.NextAttemptAt,
+			&createdAt,
+			&sentAt); err != nil {
+			return resp, err
+		}
+		if err := json.Unmarshal(payload, &event.Payload); err != nil {
+			return resp, err
+		}
+		event.LastError = pkg.NullStringToString(lastError)
+		event.CreatedAt = pkg.NullStringToString(createdAt)
+		event.SentAt = pkg.NullStringToString(sentAt)
+		resp.Events = append(resp.Events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// Replay puts a dead event back in the queue with a fresh attempt budget, channels that took it are still skipped.
+func (o *outboxRepo) Replay(ctx context.Context, id string) error {
+	query := `update outbox_events set
+		status = $1,
+		attempts = 0,
+		locked_until = NULL,
+		next_attempt_at = NOW()
+		where id = $2 and status = $3`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := o.db.Exec(ctx, query, config.OUTBOX_PENDING, id, config.OUTBOX_DEAD)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrOutboxEventNotDead
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxClaimAndDeadLetter(t *testing.T) {
+	repo := NewOutbox(db)
+
+	events, err := repo.ClaimPending(context.Background(), config.OutboxBatchSize, time.Minute)
+	if !assert.NoError(t, err) || len(events) == 0 {
+		return
+	}
+	event := events[0]
+
+	// a leased event is not handed out twice
+	again, err := repo.ClaimPending(context.Background(), config.OutboxBatchSize, time.Minute)
+	if assert.NoError(t, err) {
+		for _, e := range again {
+			assert.NotEqual(t, event.Id, e.Id)
+		}
+	}
+
+	err = repo.MarkFailed(context.Background(), models.OutboxFailure{
+		Id:        event.Id,
+		Error:     "webhook: channel down",
+		Delivered: []string{"telegram"},
+		Dead:      true,
+	})
+	assert.NoError(t, err)
+
+	dead, err := repo.GetList(context.Background(), models.GetOutboxEventsRequest{Status: config.OUTBOX_DEAD, Page: 1, Limit: 10})
+	if assert.NoError(t, err) {
+		assert.NotZero(t, dead.Count)
+	}
+
+	assert.NoError(t, repo.Replay(context.Background(), event.Id))
+	assert.ErrorIs(t, repo.Replay(context.Background(), event.Id), storage.ErrOutboxEventNotDead)
+
+	// the replayed event still knows the channel that took it
+	replayed, err := repo.ClaimPending(context.Background(), config.OutboxBatchSize, time.Minute)
+	if assert.NoError(t, err) {
+		for _, e := range replayed {
+			if e.Id == event.Id {
+				assert.Equal(t, []string{"telegram"}, e.DeliveredChannels)
+			}
+		}
+	}
+}
+
+func TestOutboxReplayUnknown(t *testing.T) {
+	repo := NewOutbox(db)
+
+	err := repo.Replay(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, storage.ErrOutboxEventNotDead)
+}
@@ -0,0 +1,368 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type paymentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPayment(db *pgxpool.Pool) paymentRepo {
+	return paymentRepo{
+		db: db,
+	}
+}
+
+// Record adds the payment to the ledger of the order and updates orders.paid. The order row is locked,
+// so payments of one order are serialized, and a payment above the outstanding amount or a refund above
+// the paid amount fails with storage.ErrLedgerChanged. A pending payment reserves its amount until it is
+// confirmed or failed.
+func (p *paymentRepo) Record(ctx context.Context, req models.RecordPayment) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var lockedID string
+	if err = tx.QueryRow(ctx, `select id from orders where id = $1 for update`, req.OrderId).Scan(&lockedID); err != nil {
+		return "", dbError(err, "order")
+	}
+
+	balance, err := getOrderBalance(ctx, tx, req.OrderId)
+	if err != nil {
+		return "", err
+	}
+	switch req.Kind {
+	case config.PAYMENT_KIND_PAYMENT:
+		if req.Amount > balance.Outstanding {
+			return "", storage.ErrLedgerChanged
+		}
+	case config.PAYMENT_KIND_REFUND:
+		if req.Amount > balance.PaidAmount {
+			return "", storage.ErrLedgerChanged
+		}
+	}
+
+	id, err := insertPayment(ctx, tx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if err = syncOrderPaid(ctx, tx, req.OrderId); err != nil {
+		return "", err
+	}
+
+	if req.Status != config.PAYMENT_PENDING {
+		if err = insertOutboxEvent(ctx, tx, config.EVENT_PAYMENT_RECORDED, req.OrderId); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Confirm marks a pending payment as moved by the payment provider and keeps its reference.
+func (p *paymentRepo) Confirm(ctx context.Context, id, providerRef string) error {
+	return p.finish(ctx, id, config.PAYMENT_CONFIRMED, providerRef)
+}
+
+// Fail marks a pending payment the payment provider did not move, it no longer counts in the balance.
+// A failed deposit release reopens the deposit.
+func (p *paymentRepo) Fail(ctx context.Context, id string) error {
+	return p.finish(ctx, id, config.PAYMENT_FAILED, "")
+}
+
+// finish moves a pending payment to status under the lock of its order, a payment that is
+// not pending any more fails with storage.ErrPaymentNotPending.
+func (p *paymentRepo) finish(ctx context.Context, id, status, providerRef string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		orderID string
+		kind    string
+		amount  float32
+	)
+	if err = tx.QueryRow(ctx, `select o.id, p.kind, p.amount from payments p
+		join orders o on o.id = p.order_id
+		where p.id = $1
+		for update of o`, id).Scan(&orderID, &kind, &amount); err != nil {
+		return dbError(err, "payment")
+	}
+
+	tag, err := tx.Exec(ctx, `update payments set
+		status = $1,
+		provider_ref = COALESCE(NULLIF($2, ''), provider_ref)
+		where id = $3 and status = $4`, status, providerRef, id, config.PAYMENT_PENDING)
+	if err != nil {
+		return dbError(err, "payment")
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrPaymentNotPending
+	}
+
+	if kind == config.PAYMENT_KIND_DEPOSIT_RELEASE && status == config.PAYMENT_FAILED {
+		if err = reopenDeposit(ctx, tx, orderID, amount); err != nil {
+			return err
+		}
+	}
+
+	if err = syncOrderPaid(ctx, tx, orderID); err != nil {
+		return err
+	}
+
+	if status == config.PAYMENT_CONFIRMED {
+		event := config.EVENT_PAYMENT_RECORDED
+		if kind == config.PAYMENT_KIND_DEPOSIT_RELEASE {
+			event = config.EVENT_DEPOSIT_SETTLED
+		}
+		if err = insertOutboxEvent(ctx, tx, event, orderID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertPayment(ctx context.Context, tx pgx.Tx, req models.RecordPayment) (string, error) {
+	id := uuid.NewString()
+
+	status := req.Status
+	if status == "" {
+		status = config.PAYMENT_CONFIRMED
+	}
+
+	query := `insert into payments(
+		id,
+		order_id,
+		kind,
+		method,
+		amount,
+		provider_ref,
+		note,
+		actor_id,
+		actor_role,
+		status
+	) values($1,$2,$3,$4,$5,NULLIF($6, ''),NULLIF($7, ''),NULLIF($8, '')::uuid,NULLIF($9, ''),$10)`
+
+	_, err := tx.Exec(ctx, query,
+		id,
+		req.OrderId,
+		req.Kind,
+		req.Method,
+		req.Amount,
+		req.ProviderRef,
+		req.Note,
+		req.ActorId,
+		req.ActorRole,
+		status)
+	if err != nil {
+		return "", dbError(err, "payment")
+	}
+	return id, nil
+}
+
+// syncOrderPaid derives orders.paid from the ledger, it is called whenever the ledger or the order amount changes.
+func syncOrderPaid(ctx context.Context, tx pgx.Tx, orderID string) error {
+	balance, err := getOrderBalance(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `update orders set paid = $1 where id = $2`, balance.Paid, orderID)
+	return err
+}
+
+func getOrderBalance(ctx context.Context, db rowQuerier, orderID string) (models.OrderBalance, error) {
+	balance := models.OrderBalance{}
+
+	// captured deposit money pays the order, numeric arithmetic, so a fully paid order has exactly nothing outstanding.
+	// Failed payments are left out, pending charges only reserve the outstanding amount and pending refunds
+	// and releases are taken out right away, so the balance never counts money the provider has not moved
+	query := `select
+		amount,
+		paid,
+		refunded,
+		deposit,
+		GREATEST(amount - paid - pending, 0),
+		amount > 0 and paid >= amount
+		from (select
+			COALESCE(o.amount, 0) as amount,
+			COALESCE(sum(p.amount) filter (where p.kind in ($2, $6) and p.status = $7), 0) - COALESCE(sum(p.amount) filter (where p.kind = $3), 0) as paid,
+			COALESCE(sum(p.amount) filter (where p.kind = $3), 0) as refunded,
+			COALESCE(sum(p.amount) filter (where p.kind = $4 and p.status = $7), 0) - COALESCE(sum(p.amount) filter (where p.kind in ($5, $6)), 0) as deposit,
+			COALESCE(sum(p.amount) filter (where p.kind = $2 and p.status = $8), 0) as pending
+			from orders o
+			left join payments p on p.order_id = o.id and p.status <> $9
+			where o.id = $1
+			group by o.id) ledger`
+
+	err := db.QueryRow(ctx, query,
+		orderID,
+		config.PAYMENT_KIND_PAYMENT,
+		config.PAYMENT_KIND_REFUND,
+		config.PAYMENT_KIND_DEPOSIT,
+		config.PAYMENT_KIND_DEPOSIT_RELEASE,
+		config.PAYMENT_KIND_DEPOSIT_CAPTURE,
+		config.PAYMENT_CONFIRMED,
+		config.PAYMENT_PENDING,
+		config.PAYMENT_FAILED).Scan(
+		&balance.Amount,
+		&balance.PaidAmount,
+		&balance.Refunded,
+		&balance.DepositHeld,
+		&balance.Outstanding,
+		&balance.Paid)
+	if err != nil {
+		return models.OrderBalance{}, dbError(err, "order")
+	}
+	return balance, nil
+}
+
+func (p *paymentRepo) GetBalance(ctx context.Context, orderID string) (models.OrderBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	return getOrderBalance(ctx, p.db, orderID)
+}
+
+func (p *paymentRepo) GetByOrderID(ctx context.Context, orderID string) ([]models.Payment, error) {
+	payments := []models.Payment{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	rows, err := p.db.Query(ctx, `select
+		id,
+		order_id,
+		kind,
+		method,
+		amount,
+		status,
+		provider_ref,
+		note,
+		actor_id::text,
+		actor_role,
+		created_at::text
+		from payments
+		where order_id = $1
+		order by created_at, id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			payment     = models.Payment{}
+			providerRef sql.NullString
+			note        sql.NullString
+			actorID     sql.NullString
+			actorRole   sql.NullString
+			createdAt   sql.NullString
+		)
+		if err := rows.Scan(
+			&payment.Id,
+			&payment.OrderId,
+			&payment.Kind,
+			&payment.Method,
+			&payment.Amount,
+			&payment.Status,
+			&providerRef,
+			&note,
+			&actorID,
+			&actorRole,
+			&createdAt); err != nil {
+			return nil, err
+		}
+		payment.ProviderRef = pkg.NullStringToString(providerRef)
+		payment.Note = pkg.NullStringToString(note)
+		payment.ActorId = pkg.NullStringToString(actorID)
+		payment.ActorRole = pkg.NullStringToString(actorRole)
+		payment.CreatedAt = pkg.NullStringToString(createdAt)
+		payments = append(payments, payment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// Report sums the ledger entries recorded in [req.From, req.To).
+func (p *paymentRepo) Report(ctx context.Context, req models.FinanceReportRequest) (models.FinanceReport, error) {
+	report := models.FinanceReport{From: req.From, To: req.To}
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	err := p.db.QueryRow(ctx, `select
+		COALESCE(sum(amount) filter (where kind = $3), 0),
+		COALESCE(sum(amount) filter (where kind = $4), 0),
+		COALESCE(sum(amount) filter (where kind = $5), 0),
+		COALESCE(sum(amount) filter (where kind = $6), 0),
+		COALESCE(sum(amount) filter (where kind = $7), 0)
+		from payments
+		where created_at >= $1::date and created_at < $2::date and status = $8`,
+		req.From,
+		req.To,
+		config.PAYMENT_KIND_PAYMENT,
+		config.PAYMENT_KIND_REFUND,
+		config.PAYMENT_KIND_DEPOSIT,
+		config.PAYMENT_KIND_DEPOSIT_RELEASE,
+		config.PAYMENT_KIND_DEPOSIT_CAPTURE,
+		config.PAYMENT_CONFIRMED).Scan(
+		&report.Payments,
+		&report.Refunds,
+		&report.DepositsCollected,
+		&report.DepositsReleased,
+		&report.DepositsCaptured)
+	if err != nil {
+		return report, err
+	}
+	report.Revenue = report.Payments - report.Refunds + report.DepositsCaptured
+
+	// held money is a snapshot of the open deposits, not of the period
+	err = p.db.QueryRow(ctx, `select
+		count(*),
+		COALESCE(sum(held), 0)
+		from (select
+			sum(case when p.kind = $2 then p.amount else -p.amount end) as held
+			from order_deposits d
+			join payments p on p.order_id = d.order_id and p.kind in ($2, $3, $4) and p.status = $5
+			where d.status = $1
+			group by d.order_id) deposits
+		where held > 0`,
+		config.DEPOSIT_OPEN,
+		config.PAYMENT_KIND_DEPOSIT,
+		config.PAYMENT_KIND_DEPOSIT_RELEASE,
+		config.PAYMENT_KIND_DEPOSIT_CAPTURE,
+		config.PAYMENT_CONFIRMED).Scan(
+		&report.OpenDeposits,
+		&report.DepositsHeld)
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
@@ -0,0 +1,243 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestOrder books a new car for a new customer, the order starts a year from now.
+func createTestOrder(t *testing.T, amount float32) string {
+	t.Helper()
+
+	cars := NewCar(db)
+	carID, err := cars.Create(context.Background(), models.CreateCar{
+		Name:      faker.Name(),
+		Year:      2020,
+		Brand:     faker.Word(),
+		DailyRate: 50,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	customers := NewCustomer(db, logg)
+	customerID, err := customers.Create(context.Background(), models.Customer{
+		FirstName: faker.FirstName(),
+		Gmail:     faker.Email(),
+		Phone:     faker.Phonenumber(),
+		Password:  "Secret#123",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	from := time.Now().AddDate(1, 0, 0)
+	orders := NewOrder(db)
+	orderID, err := orders.Create(context.Background(), models.CreateOrder{
+		CarId:      carID,
+		CustomerId: customerID,
+		FromDate:   from.Format(time.DateOnly),
+		ToDate:     from.AddDate(0, 0, 3).Format(time.DateOnly),
+		Status:     config.STATUS_NEW,
+		Amount:     amount,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return orderID
+}
+
+func TestPaymentLedger(t *testing.T) {
+	repo := NewPayment(db)
+	orderID := createTestOrder(t, 300)
+
+	record := func(kind, method string, amount float32) error {
+		_, err := repo.Record(context.Background(), models.RecordPayment{
+			OrderId: orderID,
+			Kind:    kind,
+			Method:  method,
+			Amount:  amount,
+		})
+		return err
+	}
+
+	assert.NoError(t, record(config.PAYMENT_KIND_DEPOSIT, config.PAYMENT_METHOD_CARD, 500))
+	assert.NoError(t, record(config.PAYMENT_KIND_PAYMENT, config.PAYMENT_METHOD_CASH, 100))
+
+	balance, err := repo.GetBalance(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.OrderBalance{Amount: 300, PaidAmount: 100, DepositHeld: 500, Outstanding: 200}, balance)
+	}
+
+	// more than outstanding
+	assert.ErrorIs(t, record(config.PAYMENT_KIND_PAYMENT, config.PAYMENT_METHOD_CASH, 250), storage.ErrLedgerChanged)
+
+	assert.NoError(t, record(config.PAYMENT_KIND_PAYMENT, config.PAYMENT_METHOD_TRANSFER, 200))
+	orders := NewOrder(db)
+	order, err := orders.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.True(t, order.Paid)
+		assert.True(t, order.Balance.Paid)
+	}
+
+	assert.NoError(t, record(config.PAYMENT_KIND_REFUND, config.PAYMENT_METHOD_CASH, 50))
+	order, err = orders.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.False(t, order.Paid)
+		assert.Equal(t, float32(50), order.Balance.Outstanding)
+		assert.Equal(t, float32(50), order.Balance.Refunded)
+	}
+
+	payments, err := repo.GetByOrderID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Len(t, payments, 4)
+	}
+}
+
+func TestPendingPayment(t *testing.T) {
+	repo := NewPayment(db)
+	orderID := createTestOrder(t, 300)
+
+	pending := func(kind string, amount float32) string {
+		id, err := repo.Record(context.Background(), models.RecordPayment{
+			OrderId: orderID,
+			Kind:    kind,
+			Method:  config.PAYMENT_METHOD_CARD,
+			Amount:  amount,
+			Status:  config.PAYMENT_PENDING,
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return id
+	}
+
+	// a pending charge is not paid but reserves the outstanding amount
+	chargeID := pending(config.PAYMENT_KIND_PAYMENT, 200)
+	balance, err := repo.GetBalance(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.OrderBalance{Amount: 300, Outstanding: 100}, balance)
+	}
+	_, err = repo.Record(context.Background(), models.RecordPayment{
+		OrderId: orderID,
+		Kind:    config.PAYMENT_KIND_PAYMENT,
+		Method:  config.PAYMENT_METHOD_CASH,
+		Amount:  150,
+	})
+	assert.ErrorIs(t, err, storage.ErrLedgerChanged)
+
+	// a failed charge gives the reservation back
+	assert.NoError(t, repo.Fail(context.Background(), chargeID))
+	assert.ErrorIs(t, repo.Confirm(context.Background(), chargeID, "ref"), storage.ErrPaymentNotPending)
+	balance, err = repo.GetBalance(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float32(300), balance.Outstanding)
+	}
+
+	chargeID = pending(config.PAYMENT_KIND_PAYMENT, 300)
+	assert.NoError(t, repo.Confirm(context.Background(), chargeID, "fake_ch_1"))
+	balance, err = repo.GetBalance(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.True(t, balance.Paid)
+		assert.Equal(t, float32(300), balance.PaidAmount)
+	}
+
+	// a pending refund is taken from the paid amount right away
+	pending(config.PAYMENT_KIND_REFUND, 100)
+	balance, err = repo.GetBalance(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float32(200), balance.PaidAmount)
+		assert.False(t, balance.Paid)
+	}
+
+	payments, err := repo.GetByOrderID(context.Background(), orderID)
+	if assert.NoError(t, err) && assert.Len(t, payments, 3) {
+		assert.Equal(t, config.PAYMENT_FAILED, payments[0].Status)
+		assert.Equal(t, config.PAYMENT_CONFIRMED, payments[1].Status)
+		assert.Equal(t, "fake_ch_1", payments[1].ProviderRef)
+		assert.Equal(t, config.PAYMENT_PENDING, payments[2].Status)
+	}
+}
+
+func TestSettleDeposit(t *testing.T) {
+	payments := NewPayment(db)
+	deposits := NewDeposit(db)
+	orderID := createTestOrder(t, 300)
+
+	deposit, err := deposits.GetByOrderID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.DEPOSIT_OPEN, deposit.Status)
+	}
+
+	for _, entry := range []models.RecordPayment{
+		{Kind: config.PAYMENT_KIND_PAYMENT, Method: config.PAYMENT_METHOD_CASH, Amount: 300},
+		{Kind: config.PAYMENT_KIND_DEPOSIT, Method: config.PAYMENT_METHOD_CARD, Amount: 200},
+	} {
+		entry.OrderId = orderID
+		_, err := payments.Record(context.Background(), entry)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	// a split that does not add up to the held deposit is refused
+	_, err = deposits.Settle(context.Background(), models.SettleDeposit{OrderId: orderID, Release: 150})
+	assert.ErrorIs(t, err, storage.ErrLedgerChanged)
+
+	releaseID, err := deposits.Settle(context.Background(), models.SettleDeposit{
+		OrderId: orderID,
+		Charges: []models.OrderCharge{{Kind: config.LINE_ITEM_DAMAGE, Description: "scratched door", Amount: 50}},
+		Capture: 50,
+		Release: 150,
+		Method:  config.PAYMENT_METHOD_CARD,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	orders := NewOrder(db)
+	order, err := orders.GetByID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float32(350), order.Amount)
+		assert.True(t, order.Paid)
+		assert.Equal(t, config.DEPOSIT_PARTIALLY_CAPTURED, order.Deposit.Status)
+		assert.Equal(t, float32(50), order.Deposit.Captured)
+		assert.Equal(t, float32(0), order.Deposit.Held)
+	}
+
+	_, err = deposits.Settle(context.Background(), models.SettleDeposit{OrderId: orderID})
+	assert.ErrorIs(t, err, storage.ErrDepositSettled)
+
+	// the card release was declined, the deposit is open again for what is still held
+	if assert.NotEmpty(t, releaseID) {
+		assert.NoError(t, payments.Fail(context.Background(), releaseID))
+	}
+	deposit, err = deposits.GetByOrderID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.DEPOSIT_HELD, deposit.Status)
+		assert.Equal(t, float32(150), deposit.Held)
+		assert.Equal(t, float32(50), deposit.Captured)
+		assert.Equal(t, float32(0), deposit.Released)
+	}
+
+	releaseID, err = deposits.Settle(context.Background(), models.SettleDeposit{
+		OrderId: orderID,
+		Release: 150,
+		Method:  config.PAYMENT_METHOD_CARD,
+	})
+	if assert.NoError(t, err) {
+		assert.NoError(t, payments.Confirm(context.Background(), releaseID, "fake_re_1"))
+	}
+	deposit, err = deposits.GetByOrderID(context.Background(), orderID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.DEPOSIT_PARTIALLY_CAPTURED, deposit.Status)
+		assert.Equal(t, float32(0), deposit.Held)
+	}
+}
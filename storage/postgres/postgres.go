@@ -19,6 +19,36 @@ type Store struct {
 
 
 func New(ctx context.Context, cfg config.Config) (storage.IStorage, error) {
+	newPool, err := NewPool(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoMigrate {
+		migrator, err := NewMigrator(newPool)
+		if err != nil {
+			newPool.Close()
+			return nil, err
+		}
+
+		migrateCtx, cancel := context.WithTimeout(context.Background(), config.MigrateTimeout)
+		defer cancel()
+
+		if err = migrator.Up(migrateCtx); err != nil {
+			fmt.Println("error while migrating db", err.Error())
+			newPool.Close()
+			return nil, err
+		}
+	}
+
+	return Store{
+		Pool: newPool,
+	}, nil
+
+}
+
+// NewPool connects to the database described by cfg.
+func NewPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 	url := fmt.Sprintf(`host=%s port=%v user=%s password=%s database=%s sslmode=disable`,
 		cfg.PostgresHost, cfg.PostgresPort, cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresDatabase)
 
@@ -38,11 +68,7 @@ func New(ctx context.Context, cfg config.Config) (storage.IStorage, error) {
 		fmt.Println("error while connecting to db", err.Error())
 		return nil, err
 	}
-
-	return Store{
-		Pool: newPool,
-	}, nil
-
+	return newPool, nil
 }
 
 func (s Store) CloseDB() {
@@ -65,3 +91,57 @@ func (s Store) Order() storage.IOrderStorage {
 
 	return &newOrder
 }
+
+func (s Store) Token() storage.ITokenStorage {
+	newToken := NewToken(s.Pool)
+
+	return &newToken
+}
+
+func (s Store) Staff() storage.IStaffStorage {
+	newStaff := NewStaff(s.Pool)
+
+	return &newStaff
+}
+
+func (s Store) Outbox() storage.IOutboxStorage {
+	newOutbox := NewOutbox(s.Pool)
+
+	return &newOutbox
+}
+
+func (s Store) Verification() storage.IVerificationStorage {
+	newVerification := NewVerification(s.Pool)
+
+	return &newVerification
+}
+
+func (s Store) Payment() storage.IPaymentStorage {
+	newPayment := NewPayment(s.Pool)
+
+	return &newPayment
+}
+
+func (s Store) Deposit() storage.IDepositStorage {
+	newDeposit := NewDeposit(s.Pool)
+
+	return &newDeposit
+}
+
+func (s Store) Extension() storage.IExtensionStorage {
+	newExtension := NewExtension(s.Pool)
+
+	return &newExtension
+}
+
+func (s Store) Promo() storage.IPromoStorage {
+	newPromo := NewPromo(s.Pool)
+
+	return &newPromo
+}
+
+func (s Store) Loyalty() storage.ILoyaltyStorage {
+	newLoyalty := NewLoyalty(s.Pool)
+
+	return &newLoyalty
+}
@@ -0,0 +1,283 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type promoRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPromo(db *pgxpool.Pool) promoRepo {
+	return promoRepo{
+		db: db,
+	}
+}
+
+func (p *promoRepo) Create(ctx context.Context, req models.CreatePromoCode) (string, error) {
+	id := uuid.NewString()
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	_, err := p.db.Exec(ctx, `insert into promo_codes(
+		id,
+		code,
+		description,
+		kind,
+		value,
+		valid_from,
+		valid_to,
+		max_uses,
+		max_uses_per_customer,
+		min_days,
+		brands
+	) values($1,$2,NULLIF($3, ''),$4,$5,$6,$7,$8,$9,$10,$11)`,
+		id,
+		req.Code,
+		req.Description,
+		req.Kind,
+		req.Value,
+		req.ValidFrom,
+		req.ValidTo,
+		req.MaxUses,
+		req.MaxUsesPerCustomer,
+		req.MinDays,
+		promoBrands(req.Brands))
+	if err != nil {
+		return "", dbError(err, "promo_code")
+	}
+	return id, nil
+}
+
+func (p *promoRepo) Update(ctx context.Context, req models.UpdatePromoCode) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := p.db.Exec(ctx, `update promo_codes set
+		description = NULLIF($1, ''),
+		kind = $2,
+		value = $3,
+		valid_from = $4,
+		valid_to = $5,
+		max_uses = $6,
+		max_uses_per_customer = $7,
+		min_days = $8,
+		brands = $9,
+		active = $10,
+		updated_at = CURRENT_TIMESTAMP
+		where id = $11`,
+		req.Description,
+		req.Kind,
+		req.Value,
+		req.ValidFrom,
+		req.ValidTo,
+		req.MaxUses,
+		req.MaxUsesPerCustomer,
+		req.MinDays,
+		promoBrands(req.Brands),
+		req.Active,
+		req.Id)
+	if err != nil {
+		return dbError(err, "promo_code")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("promo_code")
+	}
+	return nil
+}
+
+// Delete removes a promo code that was never redeemed, redeemed codes are deactivated instead.
+func (p *promoRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := p.db.Exec(ctx, `delete from promo_codes where id = $1`, id)
+	if err != nil {
+		return dbError(err, "promo_code")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("promo_code")
+	}
+	return nil
+}
+
+// promoColumns counts the uses by orders that were not canceled.
+const promoColumns = `p.id,
+	p.code,
+	p.description,
+	p.kind,
+	p.value,
+	p.valid_from::text,
+	p.valid_to::text,
+	p.max_uses,
+	p.max_uses_per_customer,
+	p.min_days,
+	p.brands,
+	p.active,
+	(select count(*) from promo_redemptions r
+		join orders o on o.id = r.order_id
+		where r.promo_code_id = p.id and o.status <> 'canceled'),
+	p.created_at::text,
+	p.updated_at::text`
+
+func scanPromoCode(row pgx.Row) (models.PromoCode, error) {
+	var (
+		promo       = models.PromoCode{}
+		description sql.NullString
+		createdAt   sql.NullString
+		updatedAt   sql.NullString
+	)
+	if err := row.Scan(
+		&promo.Id,
+		&promo.Code,
+		&description,
+		&promo.Kind,
+		&promo.Value,
+		&promo.ValidFrom,
+		&promo.ValidTo,
+		&promo.MaxUses,
+		&promo.MaxUsesPerCustomer,
+		&promo.MinDays,
+		&promo.Brands,
+		&promo.Active,
+		&promo.Uses,
+		&createdAt,
+		&updatedAt); err != nil {
+		return promo, err
+	}
+	promo.Description = pkg.NullStringToString(description)
+	promo.CreatedAt = pkg.NullStringToString(createdAt)
+	promo.UpdatedAt = pkg.NullStringToString(updatedAt)
+	return promo, nil
+}
+
+func (p *promoRepo) GetByID(ctx context.Context, id string) (models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	promo, err := scanPromoCode(p.db.QueryRow(ctx, `select `+promoColumns+` from promo_codes p where p.id = $1`, id))
+	if err != nil {
+		return models.PromoCode{}, dbError(err, "promo_code")
+	}
+	return promo, nil
+}
+
+// GetByCode finds a promo code case insensitively.
+func (p *promoRepo) GetByCode(ctx context.Context, code string) (models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	promo, err := scanPromoCode(p.db.QueryRow(ctx, `select `+promoColumns+` from promo_codes p where upper(p.code) = upper($1)`, code))
+	if err != nil {
+		return models.PromoCode{}, dbError(err, "promo_code")
+	}
+	return promo, nil
+}
+
+var promoListColumns = map[string]listColumn{
+	"id":         {expr: "p.id", cast: "uuid"},
+	"created_at": {expr: "p.created_at", cast: "timestamp"},
+}
+
+// GetList returns the promo codes, the newest first.
+func (p *promoRepo) GetList(ctx context.Context, req models.GetPromoCodesRequest) (models.GetPromoCodesResponse, error) {
+	resp := models.GetPromoCodesResponse{PromoCodes: []models.PromoCode{}}
+
+	builder := newQueryBuilder().Search(req.Search, "p.code", "p.description")
+	err := builder.Sort([]models.SortField{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}, promoListColumns)
+	if err != nil {
+		return resp, err
+	}
+	builder.Page(req.Page, req.Limit)
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`select count(*) from promo_codes p`)
+	if err := p.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`select ` + promoColumns + ` from promo_codes p`)
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return resp, err
+		}
+		resp.PromoCodes = append(resp.PromoCodes, promo)
+	}
+	return resp, rows.Err()
+}
+
+func (p *promoRepo) GetUsage(ctx context.Context, promoCodeID, customerID string) (models.PromoUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	return getPromoUsage(ctx, p.db, promoCodeID, customerID)
+}
+
+func getPromoUsage(ctx context.Context, db rowQuerier, promoCodeID, customerID string) (models.PromoUsage, error) {
+	usage := models.PromoUsage{}
+	err := db.QueryRow(ctx, `select
+		count(*),
+		count(*) filter (where r.customer_id = $2)
+		from promo_redemptions r
+		join orders o on o.id = r.order_id
+		where r.promo_code_id = $1 and o.status <> $3`, promoCodeID, customerID, config.STATUS_CANCELED).Scan(
+		&usage.Uses,
+		&usage.CustomerUses)
+	return usage, err
+}
+
+// redeemPromoCode locks the promo code, so redemptions of one code are serialized, checks it is still
+// active and below its usage limits and records the redemption by the order.
+func redeemPromoCode(ctx context.Context, tx pgx.Tx, orderID string, redemption models.PromoRedemption) error {
+	var (
+		active                      bool
+		maxUses, maxUsesPerCustomer int
+	)
+	if err := tx.QueryRow(ctx, `select active, max_uses, max_uses_per_customer from promo_codes where id = $1 for update`,
+		redemption.PromoCodeId).Scan(&active, &maxUses, &maxUsesPerCustomer); err != nil {
+		return dbError(err, "promo_code")
+	}
+
+	usage, err := getPromoUsage(ctx, tx, redemption.PromoCodeId, redemption.CustomerId)
+	if err != nil {
+		return err
+	}
+	if !active || (maxUses > 0 && usage.Uses >= maxUses) || (maxUsesPerCustomer > 0 && usage.CustomerUses >= maxUsesPerCustomer) {
+		return storage.ErrPromoCodeUsedUp
+	}
+
+	_, err = tx.Exec(ctx, `insert into promo_redemptions(
+		order_id,
+		promo_code_id,
+		customer_id,
+		discount
+	) values($1,$2,$3,$4)`, orderID, redemption.PromoCodeId, redemption.CustomerId, redemption.Discount)
+	return err
+}
+
+// promoBrands keeps an empty brand list from being stored as NULL.
+func promoBrands(brands []string) []string {
+	if brands == nil {
+		return []string{}
+	}
+	return brands
+}
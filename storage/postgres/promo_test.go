@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoCode(t *testing.T) {
+	repo := NewPromo(db)
+
+	code := strings.ToUpper(faker.Word()) + "10"
+	id, err := repo.Create(context.Background(), models.CreatePromoCode{
+		Code:               code,
+		Kind:               config.PROMO_KIND_PERCENT,
+		Value:              10,
+		ValidFrom:          time.Now().AddDate(0, 0, -1).Format(time.DateOnly),
+		ValidTo:            time.Now().AddDate(0, 1, 0).Format(time.DateOnly),
+		MaxUsesPerCustomer: 1,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// codes are unique case insensitively
+	_, err = repo.Create(context.Background(), models.CreatePromoCode{
+		Code:      strings.ToLower(code),
+		Kind:      config.PROMO_KIND_FIXED,
+		Value:     5,
+		ValidFrom: "2030-01-01",
+		ValidTo:   "2030-02-01",
+	})
+	assert.Error(t, err)
+
+	promo, err := repo.GetByCode(context.Background(), strings.ToLower(code))
+	if assert.NoError(t, err) {
+		assert.Equal(t, id, promo.Id)
+		assert.Empty(t, promo.Brands)
+		assert.True(t, promo.Active)
+	}
+
+	orderID := createTestOrder(t, 100)
+	orders := NewOrder(db)
+	order, err := orders.GetByID(context.Background(), orderID)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tx, err := db.Begin(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tx.Rollback(context.Background())
+
+	redemption := models.PromoRedemption{PromoCodeId: id, CustomerId: order.CustomerId, Discount: 10}
+	if !assert.NoError(t, redeemPromoCode(context.Background(), tx, orderID, redemption)) {
+		return
+	}
+	// the customer used the code up
+	assert.ErrorIs(t, redeemPromoCode(context.Background(), tx, orderID, redemption), storage.ErrPromoCodeUsedUp)
+
+	usage, err := getPromoUsage(context.Background(), tx, id, order.CustomerId)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, usage.Uses)
+		assert.Equal(t, 1, usage.CustomerUses)
+	}
+}
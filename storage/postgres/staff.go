@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type staffRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewStaff(db *pgxpool.Pool) staffRepo {
+	return staffRepo{
+		db: db,
+	}
+}
+
+// Create expects staff.Password to be hashed already.
+func (s *staffRepo) Create(ctx context.Context, staff models.CreateStaff) (string, error) {
+	id := uuid.New()
+
+	query := `insert into staff(
+		id,
+		full_name,
+		login,
+		password,
+		role)
+		values($1,$2,$3,$4,$5)`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, query, id.String(), staff.FullName, staff.Login, staff.Password, staff.Role)
+	if err != nil {
+		return "", dbError(err, "staff")
+	}
+	return id.String(), nil
+}
+
+func (s *staffRepo) GetByID(ctx context.Context, id string) (models.Staff, error) {
+	return s.getOne(ctx, `WHERE id = $1`, id)
+}
+
+func (s *staffRepo) GetByLogin(ctx context.Context, login string) (models.Staff, error) {
+	return s.getOne(ctx, `WHERE login = $1`, login)
+}
+
+func (s *staffRepo) HasRole(ctx context.Context, role string) (bool, error) {
+	var exists bool
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, `select exists(select 1 from staff where role = $1)`, role).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (s *staffRepo) getOne(ctx context.Context, where string, arg string) (models.Staff, error) {
+	var (
+		staff     = models.Staff{}
+		createdAt sql.NullString
+		updatedAt sql.NullString
+	)
+
+	query := `SELECT
+		id,
+		full_name,
+		login,
+		password,
+		role,
+		created_at::text,
+		updated_at::text
+		FROM staff ` + where
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	if err := s.db.QueryRow(ctx, query, arg).Scan(
+		&staff.Id,
+		&staff.FullName,
+		&staff.Login,
+		&staff.Password,
+		&staff.Role,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return models.Staff{}, dbError(err, "staff")
+	}
+
+	staff.CreatedAt = pkg.NullStringToString(createdAt)
+	staff.UpdatedAt = pkg.NullStringToString(updatedAt)
+
+	return staff, nil
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateStaff(t *testing.T) {
+	repo := NewStaff(db)
+
+	reqStaff := models.CreateStaff{
+		FullName: faker.Name(),
+		Login:    faker.Username(),
+		Password: "$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5BWX4Z3QeB3Y1Z8XGqK7XgR1oXK6e",
+		Role:     config.AGENT_ROLE,
+	}
+
+	id, err := repo.Create(context.Background(), reqStaff)
+	if assert.NoError(t, err) {
+		staff, err := repo.GetByLogin(context.Background(), reqStaff.Login)
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, staff.Id)
+			assert.Equal(t, reqStaff.Role, staff.Role)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tokenRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewToken(db *pgxpool.Pool) tokenRepo {
+	return tokenRepo{
+		db: db,
+	}
+}
+
+// Revoke stores the token id and reports whether it was revoked by this call.
+// A false result means the token had already been used or revoked before.
+func (t *tokenRepo) Revoke(ctx context.Context, token models.RevokedToken) (bool, error) {
+	query := `insert into revoked_tokens(
+		jti,
+		family_id,
+		user_id,
+		reason,
+		expires_at)
+		values($1,$2,$3,$4,to_timestamp($5))
+		ON CONFLICT (jti) DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := t.db.Exec(ctx, query, token.Jti, token.FamilyID, token.UserID, token.Reason, token.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (t *tokenRepo) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	err := t.db.QueryRow(ctx, `select exists(select 1 from revoked_tokens where jti = $1)`, jti).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+func (t *tokenRepo) RevokeFamily(ctx context.Context, familyID, userID, reason string) error {
+	query := `insert into revoked_token_families(
+		family_id,
+		user_id,
+		reason)
+		values($1,$2,$3)
+		ON CONFLICT (family_id) DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	_, err := t.db.Exec(ctx, query, familyID, userID, reason)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *tokenRepo) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	var revoked bool
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	err := t.db.QueryRow(ctx, `select exists(select 1 from revoked_token_families where family_id = $1)`, familyID).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// revokeUserTokens revokes every token of the user issued so far inside tx.
+func revokeUserTokens(ctx context.Context, tx pgx.Tx, userID, reason string) error {
+	_, err := tx.Exec(ctx, `insert into revoked_user_tokens(
+		user_id,
+		reason,
+		revoked_at)
+		values($1,$2,NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+		reason = excluded.reason,
+		revoked_at = excluded.revoked_at`, userID, reason)
+	return err
+}
+
+func (t *tokenRepo) RevokeUser(ctx context.Context, userID, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = revokeUserTokens(ctx, tx, userID, reason); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// IsUserRevoked reports whether the tokens of the user issued at issuedAt, a unix time, were revoked since.
+// Issue times have second precision, so a token issued in the second of the revocation counts as revoked.
+func (t *tokenRepo) IsUserRevoked(ctx context.Context, userID string, issuedAt int64) (bool, error) {
+	var revoked bool
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	err := t.db.QueryRow(ctx, `select exists(select 1 from revoked_user_tokens
+		where user_id = $1 and revoked_at >= to_timestamp($2)::timestamp)`, userID, issuedAt).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeToken(t *testing.T) {
+	repo := NewToken(db)
+
+	token := models.RevokedToken{
+		Jti:       uuid.NewString(),
+		FamilyID:  uuid.NewString(),
+		UserID:    uuid.NewString(),
+		Reason:    "rotated",
+		ExpiresAt: time.Now().AddDate(0, 0, 10).Unix(),
+	}
+
+	revoked, err := repo.Revoke(context.Background(), token)
+	if assert.NoError(t, err) {
+		assert.True(t, revoked)
+	}
+
+	// the second use of the same token must be reported as reuse
+	revoked, err = repo.Revoke(context.Background(), token)
+	if assert.NoError(t, err) {
+		assert.False(t, revoked)
+	}
+
+	isRevoked, err := repo.IsRevoked(context.Background(), token.Jti)
+	if assert.NoError(t, err) {
+		assert.True(t, isRevoked)
+	}
+}
+
+func TestRevokeTokenFamily(t *testing.T) {
+	repo := NewToken(db)
+
+	familyID := uuid.NewString()
+
+	err := repo.RevokeFamily(context.Background(), familyID, uuid.NewString(), "logout")
+	if assert.NoError(t, err) {
+		revoked, err := repo.IsFamilyRevoked(context.Background(), familyID)
+		if assert.NoError(t, err) {
+			assert.True(t, revoked)
+		}
+	}
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	repo := NewToken(db)
+
+	userID := uuid.NewString()
+	issuedBefore := time.Now().Add(-time.Minute).Unix()
+
+	if !assert.NoError(t, repo.RevokeUser(context.Background(), userID, "blocked")) {
+		return
+	}
+
+	revoked, err := repo.IsUserRevoked(context.Background(), userID, issuedBefore)
+	if assert.NoError(t, err) {
+		assert.True(t, revoked)
+	}
+
+	// tokens issued after the revocation keep working
+	revoked, err = repo.IsUserRevoked(context.Background(), userID, time.Now().Add(time.Minute).Unix())
+	if assert.NoError(t, err) {
+		assert.False(t, revoked)
+	}
+}
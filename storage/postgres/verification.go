@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg"
+	"rent-car/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type verificationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewVerification(db *pgxpool.Pool) verificationRepo {
+	return verificationRepo{
+		db: db,
+	}
+}
+
+// Submit stores the licence and documents of the customer, a previous submission is replaced
+// and the verification goes back to pending.
+func (v *verificationRepo) Submit(ctx context.Context, req models.SubmitVerification) error {
+	query := `insert into customer_verifications(
+		customer_id,
+		licence_number,
+		licence_country,
+		licence_expiry,
+		date_of_birth,
+		status)
+		select id, $2, $3, $4, $5, $6 from customers
+		where id = $1 and deleted_at IS NULL
+	on conflict (customer_id) do update set
+		licence_number = excluded.licence_number,
+		licence_country = excluded.licence_country,
+		licence_expiry = excluded.licence_expiry,
+		date_of_birth = excluded.date_of_birth,
+		status = excluded.status,
+		reason = NULL,
+		reviewed_by = NULL,
+		reviewed_at = NULL,
+		submitted_at = NOW()`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tx, err := v.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query,
+		req.CustomerId,
+		req.LicenceNumber,
+		req.LicenceCountry,
+		req.LicenceExpiry,
+		req.DateOfBirth,
+		config.VERIFICATION_PENDING)
+	if err != nil {
+		return dbError(err, "verification")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("customer")
+	}
+
+	if _, err = tx.Exec(ctx, `delete from customer_documents where customer_id = $1`, req.CustomerId); err != nil {
+		return err
+	}
+	for _, document := range req.Documents {
+		_, err = tx.Exec(ctx, `insert into customer_documents(
+			id,
+			customer_id,
+			kind,
+			url
+		) values($1,$2,$3,$4)`, uuid.NewString(), req.CustomerId, document.Kind, document.Url)
+		if err != nil {
+			return dbError(err, "document")
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+const verificationColumns = `customer_id,
+	licence_number,
+	licence_country,
+	licence_expiry::text,
+	date_of_birth::text,
+	status,
+	reason,
+	reviewed_by::text,
+	reviewed_at::text,
+	submitted_at::text`
+
+func scanVerification(row pgx.Row) (models.CustomerVerification, error) {
+	var (
+		verification = models.CustomerVerification{Documents: []models.VerificationDocument{}}
+		reason       sql.NullString
+		reviewedBy   sql.NullString
+		reviewedAt   sql.NullString
+	)
+	if err := row.Scan(
+		&verification.CustomerId,
+		&verification.LicenceNumber,
+		&verification.LicenceCountry,
+		&verification.LicenceExpiry,
+		&verification.DateOfBirth,
+		&verification.Status,
+		&reason,
+		&reviewedBy,
+		&reviewedAt,
+		&verification.SubmittedAt); err != nil {
+		return verification, err
+	}
+	verification.Reason = pkg.NullStringToString(reason)
+	verification.ReviewedBy = pkg.NullStringToString(reviewedBy)
+	verification.ReviewedAt = pkg.NullStringToString(reviewedAt)
+	return verification, nil
+}
+
+func (v *verificationRepo) GetByCustomerID(ctx context.Context, customerID string) (models.CustomerVerification, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	verification, err := scanVerification(v.db.QueryRow(ctx, `select `+verificationColumns+`
+		from customer_verifications
+		where customer_id = $1`, customerID))
+	if err != nil {
+		return models.CustomerVerification{}, dbError(err, "verification")
+	}
+
+	documents, err := v.getDocuments(ctx, customerID)
+	if err != nil {
+		return models.CustomerVerification{}, err
+	}
+	verification.Documents = documents[customerID]
+	if verification.Documents == nil {
+		verification.Documents = []models.VerificationDocument{}
+	}
+	return verification, nil
+}
+
+var verificationListColumns = map[string]listColumn{
+	"customer_id":  {expr: "customer_id", cast: "uuid"},
+	"submitted_at": {expr: "submitted_at", cast: "timestamp"},
+}
+
+// GetList is the review queue, the oldest submissions come first.
+func (v *verificationRepo) GetList(ctx context.Context, req models.GetVerificationsRequest) (models.GetVerificationsResponse, error) {
+	resp := models.GetVerificationsResponse{Verifications: []models.CustomerVerification{}}
+
+	builder := newQueryBuilder()
+	if req.Status != "" {
+		builder.Where("status = ?", req.Status)
+	}
+	err := builder.Sort([]models.SortField{{Field: "submitted_at"}, {Field: "customer_id"}}, verificationListColumns)
+	if err != nil {
+		return resp, err
+	}
+	builder.Page(req.Page, req.Limit)
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	countQuery, countArgs := builder.CountQuery(`select count(*) from customer_verifications`)
+	if err := v.db.QueryRow(ctx, countQuery, countArgs...).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	query, args := builder.Query(`select ` + verificationColumns + ` from customer_verifications`)
+	rows, err := v.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		verification, err := scanVerification(rows)
+		if err != nil {
+			return resp, err
+		}
+		resp.Verifications = append(resp.Verifications, verification)
+		ids = append(ids, verification.CustomerId)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
+	}
+	rows.Close()
+
+	documents, err := v.getDocuments(ctx, ids...)
+	if err != nil {
+		return resp, err
+	}
+	for i := range resp.Verifications {
+		if docs, ok := documents[resp.Verifications[i].CustomerId]; ok {
+			resp.Verifications[i].Documents = docs
+		}
+	}
+	return resp, nil
+}
+
+// getDocuments returns the documents of the customers by customer id.
+func (v *verificationRepo) getDocuments(ctx context.Context, customerIDs ...string) (map[string][]models.VerificationDocument, error) {
+	documents := map[string][]models.VerificationDocument{}
+	if len(customerIDs) == 0 {
+		return documents, nil
+	}
+
+	rows, err := v.db.Query(ctx, `select
+		id,
+		customer_id,
+		kind,
+		url,
+		created_at::text
+		from customer_documents
+		where customer_id = ANY($1::uuid[])
+		order by created_at, kind`, customerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			document   = models.VerificationDocument{}
+			customerID string
+		)
+		if err := rows.Scan(
+			&document.Id,
+			&customerID,
+			&document.Kind,
+			&document.Url,
+			&document.CreatedAt); err != nil {
+			return nil, err
+		}
+		documents[customerID] = append(documents[customerID], document)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// Review records the staff decision on a pending verification.
+func (v *verificationRepo) Review(ctx context.Context, req models.ReviewVerification) error {
+	query := `update customer_verifications set
+		status = $1,
+		reason = NULLIF($2, ''),
+		reviewed_by = NULLIF($3, '')::uuid,
+		reviewed_at = NOW()
+		where customer_id = $4 and status = $5`
+
+	ctx, cancel := context.WithTimeout(ctx, config.TimewithContex)
+	defer cancel()
+
+	tag, err := v.db.Exec(ctx, query, req.Status, req.Reason, req.ReviewerId, req.CustomerId, config.VERIFICATION_PENDING)
+	if err != nil {
+		return dbError(err, "verification")
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrVerificationNotPending
+	}
+	return nil
+}
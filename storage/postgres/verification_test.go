@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"rent-car/api/models"
+	"rent-car/config"
+	"rent-car/pkg/errs"
+	"rent-car/storage"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitAndReviewVerification(t *testing.T) {
+	customers := NewCustomer(db, logg)
+	repo := NewVerification(db)
+
+	customerID, err := customers.Create(context.Background(), models.Customer{
+		FirstName: faker.FirstName(),
+		Gmail:     faker.Email(),
+		Phone:     faker.Phonenumber(),
+		Password:  "Secret#123",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	submit := models.SubmitVerification{
+		CustomerId:     customerID,
+		LicenceNumber:  "AB1234567",
+		LicenceCountry: "UZ",
+		LicenceExpiry:  "2035-01-01",
+		DateOfBirth:    "1990-05-20",
+		Documents: []models.VerificationDocument{
+			{Kind: config.DOCUMENT_LICENCE_FRONT, Url: "https://files.example.com/front.jpg"},
+			{Kind: config.DOCUMENT_IDENTITY, Url: "https://files.example.com/passport.jpg"},
+		},
+	}
+	if !assert.NoError(t, repo.Submit(context.Background(), submit)) {
+		return
+	}
+
+	verification, err := repo.GetByCustomerID(context.Background(), customerID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.VERIFICATION_PENDING, verification.Status)
+		assert.Equal(t, "1990-05-20", verification.DateOfBirth)
+		assert.Len(t, verification.Documents, 2)
+	}
+
+	// a rejection can not be recorded twice
+	review := models.ReviewVerification{CustomerId: customerID, Status: config.VERIFICATION_REJECTED, Reason: "scan is blurred"}
+	assert.NoError(t, repo.Review(context.Background(), review))
+	assert.ErrorIs(t, repo.Review(context.Background(), review), storage.ErrVerificationNotPending)
+
+	// resubmitting puts it back in review with the new documents only
+	submit.Documents = submit.Documents[:1]
+	assert.NoError(t, repo.Submit(context.Background(), submit))
+
+	verification, err = repo.GetByCustomerID(context.Background(), customerID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.VERIFICATION_PENDING, verification.Status)
+		assert.Empty(t, verification.Reason)
+		assert.Len(t, verification.Documents, 1)
+	}
+}
+
+func TestGetVerificationUnknownCustomer(t *testing.T) {
+	repo := NewVerification(db)
+
+	_, err := repo.GetByCustomerID(context.Background(), faker.UUIDHyphenated())
+	assert.Equal(t, errs.KindNotFound, errs.KindOf(err))
+}
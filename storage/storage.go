@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"rent-car/api/models"
+	"time"
 )
 
 type IStorage interface {
@@ -10,27 +11,56 @@ type IStorage interface {
 	Car() ICarStorage
 	Customer() ICustomerStorage
 	Order() IOrderStorage
+	Token() ITokenStorage
+	Staff() IStaffStorage
+	Outbox() IOutboxStorage
+	Verification() IVerificationStorage
+	Payment() IPaymentStorage
+	Deposit() IDepositStorage
+	Extension() IExtensionStorage
+	Promo() IPromoStorage
+	Loyalty() ILoyaltyStorage
 }
 
 type ICarStorage interface {
 	Create(context.Context,models.CreateCar) (string, error)
 	GetByID(ctx context.Context,id string) (models.Car, error)
-	GetAvaibleCars(ctx context.Context,req models.GetAllCarsRequest) (models.GetAllCarsResponse, error)
+	GetAvaibleCars(ctx context.Context,req models.GetAvailableCarsRequest) (models.GetAllCarsResponse, error)
 	GetAll(context.Context,models.GetAllCarsRequest) (models.GetAllCarsResponse, error)
 	Update(context.Context,models.Car) (string, error)
 	Delete(ctx context.Context,id string) error
+	CreateSeasonalRate(context.Context, models.SeasonalRate) (string, error)
+	GetSeasonalRates(ctx context.Context, carID string) ([]models.SeasonalRate, error)
+	DeleteSeasonalRate(ctx context.Context, carID, id string) error
+	GetDeleted(context.Context, models.GetDeletedRequest) (models.GetAllCarsResponse, error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
 }
 
 type ICustomerStorage interface {
 	Create(context.Context,models.Customer) (string, error)
 	GetByID(ctx context.Context,id string) (models.Customer, error)
 	GetAllCustomer(ctx context.Context,req models.GetAllCustomersRequest) (models.GetAllCustomersResponse, error)
-	UpdateCustomer(context.Context,models.Customer) (string, error)
+	UpdateCustomer(context.Context,models.UpdateCustomer) (string, error)
 	UpdateCustomerPassword(context.Context,models.PasswordOfCustomer)(string, error)
 	GetPasswordforLogin(ctx context.Context, phone string) (string, error)
 	Delete(ctx context.Context,id string) error
 	GetByLogin(context.Context, string) (models.GetAllCustomer, error)
-	
+	GetDeleted(context.Context, models.GetDeletedRequest) (models.GetDeletedCustomersResponse, error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+	Block(context.Context, models.CustomerBlockEvent) error
+	Unblock(context.Context, models.CustomerBlockEvent) error
+	GetBlockEvents(ctx context.Context, customerID string) ([]models.CustomerBlockEvent, error)
+}
+
+type ITokenStorage interface {
+	Revoke(context.Context, models.RevokedToken) (bool, error)
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID, userID, reason string) error
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	RevokeUser(ctx context.Context, userID, reason string) error
+	IsUserRevoked(ctx context.Context, userID string, issuedAt int64) (bool, error)
 }
 
 type IOrderStorage interface {
@@ -39,6 +69,69 @@ type IOrderStorage interface {
 	GetAll(ctx context.Context,request models.GetAllOrdersRequest) (models.GetAllOrdersResponse, error)
 	Update(context.Context,models.UpdateOrder) (string, error)
 	Delete(ctx context.Context,id string) error
-	UpdateOrderStatus(context.Context,models.GetOrder) (string, error)
+	UpdateOrderStatus(context.Context,models.OrderStatusChange) (string, error)
+	GetStatusHistory(ctx context.Context, orderID string) ([]models.OrderStatusHistory, error)
+	GetOverdue(ctx context.Context, grace time.Duration, limit int) ([]models.OverdueOrder, error)
+	ChargeLateFee(context.Context, models.LateFee) error
+	Cancel(context.Context, models.OrderCancellation) ([]string, error)
 }
 
+type IStaffStorage interface {
+	Create(context.Context, models.CreateStaff) (string, error)
+	GetByID(ctx context.Context, id string) (models.Staff, error)
+	GetByLogin(ctx context.Context, login string) (models.Staff, error)
+	HasRole(ctx context.Context, role string) (bool, error)
+}
+
+type IOutboxStorage interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkSent(ctx context.Context, id string, delivered []string) error
+	MarkFailed(context.Context, models.OutboxFailure) error
+	GetList(context.Context, models.GetOutboxEventsRequest) (models.GetOutboxEventsResponse, error)
+	Replay(ctx context.Context, id string) error
+}
+
+type IVerificationStorage interface {
+	Submit(context.Context, models.SubmitVerification) error
+	GetByCustomerID(ctx context.Context, customerID string) (models.CustomerVerification, error)
+	GetList(context.Context, models.GetVerificationsRequest) (models.GetVerificationsResponse, error)
+	Review(context.Context, models.ReviewVerification) error
+}
+
+type IPaymentStorage interface {
+	Record(context.Context, models.RecordPayment) (string, error)
+	Confirm(ctx context.Context, id, providerRef string) error
+	Fail(ctx context.Context, id string) error
+	GetByOrderID(ctx context.Context, orderID string) ([]models.Payment, error)
+	GetBalance(ctx context.Context, orderID string) (models.OrderBalance, error)
+	Report(context.Context, models.FinanceReportRequest) (models.FinanceReport, error)
+}
+
+type IDepositStorage interface {
+	GetByOrderID(ctx context.Context, orderID string) (models.OrderDeposit, error)
+	Settle(context.Context, models.SettleDeposit) (string, error)
+}
+
+type IExtensionStorage interface {
+	Create(context.Context, models.OrderExtension) (string, error)
+	GetByID(ctx context.Context, orderID, id string) (models.OrderExtension, error)
+	GetByOrderID(ctx context.Context, orderID string) ([]models.OrderExtension, error)
+	Review(context.Context, models.ReviewExtension) error
+}
+
+type IPromoStorage interface {
+	Create(context.Context, models.CreatePromoCode) (string, error)
+	GetByID(ctx context.Context, id string) (models.PromoCode, error)
+	GetByCode(ctx context.Context, code string) (models.PromoCode, error)
+	GetList(context.Context, models.GetPromoCodesRequest) (models.GetPromoCodesResponse, error)
+	Update(context.Context, models.UpdatePromoCode) error
+	Delete(ctx context.Context, id string) error
+	GetUsage(ctx context.Context, promoCodeID, customerID string) (models.PromoUsage, error)
+}
+
+type ILoyaltyStorage interface {
+	GetBalance(ctx context.Context, customerID string, tierSince time.Time) (models.LoyaltyBalance, error)
+	GetHistory(context.Context, models.GetLoyaltyRequest) (models.LoyaltyHistory, error)
+	GetExpired(ctx context.Context, limit int) ([]string, error)
+	Expire(ctx context.Context, customerID string) (int, error)
+}